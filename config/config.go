@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 // IsLocal はローカル環境がどうか返します。
 func IsLocal() bool {
@@ -26,3 +29,13 @@ func CORSAllowOrigin() string {
 func FrontendURL() string {
 	return os.Getenv("FRONTEND_URL")
 }
+
+// MaxTracksPerEnqueue は一度のリクエストでキューに追加できる曲数の上限を取得します。
+// 環境変数が設定されていないか不正な値の場合は100を返します。
+func MaxTracksPerEnqueue() int {
+	max, err := strconv.Atoi(os.Getenv("MAX_TRACKS_PER_ENQUEUE"))
+	if err != nil || max <= 0 {
+		return 100
+	}
+	return max
+}
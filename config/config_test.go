@@ -58,3 +58,22 @@ func TestFrontendURL(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxTracksPerEnqueue(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{
+			name: "正しく取得できる",
+			want: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxTracksPerEnqueue(); got != tt.want {
+				t.Errorf("MaxTracksPerEnqueue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ func NewAuthRepository(dbMap *gorp.DbMap) *AuthRepository {
 	dbMap.AddTableWithName(stateDTO{}, "auth_states")
 	dbMap.AddTableWithName(spotifyAuthDTO{}, "spotify_auth")
 	dbMap.AddTableWithName(loginSessionDTO{}, "login_sessions")
+	dbMap.AddTableWithName(guestDTO{}, "guests")
 	return &AuthRepository{dbMap: dbMap}
 }
 
@@ -88,6 +89,53 @@ func (r AuthRepository) GetUserIDFromSession(sessionID string) (string, error) {
 	return dto.UserID, nil
 }
 
+// StoreGuest はゲストとゲストを識別するトークンを保存します。
+// ゲストは保存してからentity.GuestLifetimeが経過すると期限切れになります。
+func (r AuthRepository) StoreGuest(token string, guest *entity.Guest) error {
+	dto := &guestDTO{
+		ID:        guest.ID,
+		Token:     token,
+		Nickname:  guest.Nickname,
+		ExpiresAt: time.Now().UTC().Add(entity.GuestLifetime),
+	}
+
+	if err := r.dbMap.Insert(dto); err != nil {
+		return fmt.Errorf("insert guest: %w", err)
+	}
+	return nil
+}
+
+// FindGuestByToken はトークンから対応するゲストを取得します。
+// 期限切れのゲストは存在しないものとして扱います。
+func (r AuthRepository) FindGuestByToken(token string) (*entity.Guest, error) {
+	var dto guestDTO
+	query := "SELECT id, token, nickname, expires_at FROM guests WHERE token = ? AND expires_at > ?"
+	if err := r.dbMap.SelectOne(&dto, query, token, time.Now().UTC()); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("select guest: %w", entity.ErrGuestNotFound)
+		}
+		return nil, fmt.Errorf("select guest: %w", err)
+	}
+
+	return &entity.Guest{
+		ID:       dto.ID,
+		Nickname: dto.Nickname,
+	}, nil
+}
+
+// DeleteExpiredGuests は期限切れのゲストを削除し、削除した件数を返します。
+func (r AuthRepository) DeleteExpiredGuests() (int64, error) {
+	result, err := r.dbMap.Exec("DELETE FROM guests WHERE expires_at <= ?", time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired guests: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected: %w", err)
+	}
+	return n, nil
+}
+
 // StoreState はauthStateを保存します。
 func (r AuthRepository) StoreState(authState *entity.AuthState) error {
 	dto := &stateDTO{
@@ -136,3 +184,10 @@ type loginSessionDTO struct {
 	ID     string `db:"id"`
 	UserID string `db:"user_id"`
 }
+
+type guestDTO struct {
+	ID        string    `db:"id"`
+	Token     string    `db:"token"`
+	Nickname  string    `db:"nickname"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -381,3 +381,95 @@ func TestAuthRepository_GetUserIDFromSession(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRepository_FindGuestByToken(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(guestDTO{}, "guests")
+	truncateTable(t, dbMap)
+	r := AuthRepository{dbMap: dbMap}
+	if err := r.StoreGuest("token_1", &entity.Guest{ID: "guest_id_1", Nickname: "nickname"}); err != nil {
+		t.Fatal(err)
+	}
+	expired := &guestDTO{ID: "guest_id_expired", Token: "token_expired", Nickname: "nickname", ExpiresAt: time.Now().UTC().Add(-1 * time.Hour)}
+	if err := dbMap.Insert(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *entity.Guest
+		wantErr error
+	}{
+		{
+			name:    "正常に動作",
+			token:   "token_1",
+			want:    &entity.Guest{ID: "guest_id_1", Nickname: "nickname"},
+			wantErr: nil,
+		},
+		{
+			name:    "存在しないトークンを指定するとErrGuestNotFound",
+			token:   "token_2",
+			want:    nil,
+			wantErr: entity.ErrGuestNotFound,
+		},
+		{
+			name:    "期限切れのゲストのトークンを指定するとErrGuestNotFound",
+			token:   "token_expired",
+			want:    nil,
+			wantErr: entity.ErrGuestNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.FindGuestByToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("FindGuestByToken() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("FindGuestByToken() diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestAuthRepository_DeleteExpiredGuests(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(guestDTO{}, "guests")
+	truncateTable(t, dbMap)
+	r := AuthRepository{dbMap: dbMap}
+	if err := r.StoreGuest("token_valid", &entity.Guest{ID: "guest_id_valid", Nickname: "nickname"}); err != nil {
+		t.Fatal(err)
+	}
+	expired := &guestDTO{ID: "guest_id_expired", Token: "token_expired", Nickname: "nickname", ExpiresAt: time.Now().UTC().Add(-1 * time.Hour)}
+	if err := dbMap.Insert(expired); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.DeleteExpiredGuests()
+	if err != nil {
+		t.Fatalf("DeleteExpiredGuests() error = %v", err)
+	}
+	if got != 1 {
+		t.Errorf("DeleteExpiredGuests() = %d, want = 1", got)
+	}
+
+	var ids []string
+	if _, err := dbMap.Select(&ids, "SELECT id FROM guests ORDER BY id"); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"guest_id_valid"}; !cmp.Equal(ids, want) {
+		t.Errorf("DeleteExpiredGuests() remaining guests diff=%v", cmp.Diff(want, ids))
+	}
+}
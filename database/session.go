@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -29,6 +31,9 @@ type SessionRepository struct {
 func NewSessionRepository(dbMap *gorp.DbMap) *SessionRepository {
 	dbMap.AddTableWithName(sessionDTO{}, "sessions").SetKeys(false, "ID")
 	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	dbMap.AddTableWithName(queueTrackVoteDTO{}, "queue_track_votes")
+	dbMap.AddTableWithName(skipVoteDTO{}, "skip_votes")
+	dbMap.AddTableWithName(sessionBanDTO{}, "session_bans")
 	return &SessionRepository{dbMap: dbMap}
 }
 
@@ -40,7 +45,7 @@ func (r *SessionRepository) FindByID(ctx context.Context, id string) (*entity.Se
 	}
 
 	var dto sessionDTO
-	if err := dao.SelectOne(&dto, "SELECT id, name, creator_id, queue_head, state_type, device_id, expired_at, allow_to_control_by_others, progress_when_paused FROM sessions WHERE id = ?", id); err != nil {
+	if err := dao.SelectOne(&dto, "SELECT id, name, description, creator_id, queue_head, state_type, device_id, expired_at, allow_to_control_by_others, progress_when_paused, needs_spotify_queue_reset, queue_order_type, skip_vote_threshold_type, skip_vote_threshold, autoplay, fallback_playlist_uri, last_activity_at, join_code, expiration_policy_type, expiration_hours FROM sessions WHERE id = ?", id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("select session: %w", entity.ErrSessionNotFound)
 		}
@@ -57,7 +62,22 @@ func (r *SessionRepository) FindByID(ctx context.Context, id string) (*entity.Se
 		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidStateType)
 	}
 
-	return r.dtoToSession(dto, stateType, queueTracks), nil
+	queueOrderType, err := entity.NewQueueOrderType(dto.QueueOrderType)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidQueueOrderType)
+	}
+
+	skipVoteThreshold, err := entity.NewSkipVoteThreshold(dto.SkipVoteThresholdType, dto.SkipVoteThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidSkipVoteThreshold)
+	}
+
+	expirationPolicy, err := entity.NewExpirationPolicy(dto.ExpirationPolicyType, dto.ExpirationHours)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidExpirationPolicy)
+	}
+
+	return r.dtoToSession(dto, stateType, queueOrderType, skipVoteThreshold, expirationPolicy, queueTracks), nil
 }
 
 // FindByIDForUpdate は指定されたIDを持つsessionをDBから取得します
@@ -68,7 +88,7 @@ func (r *SessionRepository) FindByIDForUpdate(ctx context.Context, id string) (*
 	}
 
 	var dto sessionDTO
-	if err := dao.SelectOne(&dto, "SELECT id, name, creator_id, queue_head, state_type, device_id, expired_at, allow_to_control_by_others, progress_when_paused FROM sessions WHERE id = ? FOR UPDATE", id); err != nil {
+	if err := dao.SelectOne(&dto, "SELECT id, name, description, creator_id, queue_head, state_type, device_id, expired_at, allow_to_control_by_others, progress_when_paused, needs_spotify_queue_reset, queue_order_type, skip_vote_threshold_type, skip_vote_threshold, autoplay, fallback_playlist_uri, last_activity_at, join_code, expiration_policy_type, expiration_hours FROM sessions WHERE id = ? FOR UPDATE", id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("select session: %w", entity.ErrSessionNotFound)
 		}
@@ -85,7 +105,75 @@ func (r *SessionRepository) FindByIDForUpdate(ctx context.Context, id string) (*
 		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidStateType)
 	}
 
-	return r.dtoToSession(dto, stateType, queueTracks), nil
+	queueOrderType, err := entity.NewQueueOrderType(dto.QueueOrderType)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidQueueOrderType)
+	}
+
+	skipVoteThreshold, err := entity.NewSkipVoteThreshold(dto.SkipVoteThresholdType, dto.SkipVoteThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidSkipVoteThreshold)
+	}
+
+	expirationPolicy, err := entity.NewExpirationPolicy(dto.ExpirationPolicyType, dto.ExpirationHours)
+	if err != nil {
+		return nil, fmt.Errorf("find session: %w", entity.ErrInvalidExpirationPolicy)
+	}
+
+	return r.dtoToSession(dto, stateType, queueOrderType, skipVoteThreshold, expirationPolicy, queueTracks), nil
+}
+
+// FindByUserID は指定されたユーザが作成したか、役割を割り当てられたか、曲を追加したり投票したりスキップ投票したりして参加したセッションを
+// 最後の操作日時が新しい順に最大filter.Limit件取得します。一覧で使うためキューの曲は取得しません。
+func (r *SessionRepository) FindByUserID(ctx context.Context, userID string, filter entity.SessionListFilter) ([]*entity.SessionWithUser, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	query := "SELECT s.id, s.name, s.description, s.creator_id, s.queue_head, s.state_type, s.device_id, s.expired_at, s.allow_to_control_by_others, s.progress_when_paused, s.needs_spotify_queue_reset, s.queue_order_type, s.skip_vote_threshold_type, s.skip_vote_threshold, s.autoplay, s.fallback_playlist_uri, s.last_activity_at, s.join_code, s.expiration_policy_type, s.expiration_hours, " +
+		"u.spotify_user_id AS creator_spotify_user_id, u.display_name AS creator_display_name " +
+		"FROM sessions AS s INNER JOIN users AS u ON u.id = s.creator_id " +
+		"WHERE s.id IN (SELECT id FROM sessions WHERE creator_id = ? UNION SELECT session_id FROM queue_tracks WHERE added_by = ? UNION SELECT session_id FROM queue_track_votes WHERE user_id = ? UNION SELECT session_id FROM skip_votes WHERE user_id = ? UNION SELECT session_id FROM session_members WHERE user_id = ?)"
+	args := []interface{}{userID, userID, userID, userID, userID}
+
+	if len(filter.StateTypes) > 0 {
+		placeholders := make([]string, len(filter.StateTypes))
+		for i, st := range filter.StateTypes {
+			placeholders[i] = "?"
+			args = append(args, st.String())
+		}
+		query += " AND s.state_type IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	if filter.Name != "" {
+		query += " AND s.name LIKE ?"
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+	}
+	if filter.Cursor != nil {
+		query += " AND (s.last_activity_at < ? OR (s.last_activity_at = ? AND s.id < ?))"
+		args = append(args, filter.Cursor.LastActivityAt, filter.Cursor.LastActivityAt, filter.Cursor.ID)
+	}
+	query += " ORDER BY s.last_activity_at DESC, s.id DESC LIMIT ?"
+	args = append(args, filter.Limit)
+
+	var dto []sessionWithCreatorDTO
+	if _, err := dao.Select(&dto, query, args...); err != nil {
+		return nil, fmt.Errorf("select sessions user_id=%s: %w", userID, err)
+	}
+
+	sessions := make([]*entity.SessionWithUser, len(dto))
+	for i, d := range dto {
+		session, err := r.toSession(d.sessionDTO, nil)
+		if err != nil {
+			return nil, fmt.Errorf("find sessions user_id=%s: %w", userID, err)
+		}
+		sessions[i] = entity.NewSessionWithUser(session, &entity.User{
+			ID:            d.CreatorID,
+			SpotifyUserID: d.CreatorSpotifyUserID,
+			DisplayName:   d.CreatorDisplayName,
+		})
+	}
+	return sessions, nil
 }
 
 // FindCreatorTokenBySessionID はSessionIDからCreatorのTokenを取得します
@@ -119,9 +207,13 @@ func (r *SessionRepository) StoreSession(ctx context.Context, session *entity.Se
 		dao = r.dbMap
 	}
 
-	dto := r.sessionToDTO(session)
-
-	if err := dao.Insert(dto); err != nil {
+	err := r.retryOnDuplicateJoinCode(session, func() error {
+		return dao.Insert(r.sessionToDTO(session))
+	})
+	if err != nil {
+		if errors.Is(err, entity.ErrJoinCodeAlreadyExisted) {
+			return fmt.Errorf("insert session: %w", err)
+		}
 		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == errorNumDuplicateEntry {
 			return fmt.Errorf("insert session: %w", entity.ErrSessionAlreadyExisted)
 		}
@@ -130,66 +222,473 @@ func (r *SessionRepository) StoreSession(ctx context.Context, session *entity.Se
 	return nil
 }
 
-// Update はセッションの情報を更新します。
+// Update は参加者による再生や設定の変更などの操作でセッションの情報を更新します。最後の操作日時も現在時刻に更新します。
 func (r *SessionRepository) Update(ctx context.Context, session *entity.Session) error {
+	return r.update(ctx, session, time.Now().UTC())
+}
+
+// UpdateWithoutActivity は曲の再生が終わってheadが進んだときなど、参加者の操作ではない理由でセッションの情報を更新します。
+// 最後の操作日時はセッションを取得したときの値のまま更新しません。
+func (r *SessionRepository) UpdateWithoutActivity(ctx context.Context, session *entity.Session) error {
+	return r.update(ctx, session, session.LastActivityAt)
+}
+
+func (r *SessionRepository) update(ctx context.Context, session *entity.Session, lastActivityAt time.Time) error {
 	dao, ok := getTx(ctx)
 	if !ok {
 		dao = r.dbMap
 	}
 
-	dto := r.sessionToDTO(session)
-
-	if _, err := dao.Update(dto); err != nil {
+	err := r.retryOnDuplicateJoinCode(session, func() error {
+		dto := r.sessionToDTO(session)
+		dto.LastActivityAt = lastActivityAt
+		_, err := dao.Update(dto)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("update session: %w", err)
 	}
 	return nil
 }
 
-// StoreQueueTrack はQueueTrackをDBに挿入します。
-func (r *SessionRepository) StoreQueueTrack(ctx context.Context, queueTrack *entity.QueueTrackToStore) error {
+// updateLastActivityAt は曲の追加や投票などの参加者の操作が行われたときに、セッションの最後の操作日時を更新します。
+func updateLastActivityAt(dao TransactionDAO, sessionID string, at time.Time) error {
+	if _, err := dao.Exec("UPDATE sessions SET last_activity_at = ? WHERE id = ?;", at, sessionID); err != nil {
+		return fmt.Errorf("update sessions last_activity_at: %w", err)
+	}
+	return nil
+}
+
+// maxJoinCodeRetries は参加用のコードが他のセッションと重複したときに生成し直す回数の上限です。
+const maxJoinCodeRetries = 5
+
+// retryOnDuplicateJoinCode はセッションの参加用のコードが他のセッションと重複してfが失敗した場合に、
+// コードを生成し直してfを再実行します。上限回数まで重複した場合はErrJoinCodeAlreadyExistedを返します。
+func (r *SessionRepository) retryOnDuplicateJoinCode(session *entity.Session, f func() error) error {
+	for i := 0; ; i++ {
+		err := f()
+		if !isDuplicateJoinCode(err) {
+			return err
+		}
+		if maxJoinCodeRetries <= i {
+			return fmt.Errorf("join code = %s: %w", session.JoinCode, entity.ErrJoinCodeAlreadyExisted)
+		}
+		session.RegenerateJoinCode()
+	}
+}
+
+// isDuplicateJoinCode はエラーが参加用のコードのユニーク制約違反によるものかどうかを返します。
+func isDuplicateJoinCode(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == errorNumDuplicateEntry && strings.Contains(mysqlErr.Message, "join_code")
+}
+
+// FindIDByJoinCode は参加用のコードに対応するセッションのIDを取得します。
+func (r *SessionRepository) FindIDByJoinCode(ctx context.Context, joinCode string) (string, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	var dto struct {
+		ID string `db:"id"`
+	}
+	if err := dao.SelectOne(&dto, "SELECT id FROM sessions WHERE join_code = ?", joinCode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("select session id: %w", entity.ErrSessionNotFound)
+		}
+		return "", fmt.Errorf("select session id: %w", err)
+	}
+	return dto.ID, nil
+}
+
+// Delete はセッションを削除します。
+// キューの曲や投票などセッションに紐づくデータは外部キー制約によって合わせて削除されます。
+func (r *SessionRepository) Delete(ctx context.Context, id string) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	result, err := dao.Exec("DELETE FROM sessions WHERE id = ?;", id)
+	if err != nil {
+		return fmt.Errorf("delete session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("delete session: %w", entity.ErrSessionNotFound)
+	}
+	return nil
+}
+
+// StoreQueueTrack はQueueTrackを指定されたindexでDBに挿入し、それ以降の曲のindexを1つずつ後ろにずらします。
+// キューの最後に追加する場合は、indexに現在のキューの曲数を指定します。
+func (r *SessionRepository) StoreQueueTrack(ctx context.Context, queueTrack *entity.QueueTrackToStore, index int) error {
 	dao, ok := getTx(ctx)
 	if !ok {
 		dao = r.dbMap
 	}
 
-	if _, err := dao.Exec("INSERT INTO queue_tracks(`index`, uri, session_id) SELECT COALESCE(MAX(`index`),-1)+1, ?, ? from queue_tracks as qt WHERE session_id = ?;", queueTrack.URI, queueTrack.SessionID, queueTrack.SessionID); err != nil {
+	// 主キーが重複しないように、indexの大きい曲から順番にずらす
+	if _, err := dao.Exec("UPDATE queue_tracks SET `index` = `index` + 1 WHERE session_id = ? AND `index` >= ? ORDER BY `index` DESC;", queueTrack.SessionID, index); err != nil {
+		return fmt.Errorf("shift queue_tracks index: %w", err)
+	}
+
+	if _, err := dao.Exec("INSERT INTO queue_tracks(`index`, uri, session_id, added_by, added_by_name, added_at) VALUES (?, ?, ?, ?, ?, ?);",
+		index, queueTrack.URI, queueTrack.SessionID, queueTrack.AddedBy, queueTrack.AddedByName, queueTrack.AddedAt); err != nil {
 		return fmt.Errorf("insert queue_tracks: %w", err)
 	}
+
+	if err := updateLastActivityAt(dao, queueTrack.SessionID, queueTrack.AddedAt); err != nil {
+		return err
+	}
 	return nil
 }
 
-// ArchiveSessionsForBatch は以下の条件に当てはまるSessionのstateをArchivedに変更します
-//// - 作成から3日以上が経過している。もしくはArchiveが解除されてから3日以上が経過している
-func (r *SessionRepository) ArchiveSessionsForBatch() error {
-	currentDateTime := time.Now().UTC()
-	if _, err := r.dbMap.Exec("UPDATE sessions SET state_type = 'ARCHIVED' WHERE allow_to_control_by_others = true AND state_type != 'ARCHIVED' AND expired_at < ?;", currentDateTime); err != nil {
-		return fmt.Errorf("update session state_type to ARCHIVED: %w", err)
+// DeleteQueueTrack は指定されたindexのQueueTrackをDBから削除し、それ以降の曲のindexを詰めます。
+func (r *SessionRepository) DeleteQueueTrack(ctx context.Context, sessionID string, index int) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	if _, err := dao.Exec("DELETE FROM queue_tracks WHERE session_id = ? AND `index` = ?;", sessionID, index); err != nil {
+		return fmt.Errorf("delete queue_tracks: %w", err)
+	}
+
+	// 主キーが重複しないように、indexの小さい曲から順番に詰める
+	if _, err := dao.Exec("UPDATE queue_tracks SET `index` = `index` - 1 WHERE session_id = ? AND `index` > ? ORDER BY `index` ASC;", sessionID, index); err != nil {
+		return fmt.Errorf("update queue_tracks index: %w", err)
+	}
+	return nil
+}
+
+// MoveQueueTrack は指定されたindexのQueueTrackを別のindexに移動し、その間にある曲のindexをずらします。
+func (r *SessionRepository) MoveQueueTrack(ctx context.Context, sessionID string, from, to int) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	// 移動中に主キーが重複しないように、一度どの曲とも重複しないindexに退避させる
+	if _, err := dao.Exec("UPDATE queue_tracks SET `index` = -1 WHERE session_id = ? AND `index` = ?;", sessionID, from); err != nil {
+		return fmt.Errorf("evacuate queue_track index=%d: %w", from, err)
+	}
+
+	if from < to {
+		if _, err := dao.Exec("UPDATE queue_tracks SET `index` = `index` - 1 WHERE session_id = ? AND `index` > ? AND `index` <= ? ORDER BY `index` ASC;", sessionID, from, to); err != nil {
+			return fmt.Errorf("shift queue_tracks index forward: %w", err)
+		}
+	} else {
+		if _, err := dao.Exec("UPDATE queue_tracks SET `index` = `index` + 1 WHERE session_id = ? AND `index` >= ? AND `index` < ? ORDER BY `index` DESC;", sessionID, to, from); err != nil {
+			return fmt.Errorf("shift queue_tracks index backward: %w", err)
+		}
+	}
+
+	if _, err := dao.Exec("UPDATE queue_tracks SET `index` = ? WHERE session_id = ? AND `index` = -1;", to, sessionID); err != nil {
+		return fmt.Errorf("update queue_track index=%d: %w", to, err)
+	}
+	return nil
+}
+
+// UpdateQueueTrackPlayback はQueueTrackの再生を開始・終了した日時と終了した理由を更新します。
+func (r *SessionRepository) UpdateQueueTrackPlayback(ctx context.Context, queueTrack *entity.QueueTrack) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	startedAt := sql.NullTime{Time: queueTrack.StartedAt, Valid: !queueTrack.StartedAt.IsZero()}
+	endedAt := sql.NullTime{Time: queueTrack.EndedAt, Valid: !queueTrack.EndedAt.IsZero()}
+	endReason := sql.NullString{String: queueTrack.EndReason.String(), Valid: queueTrack.EndReason != ""}
+	if _, err := dao.Exec("UPDATE queue_tracks SET started_at = ?, ended_at = ?, end_reason = ? WHERE session_id = ? AND `index` = ?;",
+		startedAt, endedAt, endReason, queueTrack.SessionID, queueTrack.Index); err != nil {
+		return fmt.Errorf("update queue_track playback index=%d: %w", queueTrack.Index, err)
+	}
+	return nil
+}
+
+// UpdateQueueTrackIndexes はQueueTrackのindexをまとめて変更します。
+// indexesは変更前のindexをkey、変更後のindexをvalueとしたmapで、変更後のindexは全体で重複しないようにする必要があります。
+func (r *SessionRepository) UpdateQueueTrackIndexes(ctx context.Context, sessionID string, indexes map[int]int) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	oldIndexes := make([]int, 0, len(indexes))
+	for oldIndex := range indexes {
+		oldIndexes = append(oldIndexes, oldIndex)
+	}
+	sort.Ints(oldIndexes)
+
+	// 入れ替えの途中で主キーが重複しないように、一度どの曲とも重複しない負のindexに退避させる
+	for _, oldIndex := range oldIndexes {
+		if _, err := dao.Exec("UPDATE queue_tracks SET `index` = ? WHERE session_id = ? AND `index` = ?;", -(oldIndex + 1), sessionID, oldIndex); err != nil {
+			return fmt.Errorf("evacuate queue_track index=%d: %w", oldIndex, err)
+		}
+	}
+	for _, oldIndex := range oldIndexes {
+		if _, err := dao.Exec("UPDATE queue_tracks SET `index` = ? WHERE session_id = ? AND `index` = ?;", indexes[oldIndex], sessionID, -(oldIndex + 1)); err != nil {
+			return fmt.Errorf("update queue_track index=%d to %d: %w", oldIndex, indexes[oldIndex], err)
+		}
+	}
+	return nil
+}
+
+// StoreSkipVote は指定されたindexの曲に対するユーザのスキップ投票をDBに挿入します。
+func (r *SessionRepository) StoreSkipVote(ctx context.Context, sessionID string, index int, userID string) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	if _, err := dao.Exec("INSERT INTO skip_votes(session_id, `index`, user_id) VALUES (?, ?, ?);", sessionID, index, userID); err != nil {
+		if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == errorNumDuplicateEntry {
+			return fmt.Errorf("insert skip_votes: %w", entity.ErrSkipVoteAlreadyExisted)
+		}
+		return fmt.Errorf("insert skip_votes: %w", err)
+	}
+
+	if err := updateLastActivityAt(dao, sessionID, time.Now().UTC()); err != nil {
+		return err
 	}
 	return nil
 }
 
+// CountSkipVotes は指定されたindexの曲に対するスキップ投票の数を取得します。
+func (r *SessionRepository) CountSkipVotes(ctx context.Context, sessionID string, index int) (int, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	count, err := dao.SelectInt("SELECT COUNT(*) FROM skip_votes WHERE session_id = ? AND `index` = ?;", sessionID, index)
+	if err != nil {
+		return 0, fmt.Errorf("count skip_votes: %w", err)
+	}
+	return int(count), nil
+}
+
+// FindMemberRole はセッションにおけるユーザの役割を取得します。
+// 役割が割り当てられていないユーザはリスナーとして扱います。作成者かどうかはSessionのCreatorIDで判定してください。
+func (r *SessionRepository) FindMemberRole(ctx context.Context, sessionID string, userID string) (entity.SessionRole, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	var dto sessionMemberDTO
+	if err := dao.SelectOne(&dto, "SELECT session_id, user_id, role FROM session_members WHERE session_id = ? AND user_id = ?;", sessionID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.SessionRoleListener, nil
+		}
+		return "", fmt.Errorf("select session_members: %w", err)
+	}
+
+	role, err := entity.NewSessionRole(dto.Role)
+	if err != nil {
+		return "", fmt.Errorf("session id=%s user id=%s: %w", sessionID, userID, err)
+	}
+	return role, nil
+}
+
+// StoreMember はセッションにおけるユーザの役割をDBに保存します。既に役割が割り当てられている場合は上書きします。
+func (r *SessionRepository) StoreMember(ctx context.Context, member *entity.SessionMember) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	if _, err := dao.Exec("INSERT INTO session_members(session_id, user_id, role) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE role = VALUES(role);",
+		member.SessionID, member.UserID, member.Role.String()); err != nil {
+		return fmt.Errorf("insert session_members: %w", err)
+	}
+	return nil
+}
+
+// StoreBan はセッションから追放された参加者をDBに保存します。既に追放されている場合は何もしません。
+func (r *SessionRepository) StoreBan(ctx context.Context, ban *entity.SessionBan) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	if _, err := dao.Exec("INSERT IGNORE INTO session_bans(session_id, participant_id) VALUES (?, ?);", ban.SessionID, ban.ParticipantID); err != nil {
+		return fmt.Errorf("insert session_bans: %w", err)
+	}
+	return nil
+}
+
+// IsMember はユーザがセッションで役割を割り当てられたメンバーかどうかを返します。作成者かどうかはSessionのCreatorIDで判定してください。
+func (r *SessionRepository) IsMember(ctx context.Context, sessionID string, userID string) (bool, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	count, err := dao.SelectInt("SELECT COUNT(*) FROM session_members WHERE session_id = ? AND user_id = ?;", sessionID, userID)
+	if err != nil {
+		return false, fmt.Errorf("count session_members: %w", err)
+	}
+	return count > 0, nil
+}
+
+// IsBanned は参加者がセッションから追放されているかどうかを返します。
+func (r *SessionRepository) IsBanned(ctx context.Context, sessionID string, participantID string) (bool, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	count, err := dao.SelectInt("SELECT COUNT(*) FROM session_bans WHERE session_id = ? AND participant_id = ?;", sessionID, participantID)
+	if err != nil {
+		return false, fmt.Errorf("count session_bans: %w", err)
+	}
+	return count > 0, nil
+}
+
+// StoreQueueTrackVote は指定されたindexの曲に対するユーザの投票をDBに挿入します。
+func (r *SessionRepository) StoreQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) error {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	if _, err := dao.Exec("INSERT INTO queue_track_votes(session_id, `index`, user_id) VALUES (?, ?, ?);", sessionID, index, userID); err != nil {
+		return fmt.Errorf("insert queue_track_votes: %w", err)
+	}
+
+	if err := updateLastActivityAt(dao, sessionID, time.Now().UTC()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteQueueTrackVote は指定されたindexの曲に対するユーザの投票をDBから削除します。
+// 削除する投票が存在したかどうかを返します。
+func (r *SessionRepository) DeleteQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) (bool, error) {
+	dao, ok := getTx(ctx)
+	if !ok {
+		dao = r.dbMap
+	}
+
+	result, err := dao.Exec("DELETE FROM queue_track_votes WHERE session_id = ? AND `index` = ? AND user_id = ?;", sessionID, index, userID)
+	if err != nil {
+		return false, fmt.Errorf("delete queue_track_votes: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if err := updateLastActivityAt(dao, sessionID, time.Now().UTC()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ArchiveSessionsForBatch はセッションごとのExpirationPolicyに従って、以下の条件に当てはまるSessionのstateをArchivedに変更し、
+// 変更したSessionのIDと変更前のstateを返します
+// - FIXED: 作成もしくはArchiveが解除されてから指定された時間が経過している(expired_atが現在時刻より前)
+// - IDLE: 最後に曲の追加や再生などの操作が行われてから指定された時間が経過している
+// NEVERのSessionはArchivedに変更しません。
+func (r *SessionRepository) ArchiveSessionsForBatch() ([]*entity.ArchivedSession, error) {
+	currentDateTime := time.Now().UTC()
+	tx, err := r.dbMap.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin transaction: %w", err)
+	}
+
+	var dto []archivedSessionDTO
+	query := "SELECT id, state_type FROM sessions WHERE state_type != 'ARCHIVED' AND (" +
+		"(expiration_policy_type = 'FIXED' AND expired_at < ?) OR " +
+		"(expiration_policy_type = 'IDLE' AND last_activity_at < DATE_SUB(?, INTERVAL expiration_hours HOUR))) FOR UPDATE"
+	if _, err := tx.Select(&dto, query, currentDateTime, currentDateTime); err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("select sessions to archive: %w", err)
+	}
+
+	archived := make([]*entity.ArchivedSession, len(dto))
+	for i, d := range dto {
+		stateType, err := entity.NewStateType(d.StateType)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("session id=%s: %w", d.ID, entity.ErrInvalidStateType)
+		}
+		archived[i] = &entity.ArchivedSession{ID: d.ID, PrevStateType: stateType}
+	}
+
+	if len(archived) > 0 {
+		placeholders := make([]string, len(archived))
+		args := make([]interface{}, len(archived))
+		for i, a := range archived {
+			placeholders[i] = "?"
+			args[i] = a.ID
+		}
+		if _, err := tx.Exec("UPDATE sessions SET state_type = 'ARCHIVED', join_code = NULL WHERE id IN ("+strings.Join(placeholders, ", ")+");", args...); err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("update session state_type to ARCHIVED: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("failed to commit: rollback: %w", err)
+	}
+	return archived, nil
+}
+
 func (r *SessionRepository) getQueueTracksBySessionID(id string) ([]*entity.QueueTrack, error) {
 	var dto []queueTrackDTO
 	if _, err := r.dbMap.Select(&dto, "SELECT * FROM queue_tracks WHERE session_id = ? ORDER BY `index` ASC", id); err != nil {
 		return nil, fmt.Errorf("select queue_tracks: %w", err)
 	}
-	return r.toQueueTracks(dto), nil
+
+	var votesDTO []queueTrackVoteCountDTO
+	if _, err := r.dbMap.Select(&votesDTO, "SELECT `index`, COUNT(*) AS votes FROM queue_track_votes WHERE session_id = ? GROUP BY `index`", id); err != nil {
+		return nil, fmt.Errorf("select queue_track_votes: %w", err)
+	}
+	return r.toQueueTracks(dto, votesDTO), nil
 }
 
-func (r *SessionRepository) toQueueTracks(resultQueueTracks []queueTrackDTO) []*entity.QueueTrack {
+func (r *SessionRepository) toQueueTracks(resultQueueTracks []queueTrackDTO, resultVotes []queueTrackVoteCountDTO) []*entity.QueueTrack {
+	votes := make(map[int]int, len(resultVotes))
+	for _, rv := range resultVotes {
+		votes[rv.Index] = rv.Votes
+	}
+
 	queueTracks := make([]*entity.QueueTrack, len(resultQueueTracks))
 
 	for i, rs := range resultQueueTracks {
 		queueTracks[i] = &entity.QueueTrack{
-			Index:     rs.Index,
-			URI:       rs.URI,
-			SessionID: rs.SessionID,
+			Index:       rs.Index,
+			URI:         rs.URI,
+			SessionID:   rs.SessionID,
+			AddedBy:     rs.AddedBy,
+			AddedByName: rs.AddedByName,
+			AddedAt:     rs.AddedAt,
+			Votes:       votes[rs.Index],
+			StartedAt:   rs.StartedAt.Time,
+			EndedAt:     rs.EndedAt.Time,
+			EndReason:   entity.QueueTrackEndReason(rs.EndReason.String),
 		}
 	}
 
 	return queueTracks
 }
 
+// escapeLike はLIKE句で部分一致させる文字列のワイルドカードをエスケープします。
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // DoInTx はトランザクションの中でデータベースにアクセスするためのラッパー関数です。
 func (r *SessionRepository) DoInTx(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error) {
 	tx, err := r.dbMap.Begin()
@@ -211,25 +710,60 @@ func (r *SessionRepository) DoInTx(ctx context.Context, f func(ctx context.Conte
 	return v, nil
 }
 
-func (r *SessionRepository) dtoToSession(dto sessionDTO, stateType entity.StateType, queueTracks []*entity.QueueTrack) *entity.Session {
+func (r *SessionRepository) dtoToSession(dto sessionDTO, stateType entity.StateType, queueOrderType entity.QueueOrderType, skipVoteThreshold entity.SkipVoteThreshold, expirationPolicy entity.ExpirationPolicy, queueTracks []*entity.QueueTrack) *entity.Session {
 	return &entity.Session{
 		ID:                     dto.ID,
 		Name:                   dto.Name,
+		Description:            dto.Description,
 		CreatorID:              dto.CreatorID,
 		DeviceID:               dto.DeviceID,
 		StateType:              stateType,
 		QueueHead:              dto.QueueHead,
 		QueueTracks:            queueTracks,
 		ExpiredAt:              dto.ExpiredAt,
+		ExpirationPolicy:       expirationPolicy,
 		AllowToControlByOthers: dto.AllowToControlByOthers,
 		ProgressWhenPaused:     time.Duration(dto.ProgressWhenPaused) * time.Millisecond,
+		NeedsSpotifyQueueReset: dto.NeedsSpotifyQueueReset,
+		QueueOrderType:         queueOrderType,
+		SkipVoteThreshold:      skipVoteThreshold,
+		Autoplay:               dto.Autoplay,
+		FallbackPlaylistURI:    dto.FallbackPlaylistURI,
+		LastActivityAt:         dto.LastActivityAt,
+		JoinCode:               dto.JoinCode.String,
 	}
 }
 
+// toSession はsessionDTOの文字列のフィールドを検証しながらentity.Sessionに変換します。
+func (r *SessionRepository) toSession(dto sessionDTO, queueTracks []*entity.QueueTrack) (*entity.Session, error) {
+	stateType, err := entity.NewStateType(dto.StateType)
+	if err != nil {
+		return nil, fmt.Errorf("session id=%s: %w", dto.ID, entity.ErrInvalidStateType)
+	}
+
+	queueOrderType, err := entity.NewQueueOrderType(dto.QueueOrderType)
+	if err != nil {
+		return nil, fmt.Errorf("session id=%s: %w", dto.ID, entity.ErrInvalidQueueOrderType)
+	}
+
+	skipVoteThreshold, err := entity.NewSkipVoteThreshold(dto.SkipVoteThresholdType, dto.SkipVoteThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("session id=%s: %w", dto.ID, entity.ErrInvalidSkipVoteThreshold)
+	}
+
+	expirationPolicy, err := entity.NewExpirationPolicy(dto.ExpirationPolicyType, dto.ExpirationHours)
+	if err != nil {
+		return nil, fmt.Errorf("session id=%s: %w", dto.ID, entity.ErrInvalidExpirationPolicy)
+	}
+
+	return r.dtoToSession(dto, stateType, queueOrderType, skipVoteThreshold, expirationPolicy, queueTracks), nil
+}
+
 func (r *SessionRepository) sessionToDTO(session *entity.Session) *sessionDTO {
 	return &sessionDTO{
 		ID:                     session.ID,
 		Name:                   session.Name,
+		Description:            session.Description,
 		CreatorID:              session.CreatorID,
 		QueueHead:              session.QueueHead,
 		StateType:              session.StateType.String(),
@@ -237,23 +771,92 @@ func (r *SessionRepository) sessionToDTO(session *entity.Session) *sessionDTO {
 		ExpiredAt:              session.ExpiredAt,
 		AllowToControlByOthers: session.AllowToControlByOthers,
 		ProgressWhenPaused:     session.ProgressWhenPaused.Milliseconds(),
+		NeedsSpotifyQueueReset: session.NeedsSpotifyQueueReset,
+		QueueOrderType:         session.QueueOrderType.String(),
+		SkipVoteThresholdType:  session.SkipVoteThreshold.Type.String(),
+		SkipVoteThreshold:      session.SkipVoteThreshold.Value,
+		Autoplay:               session.Autoplay,
+		FallbackPlaylistURI:    session.FallbackPlaylistURI,
+		LastActivityAt:         session.LastActivityAt,
+		JoinCode:               sql.NullString{String: session.JoinCode, Valid: session.JoinCode != ""},
+		ExpirationPolicyType:   session.ExpirationPolicy.Type.String(),
+		ExpirationHours:        session.ExpirationPolicy.Hours,
 	}
 }
 
 type sessionDTO struct {
-	ID                     string    `db:"id"`
-	Name                   string    `db:"name"`
-	CreatorID              string    `db:"creator_id"`
-	QueueHead              int       `db:"queue_head"`
-	StateType              string    `db:"state_type"`
-	DeviceID               string    `db:"device_id"`
-	ExpiredAt              time.Time `db:"expired_at"`
-	AllowToControlByOthers bool      `db:"allow_to_control_by_others"`
-	ProgressWhenPaused     int64     `db:"progress_when_paused"`
+	ID                     string         `db:"id"`
+	Name                   string         `db:"name"`
+	Description            string         `db:"description"`
+	CreatorID              string         `db:"creator_id"`
+	QueueHead              int            `db:"queue_head"`
+	StateType              string         `db:"state_type"`
+	DeviceID               string         `db:"device_id"`
+	ExpiredAt              time.Time      `db:"expired_at"`
+	AllowToControlByOthers bool           `db:"allow_to_control_by_others"`
+	ProgressWhenPaused     int64          `db:"progress_when_paused"`
+	NeedsSpotifyQueueReset bool           `db:"needs_spotify_queue_reset"`
+	QueueOrderType         string         `db:"queue_order_type"`
+	SkipVoteThresholdType  string         `db:"skip_vote_threshold_type"`
+	SkipVoteThreshold      float64        `db:"skip_vote_threshold"`
+	Autoplay               bool           `db:"autoplay"`
+	FallbackPlaylistURI    string         `db:"fallback_playlist_uri"`
+	LastActivityAt         time.Time      `db:"last_activity_at"`
+	JoinCode               sql.NullString `db:"join_code"`
+	ExpirationPolicyType   string         `db:"expiration_policy_type"`
+	ExpirationHours        int            `db:"expiration_hours"`
 }
 
-type queueTrackDTO struct {
-	Index     int    `db:"index"`
-	URI       string `db:"uri"`
+type archivedSessionDTO struct {
+	ID        string `db:"id"`
+	StateType string `db:"state_type"`
+}
+
+type sessionMemberDTO struct {
 	SessionID string `db:"session_id"`
+	UserID    string `db:"user_id"`
+	Role      string `db:"role"`
+}
+
+type sessionBanDTO struct {
+	SessionID     string    `db:"session_id"`
+	ParticipantID string    `db:"participant_id"`
+	BannedAt      time.Time `db:"banned_at"`
+}
+
+type sessionWithCreatorDTO struct {
+	sessionDTO
+	CreatorSpotifyUserID string `db:"creator_spotify_user_id"`
+	CreatorDisplayName   string `db:"creator_display_name"`
+}
+
+type queueTrackDTO struct {
+	Index       int            `db:"index"`
+	URI         string         `db:"uri"`
+	SessionID   string         `db:"session_id"`
+	AddedBy     string         `db:"added_by"`
+	AddedByName string         `db:"added_by_name"`
+	AddedAt     time.Time      `db:"added_at"`
+	StartedAt   sql.NullTime   `db:"started_at"`
+	EndedAt     sql.NullTime   `db:"ended_at"`
+	EndReason   sql.NullString `db:"end_reason"`
+}
+
+type queueTrackVoteDTO struct {
+	SessionID string    `db:"session_id"`
+	Index     int       `db:"index"`
+	UserID    string    `db:"user_id"`
+	VotedAt   time.Time `db:"voted_at"`
+}
+
+type queueTrackVoteCountDTO struct {
+	Index int `db:"index"`
+	Votes int `db:"votes"`
+}
+
+type skipVoteDTO struct {
+	SessionID string    `db:"session_id"`
+	Index     int       `db:"index"`
+	UserID    string    `db:"user_id"`
+	VotedAt   time.Time `db:"voted_at"`
 }
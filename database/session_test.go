@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -38,6 +40,11 @@ func TestSessionRepository_FindByID(t *testing.T) {
 		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
 		AllowToControlByOthers: true,
 		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
 	queueTrack := &queueTrackDTO{
 		Index:     0,
@@ -74,6 +81,9 @@ func TestSessionRepository_FindByID(t *testing.T) {
 				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
 				AllowToControlByOthers: true,
 				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
 			},
 			wantErr: nil,
 		},
@@ -125,6 +135,11 @@ func TestSessionRepository_FindByIDForUpdate(t *testing.T) {
 		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
 		AllowToControlByOthers: true,
 		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
 	queueTrack := &queueTrackDTO{
 		Index:     0,
@@ -161,6 +176,9 @@ func TestSessionRepository_FindByIDForUpdate(t *testing.T) {
 				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
 				AllowToControlByOthers: true,
 				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
 			},
 			wantErr: nil,
 		},
@@ -188,14 +206,1156 @@ func TestSessionRepository_FindByIDForUpdate(t *testing.T) {
 	}
 }
 
+func TestSessionRepository_FindByUserID(t *testing.T) {
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	dbMap.AddTableWithName(queueTrackVoteDTO{}, "queue_track_votes")
+	dbMap.AddTableWithName(skipVoteDTO{}, "skip_votes")
+	dbMap.AddTableWithName(sessionMemberDTO{}, "session_members")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	otherUser := &userDTO{
+		ID:            "other_user",
+		SpotifyUserID: "other_user_spotify",
+		DisplayName:   "other_user_display_name",
+	}
+	newSessionDTO := func(id, name, creatorID, stateType string, lastActivityAt time.Time) *sessionDTO {
+		return &sessionDTO{
+			ID:                    id,
+			Name:                  name,
+			CreatorID:             creatorID,
+			StateType:             stateType,
+			ExpiredAt:             time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+			QueueOrderType:        "INSERTION",
+			SkipVoteThresholdType: "RATIO",
+			SkipVoteThreshold:     0.5,
+			ExpirationPolicyType:  "FIXED",
+			ExpirationHours:       72,
+			LastActivityAt:        lastActivityAt,
+		}
+	}
+	created := newSessionDTO("created_session_id", "CAMPHOR- HOUSE", "existing_user", "PLAY", time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC))
+	addedTrack := newSessionDTO("added_track_session_id", "study group", "other_user", "ARCHIVED", time.Date(2020, time.December, 1, 13, 0, 0, 0, time.UTC))
+	voted := newSessionDTO("voted_session_id", "100% party", "other_user", "STOP", time.Date(2020, time.December, 1, 11, 0, 0, 0, time.UTC))
+	skipVoted := newSessionDTO("skip_voted_session_id", "lunch", "other_user", "PAUSE", time.Date(2020, time.December, 1, 10, 0, 0, 0, time.UTC))
+	member := newSessionDTO("member_session_id", "weekly", "other_user", "ARCHIVED", time.Date(2020, time.December, 1, 9, 0, 0, 0, time.UTC))
+	notJoined := newSessionDTO("not_joined_session_id", "CAMPHOR- HOUSE", "other_user", "PLAY", time.Date(2020, time.December, 1, 14, 0, 0, 0, time.UTC))
+	addedQueueTrack := &queueTrackDTO{Index: 0, URI: "uri", SessionID: "added_track_session_id", AddedBy: "existing_user"}
+	otherQueueTrack := &queueTrackDTO{Index: 0, URI: "uri", SessionID: "voted_session_id", AddedBy: "other_user"}
+	vote := &queueTrackVoteDTO{SessionID: "voted_session_id", Index: 0, UserID: "existing_user"}
+	skipVote := &skipVoteDTO{SessionID: "skip_voted_session_id", Index: 0, UserID: "existing_user", VotedAt: time.Date(2020, time.December, 1, 10, 0, 0, 0, time.UTC)}
+	sessionMember := &sessionMemberDTO{SessionID: "member_session_id", UserID: "existing_user", Role: "CO_HOST"}
+	if err := dbMap.Insert(user, otherUser, created, addedTrack, voted, skipVoted, member, notJoined, addedQueueTrack, otherQueueTrack, vote, skipVote, sessionMember); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		filter  entity.SessionListFilter
+		wantIDs []string
+	}{
+		{
+			name:    "作成したセッションと役割を割り当てられたセッションと曲を追加したり投票したりスキップ投票したりしたセッションが最後の操作日時が新しい順に返る",
+			filter:  entity.SessionListFilter{Limit: 10},
+			wantIDs: []string{"added_track_session_id", "created_session_id", "voted_session_id", "skip_voted_session_id", "member_session_id"},
+		},
+		{
+			name:    "ステートで絞り込める",
+			filter:  entity.SessionListFilter{StateTypes: []entity.StateType{entity.Play, entity.Stop}, Limit: 10},
+			wantIDs: []string{"created_session_id", "voted_session_id"},
+		},
+		{
+			name:    "名前の部分一致で絞り込め、ワイルドカードはエスケープされる",
+			filter:  entity.SessionListFilter{Name: "0%", Limit: 10},
+			wantIDs: []string{"voted_session_id"},
+		},
+		{
+			name: "カーソルを指定するとその次のセッションから返る",
+			filter: entity.SessionListFilter{
+				Cursor: &entity.SessionListCursor{LastActivityAt: time.Date(2020, time.December, 1, 13, 0, 0, 0, time.UTC), ID: "added_track_session_id"},
+				Limit:  1,
+			},
+			wantIDs: []string{"created_session_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSessionRepository(dbMap)
+			got, err := r.FindByUserID(context.TODO(), "existing_user", tt.filter)
+			if err != nil {
+				t.Fatalf("SessionRepository.FindByUserID() error = %v", err)
+			}
+			gotIDs := make([]string, len(got))
+			for i, s := range got {
+				gotIDs[i] = s.ID
+				if s.Creator == nil || s.Creator.ID != s.CreatorID || s.Creator.DisplayName == "" {
+					t.Errorf("SessionRepository.FindByUserID() creator = %v", s.Creator)
+				}
+			}
+			if !cmp.Equal(gotIDs, tt.wantIDs) {
+				t.Errorf("SessionRepository.FindByUserID() diff = %v", cmp.Diff(tt.wantIDs, gotIDs))
+			}
+		})
+	}
+}
+
 func TestSessionRepository_StoreSession(t *testing.T) {
 	// Prepare
 	dbMap, err := NewDB()
 	if err != nil {
 		t.Fatal(err)
 	}
-	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		DeviceID:               "device_id",
+		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: true,
+		ProgressWhenPaused:     (1 * time.Second).Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+		JoinCode:               sql.NullString{String: "ABCDEF", Valid: true},
+	}
+	if err := dbMap.Insert(user, session); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		session *entity.Session
+		wantErr error
+	}{
+		{
+			name: "新規sessionを正しく保存できる",
+			session: &entity.Session{
+				ID:                     "new_session_id",
+				Name:                   "new_session_name",
+				CreatorID:              "existing_user",
+				DeviceID:               "new_device_id",
+				StateType:              "PLAY",
+				QueueHead:              0,
+				QueueTracks:            nil,
+				ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				AllowToControlByOthers: true,
+				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "登録済みのsessionの場合ErrSessionAlreadyExistedを返す",
+			session: &entity.Session{
+				ID:                     "existing_session_id",
+				Name:                   "existing_session_name",
+				CreatorID:              "existing_user",
+				DeviceID:               "device_id",
+				StateType:              "PLAY",
+				QueueHead:              0,
+				QueueTracks:            nil,
+				ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				AllowToControlByOthers: true,
+				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
+			},
+			wantErr: entity.ErrSessionAlreadyExisted,
+		},
+		{
+			name: "参加用のコードが他のセッションと重複している場合はコードを生成し直して保存できる",
+			session: &entity.Session{
+				ID:                     "duplicate_join_code_session_id",
+				Name:                   "duplicate_join_code_session_name",
+				CreatorID:              "existing_user",
+				DeviceID:               "device_id",
+				StateType:              "PLAY",
+				QueueHead:              0,
+				QueueTracks:            nil,
+				ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				AllowToControlByOthers: true,
+				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
+				JoinCode:               "ABCDEF",
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.StoreSession(context.TODO(), tt.session); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.StoreSessions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.session.JoinCode != "" && tt.session.JoinCode == session.JoinCode.String && tt.session.ID != session.ID {
+				t.Errorf("SessionRepository.StoreSessions() join code is duplicated = %s", tt.session.JoinCode)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_FindIDByJoinCode(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+		JoinCode:               sql.NullString{String: "ABCDEF", Valid: true},
+	}
+	if err := dbMap.Insert(user, session); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		joinCode string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "参加用のコードに対応するセッションのIDを取得できる",
+			joinCode: "ABCDEF",
+			want:     "existing_session_id",
+			wantErr:  nil,
+		},
+		{
+			name:     "存在しないコードの場合はErrSessionNotFound",
+			joinCode: "GHJKMN",
+			want:     "",
+			wantErr:  entity.ErrSessionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			got, err := r.FindIDByJoinCode(context.TODO(), tt.joinCode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.FindIDByJoinCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SessionRepository.FindIDByJoinCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_StoreMemberAndFindMemberRole(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionMemberDTO{}, "session_members")
+	truncateTable(t, dbMap)
+	users := []interface{}{
+		&userDTO{ID: "existing_user", SpotifyUserID: "existing_user_spotify", DisplayName: "existing_user_display_name"},
+		&userDTO{ID: "co_host_user", SpotifyUserID: "co_host_user_spotify", DisplayName: "co_host_user_display_name"},
+		&userDTO{ID: "listener_user", SpotifyUserID: "listener_user_spotify", DisplayName: "listener_user_display_name"},
+	}
+	if err := dbMap.Insert(users...); err != nil {
+		t.Fatal(err)
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: false,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(session); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &SessionRepository{dbMap: dbMap}
+	// 一度リスナーにしてから共同ホストに上書きする
+	if err := r.StoreMember(context.TODO(), &entity.SessionMember{SessionID: "existing_session_id", UserID: "co_host_user", Role: entity.SessionRoleListener}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.StoreMember(context.TODO(), &entity.SessionMember{SessionID: "existing_session_id", UserID: "co_host_user", Role: entity.SessionRoleCoHost}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		want    entity.SessionRole
+		wantErr bool
+	}{
+		{
+			name:    "保存した役割を取得できる",
+			userID:  "co_host_user",
+			want:    entity.SessionRoleCoHost,
+			wantErr: false,
+		},
+		{
+			name:    "役割が割り当てられていないユーザはリスナー",
+			userID:  "listener_user",
+			want:    entity.SessionRoleListener,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.FindMemberRole(context.TODO(), "existing_session_id", tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SessionRepository.FindMemberRole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SessionRepository.FindMemberRole() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_IsMember(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionMemberDTO{}, "session_members")
+	truncateTable(t, dbMap)
+	users := []interface{}{
+		&userDTO{ID: "existing_user", SpotifyUserID: "existing_user_spotify", DisplayName: "existing_user_display_name"},
+		&userDTO{ID: "member_user", SpotifyUserID: "member_user_spotify", DisplayName: "member_user_display_name"},
+		&userDTO{ID: "other_user", SpotifyUserID: "other_user_spotify", DisplayName: "other_user_display_name"},
+	}
+	if err := dbMap.Insert(users...); err != nil {
+		t.Fatal(err)
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "ARCHIVED",
+		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: false,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(session); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbMap.Insert(&sessionMemberDTO{SessionID: "existing_session_id", UserID: "member_user", Role: "LISTENER"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "役割が割り当てられたユーザはメンバー",
+			userID:  "member_user",
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name:    "役割が割り当てられていないユーザはメンバーではない",
+			userID:  "other_user",
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name:    "作成者は役割を割り当てられていないのでメンバーではない",
+			userID:  "existing_user",
+			want:    false,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SessionRepository{dbMap: dbMap}
+			got, err := r.IsMember(context.TODO(), "existing_session_id", tt.userID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SessionRepository.IsMember() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SessionRepository.IsMember() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_StoreBanAndIsBanned(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionBanDTO{}, "session_bans")
+	truncateTable(t, dbMap)
+	user := &userDTO{ID: "existing_user", SpotifyUserID: "existing_user_spotify", DisplayName: "existing_user_display_name"}
+	if err := dbMap.Insert(user); err != nil {
+		t.Fatal(err)
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: false,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(session); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &SessionRepository{dbMap: dbMap}
+	// 同じ参加者を二回追放してもエラーにならない
+	for i := 0; i < 2; i++ {
+		if err := r.StoreBan(context.TODO(), &entity.SessionBan{SessionID: "existing_session_id", ParticipantID: "banned_guest"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		participantID string
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name:          "追放した参加者はtrue",
+			participantID: "banned_guest",
+			want:          true,
+			wantErr:       false,
+		},
+		{
+			name:          "追放していない参加者はfalse",
+			participantID: "other_guest",
+			want:          false,
+			wantErr:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.IsBanned(context.TODO(), "existing_session_id", tt.participantID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SessionRepository.IsBanned() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SessionRepository.IsBanned() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_Update(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PAUSE",
+		DeviceID:               "device_id",
+		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: false,
+		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	sameFieldSession := &sessionDTO{
+		ID:                     "same_field_session_id",
+		Name:                   "same_field_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PAUSE",
+		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+		AllowToControlByOthers: true,
+		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(user, session, sameFieldSession); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		session *entity.Session
+		wantErr bool
+	}{
+		{
+			name: "既に存在するセッションの情報を更新できる",
+			session: &entity.Session{
+				ID:                     "existing_session_id",
+				Name:                   "existing_session_name",
+				CreatorID:              "existing_user",
+				DeviceID:               "new_device_id",
+				StateType:              entity.Play,
+				QueueHead:              1,
+				QueueTracks:            []*entity.QueueTrack{},
+				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+				AllowToControlByOthers: true,
+				ProgressWhenPaused:     2 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
+			},
+			wantErr: false,
+		},
+		{
+			name: "フィールドの値が全てDBの値を一致するセッションで更新してもエラーにならない",
+			session: &entity.Session{
+				ID:                     "same_field_session_id",
+				Name:                   "same_field_session_name",
+				CreatorID:              "existing_user",
+				QueueHead:              0,
+				StateType:              entity.Pause,
+				QueueTracks:            []*entity.QueueTrack{},
+				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+				AllowToControlByOthers: true,
+				ProgressWhenPaused:     1 * time.Second,
+				QueueOrderType:         entity.QueueOrderInsertion,
+				SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+				ExpirationPolicy:       entity.DefaultExpirationPolicy,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSessionRepository(dbMap)
+			if err := r.Update(context.TODO(), tt.session); (err != nil) != tt.wantErr {
+				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr {
+				got, err := r.FindByID(context.TODO(), tt.session.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// 最後の操作日時は更新した時刻になる
+				if got.LastActivityAt.IsZero() {
+					t.Errorf("Update() LastActivityAt should be updated")
+				}
+				opt := cmpopts.IgnoreFields(entity.Session{}, "LastActivityAt")
+				if !cmp.Equal(tt.session, got, opt) {
+					t.Errorf("Update() diff = %v", cmp.Diff(got, tt.session, opt))
+
+				}
+			}
+		})
+	}
+}
+
+func TestSessionRepository_UpdateWithoutActivity(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	truncateTable(t, dbMap)
+	lastActivityAt := time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                    "existing_session_id",
+		Name:                  "existing_session_name",
+		CreatorID:             "existing_user",
+		QueueHead:             0,
+		StateType:             "PLAY",
+		DeviceID:              "device_id",
+		ExpiredAt:             time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+		QueueOrderType:        "INSERTION",
+		SkipVoteThresholdType: "RATIO",
+		SkipVoteThreshold:     0.5,
+		ExpirationPolicyType:  "IDLE",
+		ExpirationHours:       24,
+		LastActivityAt:        lastActivityAt,
+	}
+	if err := dbMap.Insert(user, session); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSessionRepository(dbMap)
+	sess, err := r.FindByID(context.TODO(), "existing_session_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 曲の再生が終わってheadが進んだときを想定する
+	sess.QueueHead = 1
+	if err := r.UpdateWithoutActivity(context.TODO(), sess); err != nil {
+		t.Fatalf("UpdateWithoutActivity() error = %v", err)
+	}
+
+	got, err := r.FindByID(context.TODO(), "existing_session_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.QueueHead != 1 {
+		t.Errorf("UpdateWithoutActivity() QueueHead = %d, want 1", got.QueueHead)
+	}
+	// 参加者の操作ではないので最後の操作日時は更新されない
+	if !got.LastActivityAt.Equal(lastActivityAt) {
+		t.Errorf("UpdateWithoutActivity() LastActivityAt = %v, want %v", got.LastActivityAt, lastActivityAt)
+	}
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "ARCHIVED",
+		ExpiredAt:              time.Now(),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(user, session); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := dbMap.Insert(&queueTrackDTO{Index: i, URI: fmt.Sprintf("uri%d", i), SessionID: "existing_session_id"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{
+			name:    "存在するセッションとキューの曲を削除できる",
+			id:      "existing_session_id",
+			wantErr: nil,
+		},
+		{
+			name:    "存在しないセッションだとErrSessionNotFound",
+			id:      "not_found_session_id",
+			wantErr: entity.ErrSessionNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.Delete(context.TODO(), tt.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.Delete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if _, err := r.FindByID(context.TODO(), tt.id); !errors.Is(err, entity.ErrSessionNotFound) {
+				t.Errorf("SessionRepository.Delete() session is not deleted: error = %v", err)
+			}
+			queueTracks, err := r.getQueueTracksBySessionID(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(queueTracks) != 0 {
+				t.Errorf("SessionRepository.Delete() queue tracks are not deleted: %v", queueTracks)
+			}
+		})
+	}
+}
+
+func TestSessionRepository_StoreQueueTrack(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	sessionHasQueueTrack := &sessionDTO{
+		ID:                     "session_with_queue_track_id",
+		Name:                   "session_with_queue_track_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Now(),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	sessionHasNoQueueTrack := &sessionDTO{
+		ID:                     "session_with_no_queue_track_id",
+		Name:                   "session_with_no_queue_track_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Now(),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	queueTracks := &queueTrackDTO{
+		Index:     0,
+		URI:       "uri",
+		SessionID: "session_with_queue_track_id",
+	}
+	if err := dbMap.Insert(user, sessionHasQueueTrack, sessionHasNoQueueTrack, queueTracks); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		queueTrack *entity.QueueTrackToStore
+		index      int
+		wantIndex  int
+		wantURIs   []string
+		wantErr    error
+	}{
+		{
+			name: "すでにひも付いているqueue_tracksが1つ以上存在するsessionsに新規queue_tracksを正しく紐づけて保存できる",
+			queueTrack: &entity.QueueTrackToStore{
+				URI:         "new_uri",
+				SessionID:   "session_with_queue_track_id",
+				AddedBy:     "existing_user",
+				AddedByName: "existing_user_display_name",
+				AddedAt:     time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+			index:     1,
+			wantIndex: 1,
+			wantURIs:  []string{"uri", "new_uri"},
+			wantErr:   nil,
+		},
+		{
+			name: "ひも付いているqueue_tracksが1つも存在しないsessionsに新規queue_tracksを正しく紐づけて保存できる",
+			queueTrack: &entity.QueueTrackToStore{
+				URI:         "new_uri",
+				SessionID:   "session_with_no_queue_track_id",
+				AddedBy:     "",
+				AddedByName: entity.GuestDisplayName,
+				AddedAt:     time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+			index:     0,
+			wantIndex: 0,
+			wantURIs:  []string{"new_uri"},
+			wantErr:   nil,
+		},
+		{
+			name: "キューの途中に挿入すると、それ以降のqueue_tracksのindexが1つずつ後ろにずれる",
+			queueTrack: &entity.QueueTrackToStore{
+				URI:         "next_uri",
+				SessionID:   "session_with_queue_track_id",
+				AddedBy:     "existing_user",
+				AddedByName: "existing_user_display_name",
+				AddedAt:     time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+			index:     1,
+			wantIndex: 1,
+			wantURIs:  []string{"uri", "next_uri", "new_uri"},
+			wantErr:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.StoreQueueTrack(context.TODO(), tt.queueTrack, tt.index); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.StoreQueueTracks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr == nil {
+				queueTracks, _ := r.getQueueTracksBySessionID(tt.queueTrack.SessionID)
+				queueTrack, notFound := findQueueTrackByIndexAndSessionID(queueTracks, tt.wantIndex, tt.queueTrack.SessionID)
+
+				if (notFound != nil) || (queueTrack.URI != tt.queueTrack.URI) {
+					t.Errorf("SessionRepository.StoreQueueTrack() queue_track not found. wantIndex %v, wantSessionID %v", tt.wantIndex, tt.queueTrack.SessionID)
+					return
+				}
+				if queueTrack.AddedBy != tt.queueTrack.AddedBy || queueTrack.AddedByName != tt.queueTrack.AddedByName || !queueTrack.AddedAt.Equal(tt.queueTrack.AddedAt) {
+					t.Errorf("SessionRepository.StoreQueueTrack() got = %v, want %v", queueTrack, tt.queueTrack)
+				}
+
+				uris := make([]string, len(queueTracks))
+				for i, qt := range queueTracks {
+					uris[i] = qt.URI
+				}
+				if !cmp.Equal(uris, tt.wantURIs) {
+					t.Errorf("SessionRepository.StoreQueueTrack() uris diff = %v", cmp.Diff(tt.wantURIs, uris))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionRepository_DeleteQueueTrack(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	truncateTable(t, dbMap)
+	user := &userDTO{
+		ID:            "existing_user",
+		SpotifyUserID: "existing_user_spotify",
+		DisplayName:   "existing_user_display_name",
+	}
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		ExpiredAt:              time.Now(),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+	if err := dbMap.Insert(user, session); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if err := dbMap.Insert(&queueTrackDTO{Index: i, URI: fmt.Sprintf("uri%d", i), SessionID: "existing_session_id"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &SessionRepository{
+		dbMap: dbMap,
+	}
+	if err := r.DeleteQueueTrack(context.TODO(), "existing_session_id", 1); err != nil {
+		t.Fatalf("SessionRepository.DeleteQueueTrack() error = %v", err)
+	}
+
+	want := []*entity.QueueTrack{
+		{Index: 0, URI: "uri0", SessionID: "existing_session_id"},
+		{Index: 1, URI: "uri2", SessionID: "existing_session_id"},
+		{Index: 2, URI: "uri3", SessionID: "existing_session_id"},
+	}
+	queueTracks, err := r.getQueueTracksBySessionID("existing_session_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(queueTracks, want) {
+		t.Errorf("SessionRepository.DeleteQueueTrack() diff = %v", cmp.Diff(queueTracks, want))
+	}
+}
+
+func TestSessionRepository_MoveQueueTrack(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+
+	tests := []struct {
+		name    string
+		from    int
+		to      int
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "後ろの曲を前に移動できる",
+			from:    3,
+			to:      1,
+			want:    []string{"uri0", "uri3", "uri1", "uri2"},
+			wantErr: nil,
+		},
+		{
+			name:    "前の曲を後ろに移動できる",
+			from:    1,
+			to:      3,
+			want:    []string{"uri0", "uri2", "uri3", "uri1"},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncateTable(t, dbMap)
+			user := &userDTO{
+				ID:            "existing_user",
+				SpotifyUserID: "existing_user_spotify",
+				DisplayName:   "existing_user_display_name",
+			}
+			session := &sessionDTO{
+				ID:                     "existing_session_id",
+				Name:                   "existing_session_name",
+				CreatorID:              "existing_user",
+				QueueHead:              0,
+				StateType:              "PLAY",
+				ExpiredAt:              time.Now(),
+				AllowToControlByOthers: true,
+				QueueOrderType:         "INSERTION",
+				SkipVoteThresholdType:  "RATIO",
+				SkipVoteThreshold:      0.5,
+				ExpirationPolicyType:   "FIXED",
+				ExpirationHours:        72,
+			}
+			if err := dbMap.Insert(user, session); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 4; i++ {
+				if err := dbMap.Insert(&queueTrackDTO{Index: i, URI: fmt.Sprintf("uri%d", i), SessionID: "existing_session_id"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.MoveQueueTrack(context.TODO(), "existing_session_id", tt.from, tt.to); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.MoveQueueTrack() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			queueTracks, err := r.getQueueTracksBySessionID("existing_session_id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(queueTracks))
+			for i, qt := range queueTracks {
+				got[i] = qt.URI
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("SessionRepository.MoveQueueTrack() diff = %v", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestSessionRepository_UpdateQueueTrackIndexes(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+
+	tests := []struct {
+		name    string
+		indexes map[int]int
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "複数の曲のindexをまとめて入れ替えられる",
+			indexes: map[int]int{1: 2, 2: 3, 3: 1},
+			want:    []string{"uri0", "uri3", "uri1", "uri2"},
+			wantErr: nil,
+		},
+		{
+			name:    "空のmapを渡すと何も変わらない",
+			indexes: map[int]int{},
+			want:    []string{"uri0", "uri1", "uri2", "uri3"},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			truncateTable(t, dbMap)
+			user := &userDTO{
+				ID:            "existing_user",
+				SpotifyUserID: "existing_user_spotify",
+				DisplayName:   "existing_user_display_name",
+			}
+			session := &sessionDTO{
+				ID:                     "existing_session_id",
+				Name:                   "existing_session_name",
+				CreatorID:              "existing_user",
+				QueueHead:              0,
+				StateType:              "PLAY",
+				ExpiredAt:              time.Now(),
+				AllowToControlByOthers: true,
+				QueueOrderType:         "INSERTION",
+				SkipVoteThresholdType:  "RATIO",
+				SkipVoteThreshold:      0.5,
+				ExpirationPolicyType:   "FIXED",
+				ExpirationHours:        72,
+			}
+			if err := dbMap.Insert(user, session); err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 4; i++ {
+				if err := dbMap.Insert(&queueTrackDTO{Index: i, URI: fmt.Sprintf("uri%d", i), SessionID: "existing_session_id"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.UpdateQueueTrackIndexes(context.TODO(), "existing_session_id", tt.indexes); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.UpdateQueueTrackIndexes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			queueTracks, err := r.getQueueTracksBySessionID("existing_session_id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, len(queueTracks))
+			for i, qt := range queueTracks {
+				got[i] = qt.URI
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("SessionRepository.UpdateQueueTrackIndexes() diff = %v", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestSessionRepository_UpdateQueueTrackPlayback(t *testing.T) {
+	// Prepare
+	dbMap, err := NewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
 	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
+	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
 	truncateTable(t, dbMap)
 	user := &userDTO{
 		ID:            "existing_user",
@@ -208,51 +1368,57 @@ func TestSessionRepository_StoreSession(t *testing.T) {
 		CreatorID:              "existing_user",
 		QueueHead:              0,
 		StateType:              "PLAY",
-		DeviceID:               "device_id",
-		ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExpiredAt:              time.Now(),
 		AllowToControlByOthers: true,
-		ProgressWhenPaused:     (1 * time.Second).Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
-	if err := dbMap.Insert(user, session); err != nil {
+	queueTrack1 := &queueTrackDTO{
+		Index:     0,
+		URI:       "existing_uri1",
+		SessionID: "existing_session_id",
+	}
+	queueTrack2 := &queueTrackDTO{
+		Index:     1,
+		URI:       "existing_uri2",
+		SessionID: "existing_session_id",
+	}
+	if err := dbMap.Insert(user, session, queueTrack1, queueTrack2); err != nil {
 		t.Fatal(err)
 	}
 
+	startedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	endedAt := time.Date(2020, 10, 1, 12, 3, 0, 0, time.UTC)
+
 	tests := []struct {
-		name    string
-		session *entity.Session
-		wantErr error
+		name       string
+		queueTrack *entity.QueueTrack
+		wantErr    bool
 	}{
 		{
-			name: "新規sessionを正しく保存できる",
-			session: &entity.Session{
-				ID:                     "new_session_id",
-				Name:                   "new_session_name",
-				CreatorID:              "existing_user",
-				DeviceID:               "new_device_id",
-				StateType:              "PLAY",
-				QueueHead:              0,
-				QueueTracks:            nil,
-				ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
-				AllowToControlByOthers: true,
-				ProgressWhenPaused:     1 * time.Second,
+			name: "再生を開始した日時を保存できる",
+			queueTrack: &entity.QueueTrack{
+				Index:     1,
+				URI:       "existing_uri2",
+				SessionID: "existing_session_id",
+				StartedAt: startedAt,
 			},
-			wantErr: nil,
+			wantErr: false,
 		},
 		{
-			name: "登録済みのsessionの場合ErrSessionAlreadyExistedを返す",
-			session: &entity.Session{
-				ID:                     "existing_session_id",
-				Name:                   "existing_session_name",
-				CreatorID:              "existing_user",
-				DeviceID:               "device_id",
-				StateType:              "PLAY",
-				QueueHead:              0,
-				QueueTracks:            nil,
-				ExpiredAt:              time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
-				AllowToControlByOthers: true,
-				ProgressWhenPaused:     1 * time.Second,
+			name: "再生が終了した日時と理由を保存できる",
+			queueTrack: &entity.QueueTrack{
+				Index:     1,
+				URI:       "existing_uri2",
+				SessionID: "existing_session_id",
+				StartedAt: startedAt,
+				EndedAt:   endedAt,
+				EndReason: entity.QueueTrackSkipped,
 			},
-			wantErr: entity.ErrSessionAlreadyExisted,
+			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
@@ -260,22 +1426,35 @@ func TestSessionRepository_StoreSession(t *testing.T) {
 			r := &SessionRepository{
 				dbMap: dbMap,
 			}
-			if err := r.StoreSession(context.TODO(), tt.session); !errors.Is(err, tt.wantErr) {
-				t.Errorf("SessionRepository.StoreSessions() error = %v, wantErr %v", err, tt.wantErr)
+			if err := r.UpdateQueueTrackPlayback(context.TODO(), tt.queueTrack); (err != nil) != tt.wantErr {
+				t.Errorf("SessionRepository.UpdateQueueTrackPlayback() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			got, err := r.getQueueTracksBySessionID("existing_session_id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got[0].StartedAt.IsZero() || !got[0].EndedAt.IsZero() || got[0].EndReason != "" {
+				t.Errorf("other queue track should not be updated: %+v", got[0])
+			}
+			if !got[1].StartedAt.Equal(tt.queueTrack.StartedAt) || !got[1].EndedAt.Equal(tt.queueTrack.EndedAt) || got[1].EndReason != tt.queueTrack.EndReason {
+				t.Errorf("SessionRepository.UpdateQueueTrackPlayback() got = %+v, want %+v", got[1], tt.queueTrack)
 			}
 		})
 	}
 }
 
-func TestSessionRepository_Update(t *testing.T) {
+func TestSessionRepository_StoreSkipVote(t *testing.T) {
 	// Prepare
 	dbMap, err := NewDB()
 	if err != nil {
 		t.Fatal(err)
 	}
-	dbMap.AddTableWithName(sessionDTO{}, "sessions")
 	dbMap.AddTableWithName(userDTO{}, "users")
+	dbMap.AddTableWithName(sessionDTO{}, "sessions")
 	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	dbMap.AddTableWithName(skipVoteDTO{}, "skip_votes")
 	truncateTable(t, dbMap)
 	user := &userDTO{
 		ID:            "existing_user",
@@ -287,87 +1466,77 @@ func TestSessionRepository_Update(t *testing.T) {
 		Name:                   "existing_session_name",
 		CreatorID:              "existing_user",
 		QueueHead:              0,
-		StateType:              "PAUSE",
-		DeviceID:               "device_id",
-		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
-		AllowToControlByOthers: false,
-		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
-	}
-	sameFieldSession := &sessionDTO{
-		ID:                     "same_field_session_id",
-		Name:                   "same_field_session_name",
-		CreatorID:              "existing_user",
-		QueueHead:              0,
-		StateType:              "PAUSE",
-		ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
+		StateType:              "PLAY",
+		ExpiredAt:              time.Now(),
 		AllowToControlByOthers: true,
-		ProgressWhenPaused:     1 * time.Second.Milliseconds(),
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
-	if err := dbMap.Insert(user, session, sameFieldSession); err != nil {
+	if err := dbMap.Insert(user, session); err != nil {
 		t.Fatal(err)
 	}
 
 	tests := []struct {
-		name    string
-		session *entity.Session
-		wantErr bool
+		name      string
+		index     int
+		userID    string
+		wantCount int
+		wantErr   error
 	}{
 		{
-			name: "既に存在するセッションの情報を更新できる",
-			session: &entity.Session{
-				ID:                     "existing_session_id",
-				Name:                   "existing_session_name",
-				CreatorID:              "existing_user",
-				DeviceID:               "new_device_id",
-				StateType:              entity.Play,
-				QueueHead:              1,
-				QueueTracks:            []*entity.QueueTrack{},
-				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
-				AllowToControlByOthers: true,
-				ProgressWhenPaused:     2 * time.Second,
-			},
-			wantErr: false,
+			name:      "スキップ投票を保存できる",
+			index:     0,
+			userID:    "user1",
+			wantCount: 1,
+			wantErr:   nil,
 		},
 		{
-			name: "フィールドの値が全てDBの値を一致するセッションで更新してもエラーにならない",
-			session: &entity.Session{
-				ID:                     "same_field_session_id",
-				Name:                   "same_field_session_name",
-				CreatorID:              "existing_user",
-				QueueHead:              0,
-				StateType:              entity.Pause,
-				QueueTracks:            []*entity.QueueTrack{},
-				ExpiredAt:              time.Date(2020, time.December, 1, 12, 0, 0, 0, time.UTC),
-				AllowToControlByOthers: true,
-				ProgressWhenPaused:     1 * time.Second,
-			},
-			wantErr: false,
+			name:      "別のユーザの投票は別に数えられる",
+			index:     0,
+			userID:    "user2",
+			wantCount: 2,
+			wantErr:   nil,
+		},
+		{
+			name:      "同じユーザが同じ曲に投票するとErrSkipVoteAlreadyExisted",
+			index:     0,
+			userID:    "user1",
+			wantCount: 2,
+			wantErr:   entity.ErrSkipVoteAlreadyExisted,
+		},
+		{
+			name:      "別の曲への投票は別に数えられる",
+			index:     1,
+			userID:    "user1",
+			wantCount: 1,
+			wantErr:   nil,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := NewSessionRepository(dbMap)
-			if err := r.Update(context.TODO(), tt.session); (err != nil) != tt.wantErr {
-				t.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			r := &SessionRepository{
+				dbMap: dbMap,
+			}
+			if err := r.StoreSkipVote(context.TODO(), "existing_session_id", tt.index, tt.userID); !errors.Is(err, tt.wantErr) {
+				t.Errorf("SessionRepository.StoreSkipVote() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 
-			if !tt.wantErr {
-				got, err := r.FindByID(context.TODO(), tt.session.ID)
-				if err != nil {
-					t.Fatal(err)
-				}
-
-				if !cmp.Equal(tt.session, got) {
-					t.Errorf("Update() diff = %v", cmp.Diff(got, tt.session))
-
-				}
+			got, err := r.CountSkipVotes(context.TODO(), "existing_session_id", tt.index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantCount {
+				t.Errorf("SessionRepository.CountSkipVotes() = %d, want %d", got, tt.wantCount)
 			}
 		})
 	}
 }
 
-func TestSessionRepository_StoreQueueTrack(t *testing.T) {
+func TestSessionRepository_StoreQueueTrackVote(t *testing.T) {
 	// Prepare
 	dbMap, err := NewDB()
 	if err != nil {
@@ -376,62 +1545,82 @@ func TestSessionRepository_StoreQueueTrack(t *testing.T) {
 	dbMap.AddTableWithName(userDTO{}, "users")
 	dbMap.AddTableWithName(sessionDTO{}, "sessions")
 	dbMap.AddTableWithName(queueTrackDTO{}, "queue_tracks")
+	dbMap.AddTableWithName(queueTrackVoteDTO{}, "queue_track_votes")
 	truncateTable(t, dbMap)
 	user := &userDTO{
 		ID:            "existing_user",
 		SpotifyUserID: "existing_user_spotify",
 		DisplayName:   "existing_user_display_name",
 	}
-	sessionHasQueueTrack := &sessionDTO{
-		ID:                     "session_with_queue_track_id",
-		Name:                   "session_with_queue_track_name",
-		CreatorID:              "existing_user",
-		QueueHead:              0,
-		StateType:              "PLAY",
-		ExpiredAt:              time.Now(),
-		AllowToControlByOthers: true,
-	}
-	sessionHasNoQueueTrack := &sessionDTO{
-		ID:                     "session_with_no_queue_track_id",
-		Name:                   "session_with_no_queue_track_name",
+	session := &sessionDTO{
+		ID:                     "existing_session_id",
+		Name:                   "existing_session_name",
 		CreatorID:              "existing_user",
 		QueueHead:              0,
 		StateType:              "PLAY",
 		ExpiredAt:              time.Now(),
 		AllowToControlByOthers: true,
+		QueueOrderType:         "VOTE",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
-	queueTracks := &queueTrackDTO{
+	queueTrack1 := &queueTrackDTO{
 		Index:     0,
-		URI:       "uri",
-		SessionID: "session_with_queue_track_id",
+		URI:       "existing_uri1",
+		SessionID: "existing_session_id",
 	}
-	if err := dbMap.Insert(user, sessionHasQueueTrack, sessionHasNoQueueTrack, queueTracks); err != nil {
+	queueTrack2 := &queueTrackDTO{
+		Index:     1,
+		URI:       "existing_uri2",
+		SessionID: "existing_session_id",
+	}
+	if err := dbMap.Insert(user, session, queueTrack1, queueTrack2); err != nil {
 		t.Fatal(err)
 	}
 
 	tests := []struct {
-		name       string
-		queueTrack *entity.QueueTrackToStore
-		wantIndex  int
-		wantErr    error
+		name        string
+		deleteVote  bool
+		userID      string
+		wantDeleted bool
+		wantErr     bool
+		wantVotes   int
 	}{
 		{
-			name: "すでにひも付いているqueue_tracksが1つ以上存在するsessionsに新規queue_tracksを正しく紐づけて保存できる",
-			queueTrack: &entity.QueueTrackToStore{
-				URI:       "new_uri",
-				SessionID: "session_with_queue_track_id",
-			},
-			wantIndex: 1,
-			wantErr:   nil,
+			name:      "投票を保存できる",
+			userID:    "user1",
+			wantErr:   false,
+			wantVotes: 1,
 		},
 		{
-			name: "ひも付いているqueue_tracksが1つも存在しないsessionsに新規queue_tracksを正しく紐づけて保存できる",
-			queueTrack: &entity.QueueTrackToStore{
-				URI:       "new_uri",
-				SessionID: "session_with_no_queue_track_id",
-			},
-			wantIndex: 0,
-			wantErr:   nil,
+			name:      "別のユーザの投票は別に数えられる",
+			userID:    "user2",
+			wantErr:   false,
+			wantVotes: 2,
+		},
+		{
+			name:      "同じユーザが同じ曲に投票するとエラー",
+			userID:    "user1",
+			wantErr:   true,
+			wantVotes: 2,
+		},
+		{
+			name:        "投票を削除できる",
+			deleteVote:  true,
+			userID:      "user1",
+			wantDeleted: true,
+			wantErr:     false,
+			wantVotes:   1,
+		},
+		{
+			name:        "存在しない投票を削除しようとするとfalse",
+			deleteVote:  true,
+			userID:      "user1",
+			wantDeleted: false,
+			wantErr:     false,
+			wantVotes:   1,
 		},
 	}
 	for _, tt := range tests {
@@ -439,18 +1628,28 @@ func TestSessionRepository_StoreQueueTrack(t *testing.T) {
 			r := &SessionRepository{
 				dbMap: dbMap,
 			}
-			if err := r.StoreQueueTrack(context.TODO(), tt.queueTrack); !errors.Is(err, tt.wantErr) {
-				t.Errorf("SessionRepository.StoreQueueTracks() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if tt.deleteVote {
+				deleted, err := r.DeleteQueueTrackVote(context.TODO(), "existing_session_id", 1, tt.userID)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("SessionRepository.DeleteQueueTrackVote() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
+				if deleted != tt.wantDeleted {
+					t.Errorf("SessionRepository.DeleteQueueTrackVote() = %v, want %v", deleted, tt.wantDeleted)
+				}
+			} else {
+				if err := r.StoreQueueTrackVote(context.TODO(), "existing_session_id", 1, tt.userID); (err != nil) != tt.wantErr {
+					t.Errorf("SessionRepository.StoreQueueTrackVote() error = %v, wantErr %v", err, tt.wantErr)
+					return
+				}
 			}
 
-			if tt.wantErr == nil {
-				queueTracks, _ := r.getQueueTracksBySessionID(tt.queueTrack.SessionID)
-				queueTrack, notFound := findQueueTrackByIndexAndSessionID(queueTracks, tt.wantIndex, tt.queueTrack.SessionID)
-
-				if (notFound != nil) || (queueTrack.URI != tt.queueTrack.URI) {
-					t.Errorf("SessionRepository.StoreQueueTrack() queue_track not found. wantIndex %v, wantSessionID %v", tt.wantIndex, tt.queueTrack.SessionID)
-				}
+			got, err := r.getQueueTracksBySessionID("existing_session_id")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got[0].Votes != 0 || got[1].Votes != tt.wantVotes {
+				t.Errorf("votes = [%d, %d], want [0, %d]", got[0].Votes, got[1].Votes, tt.wantVotes)
 			}
 		})
 	}
@@ -473,20 +1672,30 @@ func TestSessionRepository_getQueueTrackBySessionID(t *testing.T) {
 	}
 
 	session := &sessionDTO{
-		ID:        "existing_session_id",
-		Name:      "existing_session_name",
-		CreatorID: "existing_user",
-		QueueHead: 0,
-		StateType: "PLAY",
-		ExpiredAt: time.Now(),
+		ID:                    "existing_session_id",
+		Name:                  "existing_session_name",
+		CreatorID:             "existing_user",
+		QueueHead:             0,
+		StateType:             "PLAY",
+		ExpiredAt:             time.Now(),
+		QueueOrderType:        "INSERTION",
+		SkipVoteThresholdType: "RATIO",
+		SkipVoteThreshold:     0.5,
+		ExpirationPolicyType:  "FIXED",
+		ExpirationHours:       72,
 	}
 	sessionHasManyQueueTracks := &sessionDTO{
-		ID:        "session_has_many_queue_tracks_id",
-		Name:      "session_has_many_queue_tracks_name",
-		CreatorID: "existing_user",
-		QueueHead: 0,
-		StateType: "PLAY",
-		ExpiredAt: time.Now(),
+		ID:                    "session_has_many_queue_tracks_id",
+		Name:                  "session_has_many_queue_tracks_name",
+		CreatorID:             "existing_user",
+		QueueHead:             0,
+		StateType:             "PLAY",
+		ExpiredAt:             time.Now(),
+		QueueOrderType:        "INSERTION",
+		SkipVoteThresholdType: "RATIO",
+		SkipVoteThreshold:     0.5,
+		ExpirationPolicyType:  "FIXED",
+		ExpirationHours:       72,
 	}
 
 	queueTrack1 := &queueTrackDTO{
@@ -609,13 +1818,18 @@ func TestSessionRepository_FindCreatorTokenBySessionID(t *testing.T) {
 		t.Fatal(err)
 	}
 	if err := dbMap.Insert(&sessionDTO{
-		ID:        "exist_session_id",
-		Name:      "session_name",
-		CreatorID: "creator_user_id",
-		QueueHead: 0,
-		StateType: "STOP",
-		DeviceID:  "device_id",
-		ExpiredAt: time.Now(),
+		ID:                    "exist_session_id",
+		Name:                  "session_name",
+		CreatorID:             "creator_user_id",
+		QueueHead:             0,
+		StateType:             "STOP",
+		DeviceID:              "device_id",
+		ExpiredAt:             time.Now(),
+		QueueOrderType:        "INSERTION",
+		SkipVoteThresholdType: "RATIO",
+		SkipVoteThreshold:     0.5,
+		ExpirationPolicyType:  "FIXED",
+		ExpirationHours:       72,
 	}); err != nil {
 		t.Fatal(err)
 	}
@@ -693,6 +1907,11 @@ func TestSessionRepository_ArchiveSessionsForBatch(t *testing.T) {
 		DeviceID:               "device_id",
 		ExpiredAt:              time.Now().Add(-1 * 24 * time.Hour),
 		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
 
 	newSession := &sessionDTO{
@@ -704,6 +1923,11 @@ func TestSessionRepository_ArchiveSessionsForBatch(t *testing.T) {
 		DeviceID:               "device_id",
 		ExpiredAt:              time.Now().Add(1 * 24 * time.Hour),
 		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
 	}
 
 	notAllowedSessions := &sessionDTO{
@@ -715,6 +1939,62 @@ func TestSessionRepository_ArchiveSessionsForBatch(t *testing.T) {
 		DeviceID:               "device_id",
 		ExpiredAt:              time.Now().Add(-1 * 24 * time.Hour),
 		AllowToControlByOthers: false,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		ExpirationPolicyType:   "FIXED",
+		ExpirationHours:        72,
+	}
+
+	idleSession := &sessionDTO{
+		ID:                     "existing_session_id4",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		DeviceID:               "device_id",
+		ExpiredAt:              time.Now().Add(1 * 24 * time.Hour),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		LastActivityAt:         time.Now().Add(-7 * time.Hour),
+		ExpirationPolicyType:   "IDLE",
+		ExpirationHours:        6,
+	}
+
+	activeSession := &sessionDTO{
+		ID:                     "existing_session_id5",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		DeviceID:               "device_id",
+		ExpiredAt:              time.Now().Add(-1 * 24 * time.Hour),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		LastActivityAt:         time.Now().Add(-5 * time.Hour),
+		ExpirationPolicyType:   "IDLE",
+		ExpirationHours:        6,
+	}
+
+	neverSession := &sessionDTO{
+		ID:                     "existing_session_id6",
+		Name:                   "existing_session_name",
+		CreatorID:              "existing_user",
+		QueueHead:              0,
+		StateType:              "PLAY",
+		DeviceID:               "device_id",
+		ExpiredAt:              time.Now().Add(-1 * 24 * time.Hour),
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThresholdType:  "RATIO",
+		SkipVoteThreshold:      0.5,
+		LastActivityAt:         time.Now().Add(-365 * 24 * time.Hour),
+		ExpirationPolicyType:   "NEVER",
+		ExpirationHours:        0,
 	}
 
 	tests := []struct {
@@ -733,8 +2013,23 @@ func TestSessionRepository_ArchiveSessionsForBatch(t *testing.T) {
 			wantState: "ARCHIVED",
 		},
 		{
-			name:      "古くても他人の操作を許可していないsessionはARCHIVEされない",
+			name:      "他人の操作を許可していないsessionも古ければARCHIVEされる",
 			session:   notAllowedSessions,
+			wantState: "ARCHIVED",
+		},
+		{
+			name:      "IDLEのsessionは最後の操作から指定された時間が経過するとARCHIVEされる",
+			session:   idleSession,
+			wantState: "ARCHIVED",
+		},
+		{
+			name:      "IDLEのsessionはexpired_atを過ぎていても最近操作されていればARCHIVEされない",
+			session:   activeSession,
+			wantState: "PLAY",
+		},
+		{
+			name:      "NEVERのsessionはARCHIVEされない",
+			session:   neverSession,
 			wantState: "PLAY",
 		},
 	}
@@ -757,11 +2052,20 @@ func TestSessionRepository_ArchiveSessionsForBatch(t *testing.T) {
 			r := &SessionRepository{
 				dbMap: dbMap,
 			}
-			if err := r.ArchiveSessionsForBatch(); err != nil {
+			archived, err := r.ArchiveSessionsForBatch()
+			if err != nil {
 				t.Errorf("SessionRepository.ArchiveSessionsForBatch() error = %v", err)
 				return
 			}
 
+			wantArchived := []*entity.ArchivedSession{}
+			if tt.wantState == entity.Archived {
+				wantArchived = []*entity.ArchivedSession{{ID: tt.session.ID, PrevStateType: entity.StateType(tt.session.StateType)}}
+			}
+			if !cmp.Equal(archived, wantArchived, cmpopts.EquateEmpty()) {
+				t.Errorf("SessionRepository.ArchiveSessionsForBatch() archived diff = %v", cmp.Diff(archived, wantArchived, cmpopts.EquateEmpty()))
+			}
+
 			session, err := r.FindByID(context.TODO(), tt.session.ID)
 			if err != nil {
 				t.Errorf("SessionRepository.ArchiveSessionsForBatch() error = %v", err)
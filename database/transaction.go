@@ -10,7 +10,9 @@ import (
 var txKey = struct{}{}
 
 type TransactionDAO interface {
+	Select(i interface{}, query string, args ...interface{}) ([]interface{}, error)
 	SelectOne(holder interface{}, query string, args ...interface{}) error
+	SelectInt(query string, args ...interface{}) (int64, error)
 	Insert(list ...interface{}) error
 	Update(list ...interface{}) (int64, error)
 	Exec(query string, args ...interface{}) (sql.Result, error)
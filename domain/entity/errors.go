@@ -12,6 +12,10 @@ var (
 	ErrSessionNotFound = errors.New("session not found")
 	// ErrSessionAlreadyExisted はセッションが既に存在しているときのエラーを表します。
 	ErrSessionAlreadyExisted = errors.New("session has already existed")
+	// ErrJoinCodeAlreadyExisted はセッションの参加用のコードが他のセッションと重複しているときのエラーを表します。
+	ErrJoinCodeAlreadyExisted = errors.New("join code has already existed")
+	// ErrInvalidJoinCode は不正な形式の参加用のコードであるというエラーを表します。
+	ErrInvalidJoinCode = errors.New("invalid join code")
 	// ErrSessionAllTracksFinished はセッションに追加された全てのトラックの再生が全て終了しているエラーを表します。
 	ErrSessionAllTracksFinished = errors.New("all tracks has already finished")
 	// ErrSessionPlayingDifferentTrack はキュー先頭の曲と異なる曲が再生されているエラーを表します。
@@ -21,6 +25,19 @@ var (
 
 	// ErrUserIsNotSessionCreator はユーザがセッションの作成者でないときのエラーを表します。
 	ErrUserIsNotSessionCreator = errors.New("user is not session's creator")
+	// ErrUserIsNotSessionMember はユーザがセッションの作成者でもメンバーでもないときのエラーを表します。
+	ErrUserIsNotSessionMember = errors.New("user is not session's creator or member")
+
+	// ErrInvalidSessionRole は不正なセッションにおける役割であるというエラーを表します。
+	ErrInvalidSessionRole = errors.New("invalid session role")
+	// ErrCreatorRoleNotEditable はセッションの作成者の役割を変更しようとしたり、他のユーザを作成者にしようとしたときのエラーを表します。
+	ErrCreatorRoleNotEditable = errors.New("creator role is not editable")
+	// ErrInvalidParticipantID は不正なセッションの参加者のIDであるというエラーを表します。
+	ErrInvalidParticipantID = errors.New("invalid participant id")
+	// ErrCreatorCannotBeBanned はセッションの作成者を追放しようとしたときのエラーを表します。
+	ErrCreatorCannotBeBanned = errors.New("creator cannot be banned")
+	// ErrParticipantBanned はセッションから追放された参加者が操作しようとしたときのエラーを表します。
+	ErrParticipantBanned = errors.New("participant is banned from session")
 
 	// ErrQueueTrackNotFound はセッションに紐付くQueueTrackが存在しないエラーを表します。
 	ErrQueueTrackNotFound = errors.New("queue track not found")
@@ -28,6 +45,37 @@ var (
 	// ErrNextQueueTrackNotFound は次に再生すべきQueueTrackが存在しないエラーを表します。
 	ErrNextQueueTrackNotFound = errors.New("next queue track not found")
 
+	// ErrInvalidSpotifyURI はキューに追加できる形式ではないSpotifyのURIが指定されたエラーを表します。
+	ErrInvalidSpotifyURI = errors.New("invalid spotify uri")
+	// ErrTrackNotFound は指定された曲がSpotifyに存在しないエラーを表します。
+	ErrTrackNotFound = errors.New("track not found")
+	// ErrTooManyTracksToEnqueue は一度に追加できる曲数の上限を超える曲を追加しようとしたエラーを表します。
+	ErrTooManyTracksToEnqueue = errors.New("too many tracks to enqueue")
+
+	// ErrQueueTrackNotEditable は再生済みもしくは現在対象の曲(head)を変更しようとしたときのエラーを表します。
+	ErrQueueTrackNotEditable = errors.New("queue track at or before head is not editable")
+	// ErrInvalidEnqueuePosition は不正な曲の追加位置であるというエラーを表します。
+	ErrInvalidEnqueuePosition = errors.New("invalid enqueue position")
+
+	// ErrSkipVoteAlreadyExisted はユーザが現在の曲に対して既にスキップ投票しているときのエラーを表します。
+	ErrSkipVoteAlreadyExisted = errors.New("skip vote has already existed")
+	// ErrInvalidSkipVoteThreshold は不正なスキップ投票の閾値であるというエラーを表します。
+	ErrInvalidSkipVoteThreshold = errors.New("invalid skip vote threshold")
+	// ErrInvalidExpirationPolicy は不正なセッションのアーカイブのタイミングであるというエラーを表します。
+	ErrInvalidExpirationPolicy = errors.New("invalid expiration policy")
+
+	// ErrInvalidPlaylistExportTarget は不正なプレイリストの書き出し対象であるというエラーを表します。
+	ErrInvalidPlaylistExportTarget = errors.New("invalid playlist export target")
+	// ErrNoTracksToExport はプレイリストに書き出す曲が無いときのエラーを表します。
+	ErrNoTracksToExport = errors.New("no tracks to export")
+
+	// ErrInvalidSessionCloneQueue は不正なセッションの複製時のキューの引き継ぎ方であるというエラーを表します。
+	ErrInvalidSessionCloneQueue = errors.New("invalid session clone queue")
+	// ErrInvalidSessionListCursor は不正なセッション一覧のカーソルであるというエラーを表します。
+	ErrInvalidSessionListCursor = errors.New("invalid session list cursor")
+	// ErrInvalidSessionListLimit はセッション一覧で1ページに返すセッション数が不正であるというエラーを表します。
+	ErrInvalidSessionListLimit = errors.New("invalid session list limit")
+
 	// ErrTokenNotFound はSpotifyのアクセストークンが存在しないエラーを表します。
 	ErrTokenNotFound = errors.New("token not found")
 
@@ -38,6 +86,8 @@ var (
 
 	// ErrInvalidStateType は不正なstate typeであるというエラーを表します。
 	ErrInvalidStateType = errors.New("invalid state type")
+	// ErrInvalidQueueOrderType は不正なqueue order typeであるというエラーを表します。
+	ErrInvalidQueueOrderType = errors.New("invalid queue order type")
 
 	// ErrChangeSessionStateNotPermit はセッションのステートの状態遷移が許可されていない場合のエラーを表します。
 	ErrChangeSessionStateNotPermit = errors.New("requested state is not allowed")
@@ -46,4 +96,9 @@ var (
 	ErrLoginSessionNotFound = errors.New("loginSession not found")
 	// ErrLoginSessionAlreadyExisted はセッション(login)が既に存在しているときのエラーを表します。
 	ErrLoginSessionAlreadyExisted = errors.New("loginSession has already existed")
+
+	// ErrGuestNotFound はゲストが存在しないエラーを表します。
+	ErrGuestNotFound = errors.New("guest not found")
+	// ErrInvalidNickname は不正なゲストのニックネームであるというエラーを表します。
+	ErrInvalidNickname = errors.New("invalid nickname")
 )
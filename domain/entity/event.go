@@ -2,8 +2,12 @@ package entity
 
 // Event はクライアントに送信するイベントを表します。
 type Event struct {
-	Type string `json:"type"`
-	Head *int   `json:"head,omitempty"`
+	Type     string    `json:"type"`
+	Head     *int      `json:"head,omitempty"`
+	SkipVote *SkipVote `json:"skip_vote,omitempty"`
+	Listener *Listener `json:"listener,omitempty"`
+	// ListenerCount はJOIN/LEAVEイベントの発生後にセッションに接続しているリスナーの数です。
+	ListenerCount *int `json:"listener_count,omitempty"`
 }
 
 var (
@@ -12,6 +16,11 @@ var (
 		Type: "ADDTRACK",
 	}
 
+	// EventQueueChanged はセッションのキューの曲が削除されたり並び替えられた際に発されるイベントです。
+	EventQueueChanged = &Event{
+		Type: "QUEUECHANGED",
+	}
+
 	// EventPlay はセッションの再生が開始された際に発されるイベントです。
 	EventPlay = &Event{
 		Type: "PLAY",
@@ -38,6 +47,23 @@ var (
 		Type: "ARCHIVED",
 	}
 
+	// EventDeleted はセッションが削除された際に発されるイベントです。
+	// このイベントを送信した後、セッションに接続している全てのクライアントとの接続は切断されます。
+	EventDeleted = &Event{
+		Type: "DELETED",
+	}
+
+	// EventBanned はセッションから追放された参加者のクライアントにのみ発されるイベントです。
+	// このイベントを送信した後、追放された参加者のクライアントとの接続は切断されます。
+	EventBanned = &Event{
+		Type: "BANNED",
+	}
+
+	// EventSettingsChanged はセッションの名前や説明などの設定が変更された際に発されるイベントです。
+	EventSettingsChanged = &Event{
+		Type: "SETTINGS_CHANGED",
+	}
+
 	// EventUnarchive はセッションのアーカイブが解除された際に発されるイベントです。
 	EventUnarchive = &Event{
 		Type: "UNARCHIVE",
@@ -52,3 +78,33 @@ func NewEventNextTrack(head int) *Event {
 		Head: &head,
 	}
 }
+
+// NewEventSkipVote はセッションの現在の曲(head)に対してスキップ投票された際に発されるイベントを生成します。
+// 投票された曲の位置と、投票の途中経過が含まれます。
+func NewEventSkipVote(head int, skipVote *SkipVote) *Event {
+	return &Event{
+		Type:     "SKIPVOTE",
+		Head:     &head,
+		SkipVote: skipVote,
+	}
+}
+
+// NewEventJoin はセッションに新しいリスナーが接続した際に発されるイベントを生成します。
+// 既に接続しているユーザが別のタブから接続した場合は発されません。
+func NewEventJoin(listener *Listener, listenerCount int) *Event {
+	return &Event{
+		Type:          "JOIN",
+		Listener:      listener,
+		ListenerCount: &listenerCount,
+	}
+}
+
+// NewEventLeave はリスナーがセッションから切断した際に発されるイベントを生成します。
+// 同じユーザが別のタブから接続している場合は発されません。
+func NewEventLeave(listener *Listener, listenerCount int) *Event {
+	return &Event{
+		Type:          "LEAVE",
+		Listener:      listener,
+		ListenerCount: &listenerCount,
+	}
+}
@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ExpirationPolicyType はセッションをアーカイブするタイミングの決め方を表します。
+type ExpirationPolicyType string
+
+const (
+	// ExpirationPolicyFixed は作成もしくはアーカイブの解除から決まった時間が経過したらアーカイブします。
+	ExpirationPolicyFixed ExpirationPolicyType = "FIXED"
+	// ExpirationPolicyIdle は最後に曲の追加や再生などの操作が行われてから決まった時間が経過したらアーカイブします。
+	ExpirationPolicyIdle ExpirationPolicyType = "IDLE"
+	// ExpirationPolicyNever はアーカイブしません。オフィスのBGMのように長期間使い続けるセッション向けです。
+	ExpirationPolicyNever ExpirationPolicyType = "NEVER"
+)
+
+var expirationPolicyTypes = []ExpirationPolicyType{ExpirationPolicyFixed, ExpirationPolicyIdle, ExpirationPolicyNever}
+
+// MaxExpirationHours はセッションをアーカイブするまでの時間として指定できる上限(30日)です。
+const MaxExpirationHours = 24 * 30
+
+// DefaultExpirationPolicy はセッション作成時に指定がなかった場合のアーカイブのタイミングです。
+// 作成もしくはアーカイブの解除から3日が経過したらアーカイブします。
+var DefaultExpirationPolicy = ExpirationPolicy{Type: ExpirationPolicyFixed, Hours: 72}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (t ExpirationPolicyType) String() string {
+	return string(t)
+}
+
+// ExpirationPolicy はセッションをアーカイブするタイミングを表します。
+// TypeがFIXEDとIDLEの場合はHoursがアーカイブするまでの時間、NEVERの場合はHoursは常に0になります。
+type ExpirationPolicy struct {
+	Type  ExpirationPolicyType
+	Hours int
+}
+
+// NewExpirationPolicy はExpirationPolicyを生成します。
+// FIXEDとIDLEの場合は1以上MaxExpirationHours以下の時間のみ受け付け、NEVERの場合は時間を無視します。
+func NewExpirationPolicy(policyType string, hours int) (ExpirationPolicy, error) {
+	for _, t := range expirationPolicyTypes {
+		if t.String() != policyType {
+			continue
+		}
+		if t == ExpirationPolicyNever {
+			return ExpirationPolicy{Type: t, Hours: 0}, nil
+		}
+		if hours < 1 || MaxExpirationHours < hours {
+			return ExpirationPolicy{}, fmt.Errorf("hours = %d: %w", hours, ErrInvalidExpirationPolicy)
+		}
+		return ExpirationPolicy{Type: t, Hours: hours}, nil
+	}
+	return ExpirationPolicy{}, fmt.Errorf("policyType = %s: %w", policyType, ErrInvalidExpirationPolicy)
+}
+
+// ExpiredAt はfromからHours時間後の日時を返します。
+// バッチがexpired_atを見てアーカイブするのはFIXEDの場合のみで、IDLEの場合は最後の操作日時、NEVERの場合はアーカイブしません。
+func (p ExpirationPolicy) ExpiredAt(from time.Time) time.Time {
+	return from.Add(time.Duration(p.Hours) * time.Hour).UTC()
+}
+
+// ArchivedSession はバッチでアーカイブされたセッションのIDと、アーカイブされる前のStateTypeを表します。
+// アーカイブした後に、Spotifyの再生を止めたり再生中だった曲を中断したものとして記録したりするのに使います。
+type ArchivedSession struct {
+	ID            string
+	PrevStateType StateType
+}
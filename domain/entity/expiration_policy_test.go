@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewExpirationPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		policyType string
+		hours      int
+		want       ExpirationPolicy
+		wantErr    error
+	}{
+		{
+			name:       "作成からの時間で指定できる",
+			policyType: "FIXED",
+			hours:      72,
+			want:       ExpirationPolicy{Type: ExpirationPolicyFixed, Hours: 72},
+			wantErr:    nil,
+		},
+		{
+			name:       "最後の操作からの時間で指定できる",
+			policyType: "IDLE",
+			hours:      6,
+			want:       ExpirationPolicy{Type: ExpirationPolicyIdle, Hours: 6},
+			wantErr:    nil,
+		},
+		{
+			name:       "アーカイブしない場合は時間を無視する",
+			policyType: "NEVER",
+			hours:      10,
+			want:       ExpirationPolicy{Type: ExpirationPolicyNever, Hours: 0},
+			wantErr:    nil,
+		},
+		{
+			name:       "時間が0だとErrInvalidExpirationPolicy",
+			policyType: "IDLE",
+			hours:      0,
+			want:       ExpirationPolicy{},
+			wantErr:    ErrInvalidExpirationPolicy,
+		},
+		{
+			name:       "時間が上限を超えるとErrInvalidExpirationPolicy",
+			policyType: "FIXED",
+			hours:      MaxExpirationHours + 1,
+			want:       ExpirationPolicy{},
+			wantErr:    ErrInvalidExpirationPolicy,
+		},
+		{
+			name:       "無効な種類だとErrInvalidExpirationPolicy",
+			policyType: "invalid",
+			hours:      1,
+			want:       ExpirationPolicy{},
+			wantErr:    ErrInvalidExpirationPolicy,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewExpirationPolicy(tt.policyType, tt.hours)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewExpirationPolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewExpirationPolicy() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxGuestNicknameLength はゲストのニックネームの最大文字数です。
+const MaxGuestNicknameLength = 30
+
+// GuestLifetime はゲストを登録してからトークンが使えなくなるまでの期間です。CookieのMaxAgeと揃えています。
+const GuestLifetime = 7 * 24 * time.Hour
+
+// Guest はSpotifyアカウントでログインせずにセッションに参加するユーザを表します。
+// ゲストを識別するトークンはCookieに保存し、Guestには含めません。
+type Guest struct {
+	ID       string
+	Nickname string
+}
+
+// NewGuest はGuestのポインタを生成します。
+// ニックネームの前後の空白は取り除かれ、空もしくは長すぎる場合はErrInvalidNicknameを返します。
+func NewGuest(nickname string) (*Guest, error) {
+	nickname = strings.TrimSpace(nickname)
+	if nickname == "" || MaxGuestNicknameLength < utf8.RuneCountInString(nickname) {
+		return nil, fmt.Errorf("nickname = %s: %w", nickname, ErrInvalidNickname)
+	}
+	return &Guest{
+		ID:       uuid.New().String(),
+		Nickname: nickname,
+	}, nil
+}
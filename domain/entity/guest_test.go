@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewGuest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		nickname     string
+		wantNickname string
+		wantErr      error
+	}{
+		{
+			name:         "前後の空白を取り除いたニックネームのゲストを生成できる",
+			nickname:     "  nickname ",
+			wantNickname: "nickname",
+			wantErr:      nil,
+		},
+		{
+			name:         "30文字のニックネームは指定できる",
+			nickname:     "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほ",
+			wantNickname: "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほ",
+			wantErr:      nil,
+		},
+		{
+			name:     "空白のみのニックネームはErrInvalidNickname",
+			nickname: "   ",
+			wantErr:  ErrInvalidNickname,
+		},
+		{
+			name:     "31文字以上のニックネームはErrInvalidNickname",
+			nickname: "あいうえおかきくけこさしすせそたちつてとなにぬねのはひふへほま",
+			wantErr:  ErrInvalidNickname,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewGuest(tt.nickname)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewGuest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.ID == "" {
+				t.Errorf("NewGuest() ID is empty")
+			}
+			if got.Nickname != tt.wantNickname {
+				t.Errorf("NewGuest() Nickname = %s, want %s", got.Nickname, tt.wantNickname)
+			}
+		})
+	}
+}
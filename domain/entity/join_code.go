@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// JoinCodeLength はセッションに参加するためのコードの文字数です。
+const JoinCodeLength = 6
+
+// joinCodeChars は参加用のコードに使う文字です。
+// 口頭で伝えても間違えないように、0とO、1とIとLのような見間違えやすい文字は除いています。
+const joinCodeChars = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// NewJoinCode はランダムな参加用のコードを生成します。
+// 他のセッションのコードと重複する可能性があるので、重複した場合は生成し直してください。
+func NewJoinCode() string {
+	max := big.NewInt(int64(len(joinCodeChars)))
+	code := make([]byte, JoinCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// crypto/randが失敗するのはOSの乱数生成器が使えない場合のみで、復旧できないのでpanicする
+			panic(fmt.Sprintf("generate join code: %v", err))
+		}
+		code[i] = joinCodeChars[n.Int64()]
+	}
+	return string(code)
+}
+
+// NormalizeJoinCode はユーザが入力した参加用のコードを大文字に揃えて、形式が正しいか検証します。
+func NormalizeJoinCode(code string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	if len(normalized) != JoinCodeLength {
+		return "", fmt.Errorf("join code = %s: %w", code, ErrInvalidJoinCode)
+	}
+	for _, c := range normalized {
+		if !strings.ContainsRune(joinCodeChars, c) {
+			return "", fmt.Errorf("join code = %s: %w", code, ErrInvalidJoinCode)
+		}
+	}
+	return normalized, nil
+}
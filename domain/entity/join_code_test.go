@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewJoinCode(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		code := NewJoinCode()
+		if _, err := NormalizeJoinCode(code); err != nil {
+			t.Fatalf("NewJoinCode() = %s is invalid: %v", code, err)
+		}
+	}
+}
+
+func TestNormalizeJoinCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		code    string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "正しい形式のコードはそのまま返す",
+			code:    "ABC234",
+			want:    "ABC234",
+			wantErr: nil,
+		},
+		{
+			name:    "小文字や前後の空白は正規化される",
+			code:    " abc234 ",
+			want:    "ABC234",
+			wantErr: nil,
+		},
+		{
+			name:    "文字数が違うとErrInvalidJoinCode",
+			code:    "ABC23",
+			want:    "",
+			wantErr: ErrInvalidJoinCode,
+		},
+		{
+			name:    "見間違えやすい文字が含まれているとErrInvalidJoinCode",
+			code:    "ABC0O1",
+			want:    "",
+			wantErr: ErrInvalidJoinCode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeJoinCode(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizeJoinCode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeJoinCode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
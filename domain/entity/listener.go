@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"strings"
+)
+
+// Listener はセッションにWebSocketで接続しているユーザを表します。
+// 同じユーザが複数のタブから接続していても、一人のListenerとして扱います。
+type Listener struct {
+	ID          string `json:"id"` // ログインしているユーザの場合はユーザID、ゲストの場合はゲストID
+	DisplayName string `json:"display_name"`
+	IsGuest     bool   `json:"is_guest"`
+}
+
+// NewUserListener はログインしているユーザのListenerのポインタを生成します。
+func NewUserListener(user *User) *Listener {
+	return &Listener{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+		IsGuest:     false,
+	}
+}
+
+// NewGuestListener はログインしていないユーザのListenerのポインタを生成します。
+// ゲストIDはキューの曲を追加したユーザや追放する参加者と同じ公開されるIDで、ゲストを識別する秘密の値はCookieのトークンのみです。
+// ニックネームが空の場合は、ゲストIDから表示名を生成します。
+func NewGuestListener(guestID string, nickname string) *Listener {
+	if nickname == "" {
+		short := strings.ReplaceAll(guestID, "-", "")
+		if len(short) > 4 {
+			short = short[:4]
+		}
+		nickname = "Guest " + strings.ToUpper(short)
+	}
+	return &Listener{
+		ID:          guestID,
+		DisplayName: nickname,
+		IsGuest:     true,
+	}
+}
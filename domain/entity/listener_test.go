@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+)
+
+func TestNewGuestListener(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		guestID  string
+		nickname string
+		want     *Listener
+	}{
+		{
+			name:     "ゲストIDをそのままIDに使い、ニックネームを表示名にする",
+			guestID:  "3f2a9c0d-1e4b-5a6c-8d7e-9f0a1b2c3d4e",
+			nickname: "nickname",
+			want:     &Listener{ID: "3f2a9c0d-1e4b-5a6c-8d7e-9f0a1b2c3d4e", DisplayName: "nickname", IsGuest: true},
+		},
+		{
+			name:     "ニックネームが空の場合はゲストIDから表示名を生成する",
+			guestID:  "3f2a9c0d-1e4b-5a6c-8d7e-9f0a1b2c3d4e",
+			nickname: "",
+			want:     &Listener{ID: "3f2a9c0d-1e4b-5a6c-8d7e-9f0a1b2c3d4e", DisplayName: "Guest 3F2A", IsGuest: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewGuestListener(tt.guestID, tt.nickname); *got != *tt.want {
+				t.Errorf("NewGuestListener() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "fmt"
+
+// Playlist はSpotifyのプレイリストを表します。
+type Playlist struct {
+	ID  string
+	URI string
+	URL string
+}
+
+// PlaylistExportTarget はセッションの曲をプレイリストに書き出すときに、どの曲を対象にするかを表します。
+type PlaylistExportTarget string
+
+const (
+	// PlaylistExportPlayed は再生済みの曲(headより前の曲)のみを書き出します。
+	PlaylistExportPlayed PlaylistExportTarget = "played"
+	// PlaylistExportAll はキューの全ての曲を書き出します。
+	PlaylistExportAll PlaylistExportTarget = "all"
+)
+
+var playlistExportTargets = []PlaylistExportTarget{PlaylistExportPlayed, PlaylistExportAll}
+
+// NewPlaylistExportTarget はstringから対応するPlaylistExportTargetを生成します。
+// 空文字列の場合はPlaylistExportPlayedになります。
+func NewPlaylistExportTarget(target string) (PlaylistExportTarget, error) {
+	if target == "" {
+		return PlaylistExportPlayed, nil
+	}
+	for _, t := range playlistExportTargets {
+		if t.String() == target {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("playlistExportTarget = %s:%w", target, ErrInvalidPlaylistExportTarget)
+}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (t PlaylistExportTarget) String() string {
+	return string(t)
+}
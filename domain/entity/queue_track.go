@@ -1,14 +1,139 @@
 package entity
 
+import (
+	"fmt"
+	"time"
+)
+
+// GuestDisplayName はログインしていないユーザが曲を追加したときに表示する名前です。
+const GuestDisplayName = "ゲスト"
+
+// AutoplayDisplayName はおすすめの曲としてサーバが自動で追加した曲に表示する名前です。
+const AutoplayDisplayName = "おすすめ"
+
+// FallbackPlaylistDisplayName はハウスプレイリストからサーバが自動で追加した曲に表示する名前です。
+const FallbackPlaylistDisplayName = "ハウスプレイリスト"
+
+// EnqueuePosition はキューに曲を追加する位置を表します。
+type EnqueuePosition string
+
+const (
+	// EnqueuePositionLast はキューの最後に曲を追加します。
+	EnqueuePositionLast EnqueuePosition = "last"
+	// EnqueuePositionNext は現在の曲(head)の直後に曲を追加します。
+	EnqueuePositionNext EnqueuePosition = "next"
+)
+
+var enqueuePositions = []EnqueuePosition{EnqueuePositionLast, EnqueuePositionNext}
+
+// NewEnqueuePosition はstringから対応するEnqueuePositionを生成します。
+// 空文字列の場合はEnqueuePositionLastになります。
+func NewEnqueuePosition(position string) (EnqueuePosition, error) {
+	if position == "" {
+		return EnqueuePositionLast, nil
+	}
+	for _, ep := range enqueuePositions {
+		if ep.String() == position {
+			return ep, nil
+		}
+	}
+	return "", fmt.Errorf("enqueuePosition = %s:%w", position, ErrInvalidEnqueuePosition)
+}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (ep EnqueuePosition) String() string {
+	return string(ep)
+}
+
 // QueueTrackToStore はsessionに属するqueue内に曲を挿入する際に使用します
 type QueueTrackToStore struct {
-	URI       string
-	SessionID string
+	URI         string
+	SessionID   string
+	AddedBy     string // 曲を追加したユーザのID(ログインしていないユーザの場合は空文字列)
+	AddedByName string // 曲を追加した時点でのユーザの表示名
+	AddedAt     time.Time
 }
 
 // QueueTrack はsessionに属するqueue内の曲を表します。
 type QueueTrack struct {
-	Index     int
-	URI       string
-	SessionID string
+	Index       int
+	URI         string
+	SessionID   string
+	AddedBy     string // 曲を追加したユーザのID(ログインしていないユーザの場合は空文字列)
+	AddedByName string // 曲を追加した時点でのユーザの表示名
+	AddedAt     time.Time
+	Votes       int                 // 曲に投票したユーザの数
+	StartedAt   time.Time           // 再生を開始した日時(まだ再生していない場合はゼロ値)
+	EndedAt     time.Time           // 再生が終了した日時(まだ終了していない場合はゼロ値)
+	EndReason   QueueTrackEndReason // 再生が終了した理由(まだ終了していない場合は空文字列)
+}
+
+// QueueTrackEndReason はキューの曲の再生が終了した理由を表します。
+type QueueTrackEndReason string
+
+const (
+	// QueueTrackFinished は曲が最後まで再生されたことを表します。
+	QueueTrackFinished QueueTrackEndReason = "FINISHED"
+	// QueueTrackSkipped は曲が途中でスキップされたことを表します。
+	QueueTrackSkipped QueueTrackEndReason = "SKIPPED"
+	// QueueTrackInterrupted はSpotifyとの同期が取れなくなったりセッションがアーカイブされたりして、曲の再生が中断されたことを表します。
+	QueueTrackInterrupted QueueTrackEndReason = "INTERRUPTED"
+)
+
+// String はfmt.Stringerを満たすメソッドです。
+func (r QueueTrackEndReason) String() string {
+	return string(r)
+}
+
+// start は曲の再生を開始した日時を記録します。
+// 再生の終了が記録されている曲を再度再生する場合は、終了の記録を消して開始日時を記録し直します。
+func (qt *QueueTrack) start(now time.Time) bool {
+	if !qt.StartedAt.IsZero() && qt.EndedAt.IsZero() {
+		return false
+	}
+	qt.StartedAt = now
+	qt.EndedAt = time.Time{}
+	qt.EndReason = ""
+	return true
+}
+
+// end は曲の再生が終了した日時と理由を記録します。既に終了が記録されている場合は何もしません。
+func (qt *QueueTrack) end(now time.Time, reason QueueTrackEndReason) bool {
+	if !qt.EndedAt.IsZero() {
+		return false
+	}
+	qt.EndedAt = now
+	qt.EndReason = reason
+	return true
+}
+
+// contributorKey は曲を追加したユーザを識別するためのキーを返します。
+// ログインしていないユーザが追加した曲は表示名ごとにまとめて扱います。
+func (qt *QueueTrack) contributorKey() string {
+	if qt.AddedBy != "" {
+		return qt.AddedBy
+	}
+	return "name:" + qt.AddedByName
+}
+
+// HistoryTrackStatus はセッションの履歴における曲の再生状況を表します。
+type HistoryTrackStatus string
+
+const (
+	// HistoryTrackPlayed は再生済みの曲(headより前の曲)を表します。
+	HistoryTrackPlayed HistoryTrackStatus = "played"
+	// HistoryTrackPending はまだ再生し終わっていない曲(head以降の曲)を表します。
+	HistoryTrackPending HistoryTrackStatus = "pending"
+)
+
+// String はfmt.Stringerを満たすメソッドです。
+func (st HistoryTrackStatus) String() string {
+	return string(st)
+}
+
+// HistoryTrack はセッションの履歴の1曲を表します。
+type HistoryTrack struct {
+	QueueTrack *QueueTrack
+	Track      *Track // Spotifyから曲の情報を取得できなかった場合はnil
+	Status     HistoryTrackStatus
 }
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/camphor-/relaym-server/log"
@@ -13,35 +14,65 @@ import (
 type Session struct {
 	ID                     string
 	Name                   string
+	Description            string // セッションの説明。設定されていない場合は空文字列
 	CreatorID              string
 	DeviceID               string
 	StateType              StateType
 	QueueHead              int
 	QueueTracks            []*QueueTrack
-	ExpiredAt              time.Time
+	ExpiredAt              time.Time // ExpirationPolicyがFIXEDの場合にアーカイブされる日時
+	ExpirationPolicy       ExpirationPolicy
 	AllowToControlByOthers bool
 	ProgressWhenPaused     time.Duration
+	NeedsSpotifyQueueReset bool // Spotifyのキューに追加済みの曲が変更されて、次の曲に進むときか再開するときに積み直す必要があるかどうか
+	QueueOrderType         QueueOrderType
+	SkipVoteThreshold      SkipVoteThreshold
+	Autoplay               bool      // キューの曲が無くなったときにおすすめの曲を自動で追加するかどうか
+	FallbackPlaylistURI    string    // キューの曲が無くなったときに曲を追加するハウスプレイリストのURI。設定されていない場合は空文字列
+	LastActivityAt         time.Time // 最後に曲の追加や再生などの操作が行われた日時
+	JoinCode               string    // セッションに参加するための短いコード。アーカイブされている場合は空文字列
 }
 
+const (
+	// AutoplaySeedTrackCount はおすすめの曲を取得するときにシードにする再生済みの曲数です。Spotify APIのシードの上限は5曲です。
+	AutoplaySeedTrackCount = 5
+	// AutoplayTrackCount はキューの曲が無くなったときに一度に自動で追加する曲数です。
+	AutoplayTrackCount = 5
+	// FallbackPlaylistTrackLimit はハウスプレイリストから次に追加する曲を選ぶときに取得する曲数の上限です。
+	FallbackPlaylistTrackLimit = 500
+)
+
 type SessionWithUser struct {
 	*Session
 	Creator *User
 }
 
 // NewSession はSessionのポインタを生成する関数です。
-func NewSession(name string, creatorID string, allowToControlByOthers bool) (*Session, error) {
-	return &Session{
+// settingsでnilのフィールドはデフォルトの設定になります。
+func NewSession(creatorID string, settings SessionSettings, expirationPolicy ExpirationPolicy) (*Session, error) {
+	now := time.Now().UTC()
+	session := &Session{
 		ID:                     uuid.New().String(),
-		Name:                   name,
+		Name:                   "",
 		CreatorID:              creatorID,
 		DeviceID:               "",
 		StateType:              Stop,
 		QueueHead:              0,
 		QueueTracks:            nil,
-		ExpiredAt:              time.Now().AddDate(0, 0, 3).UTC(),
-		AllowToControlByOthers: allowToControlByOthers,
+		ExpiredAt:              expirationPolicy.ExpiredAt(now),
+		ExpirationPolicy:       expirationPolicy,
+		AllowToControlByOthers: false,
 		ProgressWhenPaused:     0 * time.Second,
-	}, nil
+		QueueOrderType:         QueueOrderInsertion,
+		SkipVoteThreshold:      DefaultSkipVoteThreshold,
+		Autoplay:               false,
+		FallbackPlaylistURI:    "",
+		LastActivityAt:         now,
+		JoinCode:               NewJoinCode(),
+	}
+	// キューに曲が無いので並び替えは起きない
+	session.UpdateSettings(settings)
+	return session, nil
 }
 
 // NewSessionWithUser はSession(ポインタ)からSessionWithUser(ポインタ)を生成します
@@ -52,10 +83,9 @@ func NewSessionWithUser(session *Session, creator *User) *SessionWithUser {
 	}
 }
 
-// UpdateExpiredAt はexpired_atを現在の時刻から3日後に設定します
+// UpdateExpiredAt はexpired_atを現在の時刻からExpirationPolicyで指定された時間後に設定します
 func (s *Session) UpdateExpiredAt() {
-	threeDaysAfter := time.Now().AddDate(0, 0, 3).UTC()
-	s.ExpiredAt = threeDaysAfter
+	s.ExpiredAt = s.ExpirationPolicy.ExpiredAt(time.Now())
 }
 
 // MoveToPlay はセッションのStateTypeをPlayに状態遷移します。
@@ -66,6 +96,8 @@ func (s *Session) MoveToPlay() error {
 
 	s.StateType = Play
 	s.SetProgressWhenPaused(0 * time.Second)
+	// 再生を始めるときにSpotifyのキューを積み直しているので、積み直す必要はなくなる
+	s.NeedsSpotifyQueueReset = false
 	return nil
 }
 
@@ -85,11 +117,19 @@ func (s *Session) MoveToStop() {
 }
 
 // MoveToArchived はセッションのStateTypeをArchivedに状態遷移します。
+// 参加用のコードは他のセッションで使えるように解放します。
 func (s *Session) MoveToArchived() {
 	s.StateType = Archived
+	s.JoinCode = ""
 	s.SetProgressWhenPaused(0 * time.Second)
 }
 
+// RegenerateJoinCode は参加用のコードを新しく生成し直します。
+// コードが他のセッションと重複したときや、アーカイブが解除されたときに使います。
+func (s *Session) RegenerateJoinCode() {
+	s.JoinCode = NewJoinCode()
+}
+
 // IsCreator は指定されたユーザがセッションの作成者かどうか返します。
 func (s *Session) IsCreator(userID string) bool {
 	return s.CreatorID == userID
@@ -107,6 +147,34 @@ func (s *Session) GoNextTrack() error {
 	return nil
 }
 
+// StartHeadTrack は現在の曲(head)の再生を開始した日時を記録したQueueTrackを返します。
+// セッションのキュー自体は変更しないので、返り値をリポジトリに保存してください。
+// 一時停止からの再開など、既に再生中として記録されている場合はnilを返します。
+func (s *Session) StartHeadTrack(now time.Time) *QueueTrack {
+	if s.QueueHead < 0 || len(s.QueueTracks) <= s.QueueHead {
+		return nil
+	}
+	qt := *s.QueueTracks[s.QueueHead]
+	if !qt.start(now) {
+		return nil
+	}
+	return &qt
+}
+
+// EndHeadTrack は現在の曲(head)の再生が終了した日時と理由を記録したQueueTrackを返します。
+// StartHeadTrackと同様にセッションのキュー自体は変更しません。
+// GoNextTrackでheadを進める前に呼ぶ必要があります。既に終了が記録されている場合はnilを返します。
+func (s *Session) EndHeadTrack(now time.Time, reason QueueTrackEndReason) *QueueTrack {
+	if s.QueueHead < 0 || len(s.QueueTracks) <= s.QueueHead {
+		return nil
+	}
+	qt := *s.QueueTracks[s.QueueHead]
+	if !qt.end(now, reason) {
+		return nil
+	}
+	return &qt
+}
+
 // IsPlayingCorrectTrack は現在の再生状況がセッションの状況と一致しているかチェックします。
 func (s *Session) IsPlayingCorrectTrack(playingInfo *CurrentPlayingInfo) error {
 	logger := log.New()
@@ -150,6 +218,114 @@ func (s *Session) ShouldCallEnqueueAPINow() bool {
 	return ((len(s.QueueTracks) - s.QueueHead) < 3) && (s.StateType == Play || s.StateType == Pause)
 }
 
+// ShouldAutoplay はheadの曲がキューの最後の曲で、おすすめの曲を自動で追加する必要があるかどうか返します。
+func (s *Session) ShouldAutoplay() bool {
+	return s.Autoplay && s.isHeadLastTrack()
+}
+
+// ShouldPullFromFallbackPlaylist はheadの曲がキューの最後の曲で、ハウスプレイリストから曲を追加する必要があるかどうか返します。
+func (s *Session) ShouldPullFromFallbackPlaylist() bool {
+	return s.FallbackPlaylistURI != "" && s.isHeadLastTrack()
+}
+
+// isHeadLastTrack はheadの曲がキューの最後の曲かどうか返します。
+func (s *Session) isHeadLastTrack() bool {
+	return !s.isEmptyQueue() && len(s.QueueTracks) <= s.QueueHead+1
+}
+
+// NextFallbackTrackURI はハウスプレイリストの曲のURIの中から、次にキューに追加する曲のURIを返します。
+// まだセッションのキューに入ったことがない曲のうちプレイリストの先頭に近い曲を選び、
+// 全ての曲がキューに入ったことがある場合は最後にキューに入ったのが最も古い曲を選びます。
+func (s *Session) NextFallbackTrackURI(playlistTrackURIs []string) string {
+	lastIndexes := make(map[string]int, len(s.QueueTracks))
+	for i, qt := range s.QueueTracks {
+		lastIndexes[qt.URI] = i
+	}
+
+	next := ""
+	nextLastIndex := len(s.QueueTracks)
+	for _, uri := range playlistTrackURIs {
+		lastIndex, ok := lastIndexes[uri]
+		if !ok {
+			return uri
+		}
+		if lastIndex < nextLastIndex {
+			next = uri
+			nextLastIndex = lastIndex
+		}
+	}
+	return next
+}
+
+// AutoplaySeedTrackURIs はおすすめの曲を取得するときのシードとして、headまでに再生した曲のうち最近の曲から最大AutoplaySeedTrackCount曲分のURIを返します。
+func (s *Session) AutoplaySeedTrackURIs() []string {
+	end := s.QueueHead + 1
+	if len(s.QueueTracks) < end {
+		end = len(s.QueueTracks)
+	}
+	start := end - AutoplaySeedTrackCount
+	if start < 0 {
+		start = 0
+	}
+
+	uris := make([]string, 0, end-start)
+	for _, qt := range s.QueueTracks[start:end] {
+		uris = append(uris, qt.URI)
+	}
+	return uris
+}
+
+// ExportTrackURIs はプレイリストに書き出す曲のURIをキューの順番で返します。
+// PlaylistExportPlayedの場合は再生済みの曲(headより前の曲)、PlaylistExportAllの場合はキューの全ての曲を返します。
+func (s *Session) ExportTrackURIs(target PlaylistExportTarget) []string {
+	queueTracks := s.QueueTracks
+	if target == PlaylistExportPlayed && s.QueueHead < len(queueTracks) {
+		queueTracks = queueTracks[:s.QueueHead]
+	}
+
+	uris := make([]string, len(queueTracks))
+	for i, qt := range queueTracks {
+		uris[i] = qt.URI
+	}
+	return uris
+}
+
+// History はキューの全ての曲について、Spotifyから取得した曲の情報とheadに対する再生状況をまとめて返します。
+// tracksはQueueTracksと同じ順番で並んでいる必要があります。
+func (s *Session) History(tracks []*Track) []*HistoryTrack {
+	history := make([]*HistoryTrack, len(s.QueueTracks))
+	for i, qt := range s.QueueTracks {
+		status := HistoryTrackPending
+		if i < s.QueueHead {
+			status = HistoryTrackPlayed
+		}
+
+		var track *Track
+		if i < len(tracks) {
+			track = tracks[i]
+		}
+
+		history[i] = &HistoryTrack{
+			QueueTrack: qt,
+			Track:      track,
+			Status:     status,
+		}
+	}
+	return history
+}
+
+// CanVoteToSkip は現在の曲(head)に対してスキップ投票できるかどうか返します。
+// 再生中もしくは一時停止中の場合のみ投票できます。
+func (s *Session) CanVoteToSkip() error {
+	if s.StateType != Play && s.StateType != Pause {
+		return fmt.Errorf("vote to skip in state %s: %w", s.StateType, ErrChangeSessionStateNotPermit)
+	}
+	if len(s.QueueTracks) <= s.QueueHead {
+		return fmt.Errorf("vote to skip: %w", ErrQueueTrackNotFound)
+	}
+	return nil
+}
+
 // IsResume は次のStateTypeへの移行がポーズからの再開かどうかを返します。
 func (s *Session) IsResume(nextState StateType) bool {
 	return s.StateType == Pause && nextState == Play
@@ -161,15 +337,234 @@ func (s *Session) TrackURIsShouldBeAddedWhenStopToPlay() ([]string, error) {
 		return []string{}, fmt.Errorf("can not to move to play: %w", err)
 	}
 
+	return s.trackURIsFromHead(), nil
+}
+
+// TrackURIsToSyncWithSpotify はSpotifyのキューを積み直すときに使う、headの曲とそれに続いてSpotifyのキューに追加されているべき曲のURIを返します。
+func (s *Session) TrackURIsToSyncWithSpotify() []string {
+	return s.trackURIsFromHead()
+}
+
+// trackURIsFromHead はheadの曲から最大3曲分のURIを返します。
+func (s *Session) trackURIsFromHead() []string {
 	var uris []string
 	for i := 0; i < 3; i++ {
 		trackIndex := i + s.QueueHead
-		uris = append(uris, s.QueueTracks[trackIndex].URI)
-		if (len(s.QueueTracks) - s.QueueHead) == i+1 {
+		if len(s.QueueTracks) <= trackIndex {
 			break
 		}
+		uris = append(uris, s.QueueTracks[trackIndex].URI)
+	}
+	return uris
+}
+
+// IsEnqueuedToSpotify は指定されたindexの曲が既にSpotifyのキューに追加されているかどうか返します。
+// 再生中もしくは一時停止中は、headの2曲先までがSpotifyのキューに追加されています。
+func (s *Session) IsEnqueuedToSpotify(index int) bool {
+	if s.StateType != Play && s.StateType != Pause {
+		return false
+	}
+	return s.QueueHead < index && index < s.QueueHead+3
+}
+
+// RemoveQueueTrack は指定されたindexの曲をキューから削除します。
+// 再生済みの曲とheadの曲は削除できません。
+func (s *Session) RemoveQueueTrack(index int) error {
+	if err := s.canEditQueueTrack(index); err != nil {
+		return fmt.Errorf("remove queue track: %w", err)
+	}
+
+	s.QueueTracks = append(s.QueueTracks[:index], s.QueueTracks[index+1:]...)
+	s.reindexQueueTracks()
+	return nil
+}
+
+// AppendQueueTrack はキューの最後に曲を追加します。
+func (s *Session) AppendQueueTrack(qt *QueueTrackToStore) {
+	s.InsertQueueTrack(len(s.QueueTracks), qt)
+}
+
+// InsertQueueTrack は指定されたindexに曲を挿入し、それ以降の曲のindexを1つずつ後ろにずらします。
+func (s *Session) InsertQueueTrack(index int, qt *QueueTrackToStore) {
+	queueTrack := &QueueTrack{
+		Index:       index,
+		URI:         qt.URI,
+		SessionID:   s.ID,
+		AddedBy:     qt.AddedBy,
+		AddedByName: qt.AddedByName,
+		AddedAt:     qt.AddedAt,
+	}
+	s.QueueTracks = append(s.QueueTracks[:index:index], append([]*QueueTrack{queueTrack}, s.QueueTracks[index:]...)...)
+	s.reindexQueueTracks()
+}
+
+// EnqueueIndex は指定された位置に曲を追加するときの、追加する曲のindexを返します。
+// EnqueuePositionNextの場合は現在の曲(head)の直後になりますが、headより後ろに曲が無い場合はキューの最後と同じになります。
+func (s *Session) EnqueueIndex(position EnqueuePosition) int {
+	if position == EnqueuePositionNext && s.QueueHead+1 < len(s.QueueTracks) {
+		return s.QueueHead + 1
+	}
+	return len(s.QueueTracks)
+}
+
+// MoveQueueTrack は指定されたindexの曲をキュー内の別の位置に移動します。
+// 移動元と移動先はどちらもheadより後ろである必要があります。
+func (s *Session) MoveQueueTrack(from, to int) error {
+	if err := s.canEditQueueTrack(from); err != nil {
+		return fmt.Errorf("move queue track from: %w", err)
+	}
+	if err := s.canEditQueueTrack(to); err != nil {
+		return fmt.Errorf("move queue track to: %w", err)
+	}
+
+	qt := s.QueueTracks[from]
+	tracks := append(s.QueueTracks[:from:from], s.QueueTracks[from+1:]...)
+	s.QueueTracks = append(tracks[:to:to], append([]*QueueTrack{qt}, tracks[to:]...)...)
+	s.reindexQueueTracks()
+	return nil
+}
+
+// ReorderQueueTracks はQueueOrderTypeに従ってまだSpotifyのキューに追加されていない曲を並び替えます。
+// 並び替えによって位置が変わった曲について、変更前のindexをkey、変更後のindexをvalueとしたmapを返します。
+func (s *Session) ReorderQueueTracks() map[int]int {
+	start := s.firstReorderableIndex()
+	if len(s.QueueTracks)-start < 2 {
+		return map[int]int{}
+	}
+
+	var reordered []*QueueTrack
+	switch s.QueueOrderType {
+	case QueueOrderFair:
+		reordered = orderByRoundRobin(s.QueueTracks[:start], s.QueueTracks[start:])
+	case QueueOrderVote:
+		reordered = orderByVotes(s.QueueTracks[start:])
+	default:
+		return map[int]int{}
+	}
+
+	moves := make(map[int]int)
+	for i, qt := range reordered {
+		if newIndex := start + i; qt.Index != newIndex {
+			moves[qt.Index] = newIndex
+		}
+		s.QueueTracks[start+i] = qt
+	}
+	s.reindexQueueTracks()
+	return moves
+}
+
+// firstReorderableIndex は自動で並び替えて良い最初の曲のindexを返します。
+// 再生中もしくは一時停止中はSpotifyのキューに追加されている曲を、それ以外の場合はheadの曲を並び替えの対象から外します。
+func (s *Session) firstReorderableIndex() int {
+	if s.StateType == Play || s.StateType == Pause {
+		return s.QueueHead + 3
+	}
+	return s.QueueHead + 1
+}
+
+// orderByRoundRobin はqueueTracksを曲を追加したユーザごとに1曲ずつ順番に並ぶように並び替えます。
+// 同じユーザが追加した曲同士は追加された順番を保ちます。
+// ユーザの順番は、fixedTracks(再生済みや並び替えない曲)に最近の曲が含まれていないユーザほど先になり、同じ場合は最初に曲を追加した順番になります。
+func orderByRoundRobin(fixedTracks, queueTracks []*QueueTrack) []*QueueTrack {
+	sorted := make([]*QueueTrack, len(queueTracks))
+	copy(sorted, queueTracks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].AddedAt.Equal(sorted[j].AddedAt) {
+			return sorted[i].AddedAt.Before(sorted[j].AddedAt)
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+
+	var contributors []string
+	tracksByContributor := make(map[string][]*QueueTrack)
+	for _, qt := range sorted {
+		key := qt.contributorKey()
+		if _, ok := tracksByContributor[key]; !ok {
+			contributors = append(contributors, key)
+		}
+		tracksByContributor[key] = append(tracksByContributor[key], qt)
+	}
+
+	lastFixedIndex := make(map[string]int)
+	for i, qt := range fixedTracks {
+		lastFixedIndex[qt.contributorKey()] = i
+	}
+	sort.SliceStable(contributors, func(i, j int) bool {
+		li, ok := lastFixedIndex[contributors[i]]
+		if !ok {
+			li = -1
+		}
+		lj, ok := lastFixedIndex[contributors[j]]
+		if !ok {
+			lj = -1
+		}
+		return li < lj
+	})
+
+	reordered := make([]*QueueTrack, 0, len(queueTracks))
+	for round := 0; len(reordered) < len(queueTracks); round++ {
+		for _, c := range contributors {
+			if round < len(tracksByContributor[c]) {
+				reordered = append(reordered, tracksByContributor[c][round])
+			}
+		}
+	}
+	return reordered
+}
+
+// orderByVotes はqueueTracksを投票数の多い順に並び替えます。
+// 投票数が同じ曲同士は追加された順番になります。
+func orderByVotes(queueTracks []*QueueTrack) []*QueueTrack {
+	sorted := make([]*QueueTrack, len(queueTracks))
+	copy(sorted, queueTracks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Votes != sorted[j].Votes {
+			return sorted[i].Votes > sorted[j].Votes
+		}
+		if !sorted[i].AddedAt.Equal(sorted[j].AddedAt) {
+			return sorted[i].AddedAt.Before(sorted[j].AddedAt)
+		}
+		return sorted[i].Index < sorted[j].Index
+	})
+	return sorted
+}
+
+// AddQueueTrackVote は指定されたindexの曲の投票数を1増やします。
+func (s *Session) AddQueueTrackVote(index int) error {
+	if err := s.canEditQueueTrack(index); err != nil {
+		return fmt.Errorf("add vote to queue track index=%d: %w", index, err)
+	}
+	s.QueueTracks[index].Votes++
+	return nil
+}
+
+// RemoveQueueTrackVote は指定されたindexの曲の投票数を1減らします。
+func (s *Session) RemoveQueueTrackVote(index int) error {
+	if err := s.canEditQueueTrack(index); err != nil {
+		return fmt.Errorf("remove vote from queue track index=%d: %w", index, err)
+	}
+	if s.QueueTracks[index].Votes > 0 {
+		s.QueueTracks[index].Votes--
+	}
+	return nil
+}
+
+// canEditQueueTrack は指定されたindexの曲を削除したり並び替えたりして良いかどうか返します。
+func (s *Session) canEditQueueTrack(index int) error {
+	if index < 0 || len(s.QueueTracks) <= index {
+		return fmt.Errorf("index=%d: %w", index, ErrQueueTrackNotFound)
+	}
+	if index <= s.QueueHead {
+		return fmt.Errorf("index=%d head=%d: %w", index, s.QueueHead, ErrQueueTrackNotEditable)
+	}
+	return nil
+}
+
+// reindexQueueTracks はキューの並び順に合わせてQueueTrackのIndexを振り直します。
+func (s *Session) reindexQueueTracks() {
+	for i, qt := range s.QueueTracks {
+		qt.Index = i
 	}
-	return uris, nil
 }
 
 // TrackURIShouldBeAddedWhenHandleTrackEnd はある一曲の再生が終わったときにSpotifyのキューに追加するTrackURIを抽出します。
@@ -242,6 +637,35 @@ func (s *Session) HeadTrack() *QueueTrack {
 	return s.QueueTracks[s.QueueHead]
 }
 
+// QueueOrderType はセッションのキューに追加された曲の並び順の決め方を表します。
+type QueueOrderType string
+
+const (
+	// QueueOrderInsertion は曲が追加された順番に再生します。
+	QueueOrderInsertion QueueOrderType = "INSERTION"
+	// QueueOrderFair は曲を追加したユーザごとに1曲ずつ順番に再生します。
+	QueueOrderFair QueueOrderType = "FAIR"
+	// QueueOrderVote は投票数の多い曲から順番に再生します。
+	QueueOrderVote QueueOrderType = "VOTE"
+)
+
+var queueOrderTypes = []QueueOrderType{QueueOrderInsertion, QueueOrderFair, QueueOrderVote}
+
+// NewQueueOrderType はstringから対応するQueueOrderTypeを生成します。
+func NewQueueOrderType(queueOrderType string) (QueueOrderType, error) {
+	for _, qot := range queueOrderTypes {
+		if qot.String() == queueOrderType {
+			return qot, nil
+		}
+	}
+	return "", fmt.Errorf("queueOrderType = %s:%w", queueOrderType, ErrInvalidQueueOrderType)
+}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (qot QueueOrderType) String() string {
+	return string(qot)
+}
+
 type StateType string
 
 const (
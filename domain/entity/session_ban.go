@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SessionBan はセッションから追放された参加者を表します。
+// 参加者はログインしているユーザのユーザID、またはゲストのゲストIDで識別します。
+type SessionBan struct {
+	SessionID     string
+	ParticipantID string
+}
+
+// NewSessionBan はセッションの作成者が参加者を追放するSessionBanのポインタを生成します。
+// 作成者自身を追放することはできません。
+func (s *Session) NewSessionBan(participantID string) (*SessionBan, error) {
+	if participantID == "" {
+		return nil, fmt.Errorf("participant id is empty: %w", ErrInvalidParticipantID)
+	}
+	if s.IsCreator(participantID) {
+		return nil, fmt.Errorf("participant id=%s: %w", participantID, ErrCreatorCannotBeBanned)
+	}
+	return &SessionBan{
+		SessionID:     s.ID,
+		ParticipantID: participantID,
+	}, nil
+}
+
+// PendingQueueTrackIndexesAddedBy は指定した参加者が追加したまだ再生されていない曲のindexを降順で返します。
+// 後ろの曲から削除すれば、削除していない曲のindexがずれないようにするためです。
+func (s *Session) PendingQueueTrackIndexesAddedBy(participantID string) []int {
+	indexes := make([]int, 0)
+	for i, qt := range s.QueueTracks {
+		if i <= s.QueueHead || qt.AddedBy != participantID {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	return indexes
+}
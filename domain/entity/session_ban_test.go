@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSession_NewSessionBan(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{ID: "sessionID", CreatorID: "creatorID"}
+
+	tests := []struct {
+		name          string
+		participantID string
+		want          *SessionBan
+		wantErr       error
+	}{
+		{
+			name:          "作成者以外の参加者を追放できる",
+			participantID: "guestID",
+			want: &SessionBan{
+				SessionID:     "sessionID",
+				ParticipantID: "guestID",
+			},
+			wantErr: nil,
+		},
+		{
+			name:          "IDが空の参加者は追放できない",
+			participantID: "",
+			want:          nil,
+			wantErr:       ErrInvalidParticipantID,
+		},
+		{
+			name:          "作成者は追放できない",
+			participantID: "creatorID",
+			want:          nil,
+			wantErr:       ErrCreatorCannotBeBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := session.NewSessionBan(tt.participantID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSessionBan() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("NewSessionBan() diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestSession_PendingQueueTrackIndexesAddedBy(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{
+		QueueHead: 1,
+		QueueTracks: []*QueueTrack{
+			{Index: 0, AddedBy: "bannedID"},
+			{Index: 1, AddedBy: "bannedID"},
+			{Index: 2, AddedBy: "bannedID"},
+			{Index: 3, AddedBy: "otherID"},
+			{Index: 4, AddedBy: "bannedID"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		participantID string
+		want          []int
+	}{
+		{
+			name:          "まだ再生されていない曲のindexのみを降順で返す",
+			participantID: "bannedID",
+			want:          []int{4, 2},
+		},
+		{
+			name:          "曲を追加していない参加者の場合は空",
+			participantID: "unknownID",
+			want:          []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := session.PendingQueueTrackIndexesAddedBy(tt.participantID); !cmp.Equal(got, tt.want) {
+				t.Errorf("PendingQueueTrackIndexesAddedBy() diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionCloneQueue はセッションを複製するときに、元のセッションのキューをどこまで引き継ぐかを表します。
+type SessionCloneQueue string
+
+const (
+	// SessionCloneQueueNone はキューを引き継ぎません。
+	SessionCloneQueueNone SessionCloneQueue = "none"
+	// SessionCloneQueueAll はキューの全ての曲を引き継ぎます。
+	SessionCloneQueueAll SessionCloneQueue = "all"
+	// SessionCloneQueueUnplayed はまだ再生していない曲(head以降の曲)のみを引き継ぎます。
+	SessionCloneQueueUnplayed SessionCloneQueue = "unplayed"
+)
+
+var sessionCloneQueues = []SessionCloneQueue{SessionCloneQueueNone, SessionCloneQueueAll, SessionCloneQueueUnplayed}
+
+// NewSessionCloneQueue はstringから対応するSessionCloneQueueを生成します。
+// 空文字列の場合はSessionCloneQueueNoneになります。
+func NewSessionCloneQueue(queue string) (SessionCloneQueue, error) {
+	if queue == "" {
+		return SessionCloneQueueNone, nil
+	}
+	for _, q := range sessionCloneQueues {
+		if q.String() == queue {
+			return q, nil
+		}
+	}
+	return "", fmt.Errorf("sessionCloneQueue = %s:%w", queue, ErrInvalidSessionCloneQueue)
+}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (q SessionCloneQueue) String() string {
+	return string(q)
+}
+
+// SessionCloneOption はセッションを複製するときに、元のセッションから何を引き継ぐかを表します。
+type SessionCloneOption struct {
+	Name                   string // 空文字列の場合は元のセッション名を引き継ぐ
+	AllowToControlByOthers *bool  // nilの場合は元のセッションの設定を引き継ぐ
+	Queue                  SessionCloneQueue
+}
+
+// Clone はセッションを複製して、creatorIDのユーザが作成した新しいセッションを生成します。
+// 引き継ぐ曲は元の曲を追加したユーザの情報を保ったまま、addedAtに追加されたものとして返すので、新しいセッションと合わせて保存してください。
+// 名前と他人による操作の許可以外の設定は引き継がずに、セッション作成時のデフォルト値になります。
+func (s *Session) Clone(creatorID string, opt SessionCloneOption, addedAt time.Time) (*Session, []*QueueTrackToStore, error) {
+	name := s.Name
+	if opt.Name != "" {
+		name = opt.Name
+	}
+	allowToControlByOthers := s.AllowToControlByOthers
+	if opt.AllowToControlByOthers != nil {
+		allowToControlByOthers = *opt.AllowToControlByOthers
+	}
+
+	cloned, err := NewSession(creatorID, SessionSettings{Name: &name, AllowToControlByOthers: &allowToControlByOthers}, DefaultExpirationPolicy)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NewSession sessionName=%s: %w", name, err)
+	}
+
+	var queueTracks []*QueueTrack
+	switch opt.Queue {
+	case SessionCloneQueueAll:
+		queueTracks = s.QueueTracks
+	case SessionCloneQueueUnplayed:
+		if s.QueueHead < len(s.QueueTracks) {
+			queueTracks = s.QueueTracks[s.QueueHead:]
+		}
+	}
+
+	queueTracksToStore := make([]*QueueTrackToStore, len(queueTracks))
+	for i, qt := range queueTracks {
+		queueTracksToStore[i] = &QueueTrackToStore{
+			URI:         qt.URI,
+			SessionID:   cloned.ID,
+			AddedBy:     qt.AddedBy,
+			AddedByName: qt.AddedByName,
+			AddedAt:     addedAt,
+		}
+		cloned.AppendQueueTrack(queueTracksToStore[i])
+	}
+	return cloned, queueTracksToStore, nil
+}
@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSessionCloneQueue(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		queue   string
+		want    SessionCloneQueue
+		wantErr error
+	}{
+		{
+			name:    "空文字列だとnone",
+			queue:   "",
+			want:    SessionCloneQueueNone,
+			wantErr: nil,
+		},
+		{
+			name:    "unplayedを指定できる",
+			queue:   "unplayed",
+			want:    SessionCloneQueueUnplayed,
+			wantErr: nil,
+		},
+		{
+			name:    "不正な値だとErrInvalidSessionCloneQueue",
+			queue:   "invalid",
+			want:    "",
+			wantErr: ErrInvalidSessionCloneQueue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSessionCloneQueue(tt.queue)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSessionCloneQueue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewSessionCloneQueue() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_Clone(t *testing.T) {
+	t.Parallel()
+
+	addedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	disallow := false
+	newSession := func() *Session {
+		return &Session{
+			ID:                     "sessionID",
+			Name:                   "sessionName",
+			CreatorID:              "creatorID",
+			StateType:              Archived,
+			QueueHead:              1,
+			AllowToControlByOthers: true,
+			QueueOrderType:         QueueOrderVote,
+			Autoplay:               true,
+			QueueTracks: []*QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID", AddedBy: "userID", AddedByName: "userDisplayName"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID", AddedBy: "", AddedByName: GuestDisplayName, Votes: 2},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                       string
+		opt                        SessionCloneOption
+		wantName                   string
+		wantAllowToControlByOthers bool
+		wantURIs                   []string
+	}{
+		{
+			name:                       "何も指定しないと名前と他人による操作の許可を引き継ぎ、キューは引き継がない",
+			opt:                        SessionCloneOption{Queue: SessionCloneQueueNone},
+			wantName:                   "sessionName",
+			wantAllowToControlByOthers: true,
+			wantURIs:                   []string{},
+		},
+		{
+			name:                       "名前と他人による操作の許可を指定すると上書きされる",
+			opt:                        SessionCloneOption{Name: "newSessionName", AllowToControlByOthers: &disallow, Queue: SessionCloneQueueNone},
+			wantName:                   "newSessionName",
+			wantAllowToControlByOthers: false,
+			wantURIs:                   []string{},
+		},
+		{
+			name:                       "allを指定するとキューの全ての曲を引き継ぐ",
+			opt:                        SessionCloneOption{Queue: SessionCloneQueueAll},
+			wantName:                   "sessionName",
+			wantAllowToControlByOthers: true,
+			wantURIs:                   []string{"spotify:track:track_uri1", "spotify:track:track_uri2"},
+		},
+		{
+			name:                       "unplayedを指定するとhead以降の曲のみを引き継ぐ",
+			opt:                        SessionCloneOption{Queue: SessionCloneQueueUnplayed},
+			wantName:                   "sessionName",
+			wantAllowToControlByOthers: true,
+			wantURIs:                   []string{"spotify:track:track_uri2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSession()
+			got, gotQueueTracks, err := s.Clone("newCreatorID", tt.opt, addedAt)
+			if err != nil {
+				t.Fatalf("Clone() error = %v", err)
+			}
+			if got.ID == s.ID || got.CreatorID != "newCreatorID" || got.StateType != Stop || got.QueueHead != 0 {
+				t.Errorf("Clone() should create new stopped session owned by newCreatorID: got = %+v", got)
+			}
+			if got.Name != tt.wantName || got.AllowToControlByOthers != tt.wantAllowToControlByOthers {
+				t.Errorf("Clone() name = %s, allowToControlByOthers = %v, want %s, %v", got.Name, got.AllowToControlByOthers, tt.wantName, tt.wantAllowToControlByOthers)
+			}
+			if got.QueueOrderType != QueueOrderInsertion || got.Autoplay {
+				t.Errorf("Clone() should not copy other settings: got = %+v", got)
+			}
+
+			gotURIs := make([]string, len(gotQueueTracks))
+			for i, qt := range gotQueueTracks {
+				gotURIs[i] = qt.URI
+				if qt.SessionID != got.ID || !qt.AddedAt.Equal(addedAt) {
+					t.Errorf("Clone() queueTrack = %+v, want sessionID = %s, addedAt = %v", qt, got.ID, addedAt)
+				}
+				if got.QueueTracks[i].Votes != 0 {
+					t.Errorf("Clone() should not copy votes: got = %d", got.QueueTracks[i].Votes)
+				}
+			}
+			if !cmp.Equal(gotURIs, tt.wantURIs) {
+				t.Errorf("Clone() uris diff = %s", cmp.Diff(tt.wantURIs, gotURIs))
+			}
+			if len(got.QueueTracks) != len(gotQueueTracks) {
+				t.Errorf("Clone() len(QueueTracks) = %d, want %d", len(got.QueueTracks), len(gotQueueTracks))
+			}
+		})
+	}
+}
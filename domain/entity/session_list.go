@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSessionListLimit はセッション一覧で1ページに返すセッション数のデフォルト値です。
+	DefaultSessionListLimit = 20
+	// MaxSessionListLimit はセッション一覧で1ページに返すセッション数の上限です。
+	MaxSessionListLimit = 100
+)
+
+// SessionListFilter はユーザのセッション一覧を取得するときの絞り込みとページネーションの条件を表します。
+type SessionListFilter struct {
+	StateTypes []StateType        // 空の場合はステートで絞り込まない
+	Name       string             // セッション名に部分一致するものに絞り込む。空文字列の場合は絞り込まない
+	Cursor     *SessionListCursor // 前のページの最後のセッションの位置。nilの場合は最初のページを取得する
+	Limit      int
+}
+
+// SessionListCursor はセッション一覧のページネーションで、前のページの最後のセッションの位置を表します。
+// セッション一覧は最後の操作日時が新しい順、同じ日時の場合はIDの降順に並びます。
+type SessionListCursor struct {
+	LastActivityAt time.Time
+	ID             string
+}
+
+// NewSessionListCursor は指定したセッションの次から取得するためのSessionListCursorのポインタを生成します。
+func NewSessionListCursor(session *Session) *SessionListCursor {
+	return &SessionListCursor{
+		LastActivityAt: session.LastActivityAt,
+		ID:             session.ID,
+	}
+}
+
+// DecodeSessionListCursor はEncodeで文字列にしたカーソルを元に戻します。
+func DecodeSessionListCursor(cursor string) (*SessionListCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor = %s: %v: %w", cursor, err, ErrInvalidSessionListCursor)
+	}
+
+	values := strings.SplitN(string(b), ":", 2)
+	if len(values) != 2 || values[1] == "" {
+		return nil, fmt.Errorf("cursor = %s: %w", cursor, ErrInvalidSessionListCursor)
+	}
+	nsec, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse cursor time = %s: %v: %w", values[0], err, ErrInvalidSessionListCursor)
+	}
+	return &SessionListCursor{
+		LastActivityAt: time.Unix(0, nsec).UTC(),
+		ID:             values[1],
+	}, nil
+}
+
+// Encode はカーソルをURLのクエリパラメータに含められる文字列に変換します。
+func (c *SessionListCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", c.LastActivityAt.UnixNano(), c.ID)))
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeSessionListCursor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cursor  string
+		want    *SessionListCursor
+		wantErr error
+	}{
+		{
+			name:    "Encodeした文字列を元に戻せる",
+			cursor:  (&SessionListCursor{LastActivityAt: time.Date(2020, 1, 1, 0, 0, 0, 1, time.UTC), ID: "sessionID"}).Encode(),
+			want:    &SessionListCursor{LastActivityAt: time.Date(2020, 1, 1, 0, 0, 0, 1, time.UTC), ID: "sessionID"},
+			wantErr: nil,
+		},
+		{
+			name:    "base64でないとErrInvalidSessionListCursor",
+			cursor:  "!!!",
+			want:    nil,
+			wantErr: ErrInvalidSessionListCursor,
+		},
+		{
+			name:    "IDが含まれていないとErrInvalidSessionListCursor",
+			cursor:  "MTIzNDU2",
+			want:    nil,
+			wantErr: ErrInvalidSessionListCursor,
+		},
+		{
+			name:    "日時が数値でないとErrInvalidSessionListCursor",
+			cursor:  "YWJjOnNlc3Npb25JRA",
+			want:    nil,
+			wantErr: ErrInvalidSessionListCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeSessionListCursor(tt.cursor)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeSessionListCursor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("DecodeSessionListCursor() diff = %v", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+import "fmt"
+
+// SessionRole はセッションにおけるユーザの役割を表します。
+type SessionRole string
+
+const (
+	// SessionRoleCreator はセッションの作成者です。全ての操作ができます。
+	SessionRoleCreator SessionRole = "CREATOR"
+	// SessionRoleCoHost は作成者から再生の操作を任された共同ホストです。
+	// AllowToControlByOthersに関わらず、再生やキューを操作できます。
+	SessionRoleCoHost SessionRole = "CO_HOST"
+	// SessionRoleListener はそれ以外の参加者です。
+	// AllowToControlByOthersがtrueの場合のみ、再生やキューを操作できます。
+	SessionRoleListener SessionRole = "LISTENER"
+)
+
+var sessionRoles = []SessionRole{SessionRoleCreator, SessionRoleCoHost, SessionRoleListener}
+
+// NewSessionRole はstringから対応するSessionRoleを生成します。
+func NewSessionRole(role string) (SessionRole, error) {
+	for _, r := range sessionRoles {
+		if r.String() == role {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("sessionRole = %s:%w", role, ErrInvalidSessionRole)
+}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (r SessionRole) String() string {
+	return string(r)
+}
+
+// SessionMember はセッションの参加者とその役割を表します。
+// 作成者はSessionのCreatorIDで管理するので、作成者以外の役割のみを保存します。
+type SessionMember struct {
+	SessionID string
+	UserID    string
+	Role      SessionRole
+}
+
+// NewSessionMember はセッションの作成者がユーザに役割を割り当てたSessionMemberのポインタを生成します。
+// 作成者の役割を変更したり、他のユーザを作成者にすることはできません。
+func (s *Session) NewSessionMember(userID string, role SessionRole) (*SessionMember, error) {
+	if s.IsCreator(userID) {
+		return nil, fmt.Errorf("user id=%s: %w", userID, ErrCreatorRoleNotEditable)
+	}
+	if role == SessionRoleCreator {
+		return nil, fmt.Errorf("role=%s: %w", role, ErrCreatorRoleNotEditable)
+	}
+	return &SessionMember{
+		SessionID: s.ID,
+		UserID:    userID,
+		Role:      role,
+	}, nil
+}
+
+// CanBeControlledBy は指定した役割のユーザがセッションの再生やキューを操作できるかどうかを返します。
+// 作成者と共同ホストは常に操作でき、リスナーはAllowToControlByOthersがtrueの場合のみ操作できます。
+func (s *Session) CanBeControlledBy(role SessionRole) bool {
+	switch role {
+	case SessionRoleCreator, SessionRoleCoHost:
+		return true
+	default:
+		return s.AllowToControlByOthers
+	}
+}
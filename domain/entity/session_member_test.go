@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSession_NewSessionMember(t *testing.T) {
+	t.Parallel()
+
+	session := &Session{ID: "sessionID", CreatorID: "creatorID"}
+
+	tests := []struct {
+		name    string
+		userID  string
+		role    SessionRole
+		want    *SessionMember
+		wantErr error
+	}{
+		{
+			name:   "作成者以外のユーザを共同ホストにできる",
+			userID: "userID",
+			role:   SessionRoleCoHost,
+			want: &SessionMember{
+				SessionID: "sessionID",
+				UserID:    "userID",
+				Role:      SessionRoleCoHost,
+			},
+			wantErr: nil,
+		},
+		{
+			name:    "作成者の役割は変更できない",
+			userID:  "creatorID",
+			role:    SessionRoleListener,
+			want:    nil,
+			wantErr: ErrCreatorRoleNotEditable,
+		},
+		{
+			name:    "他のユーザを作成者にはできない",
+			userID:  "userID",
+			role:    SessionRoleCreator,
+			want:    nil,
+			wantErr: ErrCreatorRoleNotEditable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := session.NewSessionMember(tt.userID, tt.role)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSessionMember() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("NewSessionMember() diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestSession_CanBeControlledBy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		allowToControlByOthers bool
+		role                   SessionRole
+		want                   bool
+	}{
+		{
+			name:                   "作成者は他人による操作が許可されていなくても操作できる",
+			allowToControlByOthers: false,
+			role:                   SessionRoleCreator,
+			want:                   true,
+		},
+		{
+			name:                   "共同ホストは他人による操作が許可されていなくても操作できる",
+			allowToControlByOthers: false,
+			role:                   SessionRoleCoHost,
+			want:                   true,
+		},
+		{
+			name:                   "リスナーは他人による操作が許可されていないと操作できない",
+			allowToControlByOthers: false,
+			role:                   SessionRoleListener,
+			want:                   false,
+		},
+		{
+			name:                   "リスナーも他人による操作が許可されていれば操作できる",
+			allowToControlByOthers: true,
+			role:                   SessionRoleListener,
+			want:                   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Session{AllowToControlByOthers: tt.allowToControlByOthers}
+			if got := s.CanBeControlledBy(tt.role); got != tt.want {
+				t.Errorf("CanBeControlledBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package entity
+
+// MaxSessionDescriptionLength はセッションの説明の最大文字数です。
+const MaxSessionDescriptionLength = 1000
+
+// SessionSettings はセッションの作成時に指定したり、作成後に変更したりできる設定を表します。
+// nilのフィールドは変更せず、作成時はデフォルトの設定になります。
+type SessionSettings struct {
+	Name                   *string
+	Description            *string
+	AllowToControlByOthers *bool
+	QueueOrderType         *QueueOrderType
+	SkipVoteThreshold      *SkipVoteThreshold
+	Autoplay               *bool
+	FallbackPlaylistURI    *string // 空文字列の場合はハウスプレイリストの設定を解除する
+}
+
+// UpdateSettings はセッションの設定を変更します。
+// キューの曲の並び順の決め方が変わった場合はまだSpotifyのキューに追加されていない曲を並び替え、
+// ReorderQueueTracksと同様に位置が変わった曲の変更前と変更後のindexのmapを返します。
+func (s *Session) UpdateSettings(settings SessionSettings) map[int]int {
+	if settings.Name != nil {
+		s.Name = *settings.Name
+	}
+	if settings.Description != nil {
+		s.Description = *settings.Description
+	}
+	if settings.AllowToControlByOthers != nil {
+		s.AllowToControlByOthers = *settings.AllowToControlByOthers
+	}
+	if settings.SkipVoteThreshold != nil {
+		s.SkipVoteThreshold = *settings.SkipVoteThreshold
+	}
+	if settings.Autoplay != nil {
+		s.Autoplay = *settings.Autoplay
+	}
+	if settings.FallbackPlaylistURI != nil {
+		s.FallbackPlaylistURI = *settings.FallbackPlaylistURI
+	}
+
+	if settings.QueueOrderType == nil || *settings.QueueOrderType == s.QueueOrderType {
+		return map[int]int{}
+	}
+	s.QueueOrderType = *settings.QueueOrderType
+	return s.ReorderQueueTracks()
+}
@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSession_UpdateSettings(t *testing.T) {
+	t.Parallel()
+
+	newSession := func() *Session {
+		return &Session{
+			ID:                     "sessionID",
+			Name:                   "sessionName",
+			StateType:              Stop,
+			QueueHead:              0,
+			AllowToControlByOthers: false,
+			QueueOrderType:         QueueOrderInsertion,
+			SkipVoteThreshold:      DefaultSkipVoteThreshold,
+			QueueTracks: []*QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", Votes: 0},
+				{Index: 1, URI: "spotify:track:track_uri2", Votes: 0},
+				{Index: 2, URI: "spotify:track:track_uri3", Votes: 1},
+			},
+		}
+	}
+	name := "newSessionName"
+	description := "description"
+	allowToControlByOthers := true
+	insertion := QueueOrderInsertion
+	vote := QueueOrderVote
+	skipVoteThreshold := SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3}
+	empty := ""
+
+	tests := []struct {
+		name     string
+		session  *Session
+		settings SessionSettings
+		want     *Session
+		wantMove map[int]int
+	}{
+		{
+			name:     "何も指定しないと何も変更されない",
+			session:  newSession(),
+			settings: SessionSettings{},
+			want:     newSession(),
+			wantMove: map[int]int{},
+		},
+		{
+			name:    "指定した設定のみ変更される",
+			session: &Session{Name: "sessionName", Autoplay: true, FallbackPlaylistURI: "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"},
+			settings: SessionSettings{
+				Name:                   &name,
+				Description:            &description,
+				AllowToControlByOthers: &allowToControlByOthers,
+				SkipVoteThreshold:      &skipVoteThreshold,
+				FallbackPlaylistURI:    &empty,
+			},
+			want: &Session{
+				Name:                   "newSessionName",
+				Description:            "description",
+				AllowToControlByOthers: true,
+				SkipVoteThreshold:      SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3},
+				Autoplay:               true,
+				FallbackPlaylistURI:    "",
+			},
+			wantMove: map[int]int{},
+		},
+		{
+			name:     "並び順の決め方が変わらない場合は並び替えない",
+			session:  newSession(),
+			settings: SessionSettings{QueueOrderType: &insertion},
+			want:     newSession(),
+			wantMove: map[int]int{},
+		},
+		{
+			name:     "並び順の決め方が変わるとheadより後の曲を並び替える",
+			session:  newSession(),
+			settings: SessionSettings{QueueOrderType: &vote},
+			want: &Session{
+				ID:                     "sessionID",
+				Name:                   "sessionName",
+				StateType:              Stop,
+				QueueHead:              0,
+				AllowToControlByOthers: false,
+				QueueOrderType:         QueueOrderVote,
+				SkipVoteThreshold:      DefaultSkipVoteThreshold,
+				QueueTracks: []*QueueTrack{
+					{Index: 0, URI: "spotify:track:track_uri1", Votes: 0},
+					{Index: 1, URI: "spotify:track:track_uri3", Votes: 1},
+					{Index: 2, URI: "spotify:track:track_uri2", Votes: 0},
+				},
+			},
+			wantMove: map[int]int{1: 2, 2: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.session.UpdateSettings(tt.settings)
+			if !cmp.Equal(got, tt.wantMove) {
+				t.Errorf("UpdateSettings() diff = %v", cmp.Diff(got, tt.wantMove))
+			}
+			if !cmp.Equal(tt.session, tt.want) {
+				t.Errorf("UpdateSettings() session diff = %v", cmp.Diff(tt.session, tt.want))
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -10,41 +13,81 @@ import (
 func TestNewSession(t *testing.T) {
 	t.Parallel()
 
-	session := &Session{
-		ID:                     "ID",
-		Name:                   "VeryGoodSession",
-		CreatorID:              "VeryCreativePersonID",
-		StateType:              Stop,
-		QueueHead:              0,
-		QueueTracks:            nil,
-		AllowToControlByOthers: true,
-	}
+	sessionName := "VeryGoodSession"
+	allowToControlByOthers := true
+	queueOrderType := QueueOrderFair
+	skipVoteThreshold := SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3}
+	autoplay := true
+	fallbackPlaylistURI := "spotify:playlist:37i9dQZF1DX4WYpdgoIcn6"
 
 	tests := []struct {
-		name                   string
-		sessionName            string
-		creatorID              string
-		allowToCOntrolByOthers bool
-		want                   *Session
+		name             string
+		creatorID        string
+		settings         SessionSettings
+		expirationPolicy ExpirationPolicy
+		want             *Session
 	}{
 		{
-			name:                   "正常系",
-			sessionName:            "VeryGoodSession",
-			creatorID:              "VeryCreativePersonID",
-			allowToCOntrolByOthers: true,
-			want:                   session,
+			name:      "正常系",
+			creatorID: "VeryCreativePersonID",
+			settings: SessionSettings{
+				Name:                   &sessionName,
+				AllowToControlByOthers: &allowToControlByOthers,
+				QueueOrderType:         &queueOrderType,
+				SkipVoteThreshold:      &skipVoteThreshold,
+				Autoplay:               &autoplay,
+				FallbackPlaylistURI:    &fallbackPlaylistURI,
+			},
+			expirationPolicy: ExpirationPolicy{Type: ExpirationPolicyIdle, Hours: 12},
+			want: &Session{
+				ID:                     "ID",
+				Name:                   "VeryGoodSession",
+				CreatorID:              "VeryCreativePersonID",
+				StateType:              Stop,
+				QueueHead:              0,
+				QueueTracks:            nil,
+				AllowToControlByOthers: true,
+				QueueOrderType:         QueueOrderFair,
+				SkipVoteThreshold:      SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3},
+				Autoplay:               true,
+				FallbackPlaylistURI:    "spotify:playlist:37i9dQZF1DX4WYpdgoIcn6",
+				ExpirationPolicy:       ExpirationPolicy{Type: ExpirationPolicyIdle, Hours: 12},
+			},
+		},
+		{
+			name:             "名前以外の設定を指定しないとデフォルトの設定になる",
+			creatorID:        "VeryCreativePersonID",
+			settings:         SessionSettings{Name: &sessionName},
+			expirationPolicy: DefaultExpirationPolicy,
+			want: &Session{
+				ID:                     "ID",
+				Name:                   "VeryGoodSession",
+				CreatorID:              "VeryCreativePersonID",
+				StateType:              Stop,
+				QueueHead:              0,
+				QueueTracks:            nil,
+				AllowToControlByOthers: false,
+				QueueOrderType:         QueueOrderInsertion,
+				SkipVoteThreshold:      DefaultSkipVoteThreshold,
+				Autoplay:               false,
+				FallbackPlaylistURI:    "",
+				ExpirationPolicy:       DefaultExpirationPolicy,
+			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := NewSession(tt.sessionName, tt.creatorID, tt.allowToCOntrolByOthers)
+			got, err := NewSession(tt.creatorID, tt.settings, tt.expirationPolicy)
 			if err != nil {
 				t.Fatal(err)
 			}
-			opts := []cmp.Option{cmpopts.IgnoreFields(Session{}, "ID"), cmpopts.IgnoreFields(Session{}, "ExpiredAt")}
+			opts := []cmp.Option{cmpopts.IgnoreFields(Session{}, "ID"), cmpopts.IgnoreFields(Session{}, "ExpiredAt"), cmpopts.IgnoreFields(Session{}, "LastActivityAt"), cmpopts.IgnoreFields(Session{}, "JoinCode")}
 			if !cmp.Equal(got, tt.want, opts...) {
 				t.Errorf("NewSession() diff = %v", cmp.Diff(got, tt.want, opts...))
 			}
+			if _, err := NormalizeJoinCode(got.JoinCode); err != nil {
+				t.Errorf("NewSession() invalid join code = %s", got.JoinCode)
+			}
 		})
 	}
 }
@@ -120,6 +163,54 @@ func TestStateType_String(t *testing.T) {
 	}
 }
 
+func TestNewQueueOrderType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		queueOrderType string
+		want           QueueOrderType
+		wantErr        bool
+	}{
+		{
+			name:           "Insertion",
+			queueOrderType: "INSERTION",
+			want:           QueueOrderInsertion,
+			wantErr:        false,
+		},
+		{
+			name:           "Fair",
+			queueOrderType: "FAIR",
+			want:           QueueOrderFair,
+			wantErr:        false,
+		},
+		{
+			name:           "Vote",
+			queueOrderType: "VOTE",
+			want:           QueueOrderVote,
+			wantErr:        false,
+		},
+		{
+			name:           "無効なqueue order type",
+			queueOrderType: "invalid",
+			want:           "",
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewQueueOrderType(tt.queueOrderType)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewQueueOrderType() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewQueueOrderType() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSession_IsCreator(t *testing.T) {
 	t.Parallel()
 
@@ -298,6 +389,119 @@ func TestSession_GoNextTrack(t *testing.T) {
 	}
 }
 
+func TestSession_StartHeadTrack(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 1, 0, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		s    *Session
+		want *QueueTrack
+	}{
+		{
+			name: "まだ再生していない曲は開始日時が記録される",
+			s: &Session{
+				QueueHead:   1,
+				QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}},
+			},
+			want: &QueueTrack{Index: 1, StartedAt: now},
+		},
+		{
+			name: "一時停止からの再開など既に再生中として記録されている曲はnil",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: []*QueueTrack{{Index: 0, StartedAt: startedAt}},
+			},
+			want: nil,
+		},
+		{
+			name: "再生が終了した曲を再度再生するときは終了の記録を消して開始日時が記録し直される",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: []*QueueTrack{{Index: 0, StartedAt: startedAt, EndedAt: startedAt, EndReason: QueueTrackInterrupted}},
+			},
+			want: &QueueTrack{Index: 0, StartedAt: now},
+		},
+		{
+			name: "全ての曲を再生し終わっているときはnil",
+			s: &Session{
+				QueueHead:   1,
+				QueueTracks: []*QueueTrack{{Index: 0}},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.s.StartHeadTrack(now)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("StartHeadTrack() diff = %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestSession_EndHeadTrack(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 1, 0, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		s      *Session
+		reason QueueTrackEndReason
+		want   *QueueTrack
+	}{
+		{
+			name: "再生中の曲は終了日時と理由が記録される",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: []*QueueTrack{{Index: 0, StartedAt: startedAt}, {Index: 1}},
+			},
+			reason: QueueTrackFinished,
+			want:   &QueueTrack{Index: 0, StartedAt: startedAt, EndedAt: now, EndReason: QueueTrackFinished},
+		},
+		{
+			name: "再生していない曲をスキップしたときも終了日時と理由が記録される",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}},
+			},
+			reason: QueueTrackSkipped,
+			want:   &QueueTrack{Index: 0, EndedAt: now, EndReason: QueueTrackSkipped},
+		},
+		{
+			name: "既に終了が記録されている曲はnil",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: []*QueueTrack{{Index: 0, StartedAt: startedAt, EndedAt: startedAt, EndReason: QueueTrackInterrupted}},
+			},
+			reason: QueueTrackSkipped,
+			want:   nil,
+		},
+		{
+			name: "一つも曲が追加されていないときはnil",
+			s: &Session{
+				QueueHead:   0,
+				QueueTracks: nil,
+			},
+			reason: QueueTrackFinished,
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.s.EndHeadTrack(now, tt.reason)
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("EndHeadTrack() diff = %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
 func TestSession_TrackURIsShouldBeAddedWhenStopToPlay(t *testing.T) {
 	t.Parallel()
 
@@ -373,6 +577,541 @@ func TestSession_TrackURIsShouldBeAddedWhenStopToPlay(t *testing.T) {
 	}
 }
 
+func TestSession_IsEnqueuedToSpotify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		s     *Session
+		index int
+		want  bool
+	}{
+		{
+			name:  "PLAYでheadの次の曲はSpotifyのキューに追加されている",
+			s:     &Session{QueueHead: 1, StateType: Play},
+			index: 2,
+			want:  true,
+		},
+		{
+			name:  "PAUSEでheadの2曲先の曲はSpotifyのキューに追加されている",
+			s:     &Session{QueueHead: 1, StateType: Pause},
+			index: 3,
+			want:  true,
+		},
+		{
+			name:  "PLAYでheadの3曲先の曲はSpotifyのキューに追加されていない",
+			s:     &Session{QueueHead: 1, StateType: Play},
+			index: 4,
+			want:  false,
+		},
+		{
+			name:  "PLAYでheadの曲はfalse",
+			s:     &Session{QueueHead: 1, StateType: Play},
+			index: 1,
+			want:  false,
+		},
+		{
+			name:  "STOPのときはSpotifyのキューを使っていないのでfalse",
+			s:     &Session{QueueHead: 1, StateType: Stop},
+			index: 2,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.IsEnqueuedToSpotify(tt.index); got != tt.want {
+				t.Errorf("IsEnqueuedToSpotify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_RemoveQueueTrack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		s       *Session
+		index   int
+		want    []*QueueTrack
+		wantErr error
+	}{
+		{
+			name: "headより後ろの曲を削除すると後ろの曲のindexが詰められる",
+			s: &Session{
+				QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}, {Index: 2, URI: "2"}, {Index: 3, URI: "3"}},
+				QueueHead:   1,
+			},
+			index: 2,
+			want:  []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}, {Index: 2, URI: "3"}},
+		},
+		{
+			name: "最後の曲を削除できる",
+			s: &Session{
+				QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+				QueueHead:   0,
+			},
+			index: 1,
+			want:  []*QueueTrack{{Index: 0, URI: "0"}},
+		},
+		{
+			name: "headの曲は削除できない",
+			s: &Session{
+				QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+				QueueHead:   1,
+			},
+			index:   1,
+			want:    []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+			wantErr: ErrQueueTrackNotEditable,
+		},
+		{
+			name: "再生済みの曲は削除できない",
+			s: &Session{
+				QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+				QueueHead:   1,
+			},
+			index:   0,
+			want:    []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+			wantErr: ErrQueueTrackNotEditable,
+		},
+		{
+			name: "存在しないindexを指定するとErrQueueTrackNotFound",
+			s: &Session{
+				QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+				QueueHead:   0,
+			},
+			index:   2,
+			want:    []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}},
+			wantErr: ErrQueueTrackNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.RemoveQueueTrack(tt.index)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RemoveQueueTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(tt.s.QueueTracks, tt.want) {
+				t.Errorf("RemoveQueueTrack() diff = %v", cmp.Diff(tt.want, tt.s.QueueTracks))
+			}
+		})
+	}
+}
+
+func TestSession_MoveQueueTrack(t *testing.T) {
+	t.Parallel()
+
+	newQueueTracks := func() []*QueueTrack {
+		return []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}, {Index: 2, URI: "2"}, {Index: 3, URI: "3"}, {Index: 4, URI: "4"}}
+	}
+
+	tests := []struct {
+		name    string
+		s       *Session
+		from    int
+		to      int
+		want    []*QueueTrack
+		wantErr error
+	}{
+		{
+			name: "後ろの曲を前に移動すると間の曲が1つずつ後ろにずれる",
+			s:    &Session{QueueTracks: newQueueTracks(), QueueHead: 0},
+			from: 4,
+			to:   1,
+			want: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "4"}, {Index: 2, URI: "1"}, {Index: 3, URI: "2"}, {Index: 4, URI: "3"}},
+		},
+		{
+			name: "前の曲を後ろに移動すると間の曲が1つずつ前にずれる",
+			s:    &Session{QueueTracks: newQueueTracks(), QueueHead: 0},
+			from: 1,
+			to:   3,
+			want: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "2"}, {Index: 2, URI: "3"}, {Index: 3, URI: "1"}, {Index: 4, URI: "4"}},
+		},
+		{
+			name: "同じ位置を指定すると何も変わらない",
+			s:    &Session{QueueTracks: newQueueTracks(), QueueHead: 0},
+			from: 2,
+			to:   2,
+			want: newQueueTracks(),
+		},
+		{
+			name:    "headの位置には移動できない",
+			s:       &Session{QueueTracks: newQueueTracks(), QueueHead: 1},
+			from:    3,
+			to:      1,
+			want:    newQueueTracks(),
+			wantErr: ErrQueueTrackNotEditable,
+		},
+		{
+			name:    "再生済みの曲は移動できない",
+			s:       &Session{QueueTracks: newQueueTracks(), QueueHead: 1},
+			from:    0,
+			to:      3,
+			want:    newQueueTracks(),
+			wantErr: ErrQueueTrackNotEditable,
+		},
+		{
+			name:    "キューの範囲外には移動できない",
+			s:       &Session{QueueTracks: newQueueTracks(), QueueHead: 0},
+			from:    2,
+			to:      5,
+			want:    newQueueTracks(),
+			wantErr: ErrQueueTrackNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.MoveQueueTrack(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MoveQueueTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !cmp.Equal(tt.s.QueueTracks, tt.want) {
+				t.Errorf("MoveQueueTrack() diff = %v", cmp.Diff(tt.want, tt.s.QueueTracks))
+			}
+		})
+	}
+}
+
+func TestSession_EnqueueIndex(t *testing.T) {
+	t.Parallel()
+
+	newQueueTracks := func() []*QueueTrack {
+		return []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}, {Index: 2, URI: "2"}}
+	}
+
+	tests := []struct {
+		name     string
+		s        *Session
+		position EnqueuePosition
+		want     int
+	}{
+		{
+			name:     "lastの場合はキューの最後",
+			s:        &Session{QueueTracks: newQueueTracks(), QueueHead: 0},
+			position: EnqueuePositionLast,
+			want:     3,
+		},
+		{
+			name:     "nextの場合はheadの直後",
+			s:        &Session{QueueTracks: newQueueTracks(), QueueHead: 1},
+			position: EnqueuePositionNext,
+			want:     2,
+		},
+		{
+			name:     "nextでもheadより後ろに曲が無い場合はキューの最後",
+			s:        &Session{QueueTracks: newQueueTracks(), QueueHead: 2},
+			position: EnqueuePositionNext,
+			want:     3,
+		},
+		{
+			name:     "nextでも全ての曲を再生し終わっている場合はキューの最後",
+			s:        &Session{QueueTracks: newQueueTracks(), QueueHead: 3},
+			position: EnqueuePositionNext,
+			want:     3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.EnqueueIndex(tt.position); got != tt.want {
+				t.Errorf("EnqueueIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_InsertQueueTrack(t *testing.T) {
+	t.Parallel()
+
+	s := &Session{ID: "sessionID", QueueTracks: []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "1"}}}
+	s.InsertQueueTrack(1, &QueueTrackToStore{URI: "new"})
+
+	want := []*QueueTrack{{Index: 0, URI: "0"}, {Index: 1, URI: "new", SessionID: "sessionID"}, {Index: 2, URI: "1"}}
+	if !cmp.Equal(s.QueueTracks, want) {
+		t.Errorf("InsertQueueTrack() diff = %v", cmp.Diff(want, s.QueueTracks))
+	}
+}
+
+func TestSession_AddQueueTrackVote(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		s       *Session
+		index   int
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "まだ再生されていない曲の投票数が1増える",
+			s:       &Session{QueueHead: 0, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1, Votes: 2}}},
+			index:   1,
+			want:    3,
+			wantErr: nil,
+		},
+		{
+			name:    "headの曲には投票できない",
+			s:       &Session{QueueHead: 1, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1, Votes: 2}}},
+			index:   1,
+			want:    2,
+			wantErr: ErrQueueTrackNotEditable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.s.AddQueueTrackVote(tt.index)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddQueueTrackVote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := tt.s.QueueTracks[tt.index].Votes; got != tt.want {
+				t.Errorf("AddQueueTrackVote() votes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_ReorderQueueTracks(t *testing.T) {
+	t.Parallel()
+
+	addedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	qt := func(index int, addedBy string, minutes int) *QueueTrack {
+		return &QueueTrack{Index: index, URI: fmt.Sprintf("%s%d", addedBy, minutes), AddedBy: addedBy, AddedAt: addedAt.Add(time.Duration(minutes) * time.Minute)}
+	}
+
+	tests := []struct {
+		name  string
+		s     *Session
+		want  []*QueueTrack
+		moves map[int]int
+	}{
+		{
+			name: "追加順モードでは並び替えない",
+			s: &Session{QueueOrderType: QueueOrderInsertion, StateType: Stop, QueueTracks: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), qt(2, "a", 2), qt(3, "b", 3),
+			}},
+			want: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), qt(2, "a", 2), qt(3, "b", 3),
+			},
+			moves: map[int]int{},
+		},
+		{
+			name: "停止中はhead以降の曲をユーザごとに1曲ずつ並び替える",
+			s: &Session{QueueOrderType: QueueOrderFair, StateType: Stop, QueueTracks: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), qt(2, "a", 2), qt(3, "b", 3), qt(4, "b", 4),
+			}},
+			want: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "b", 3), qt(2, "a", 1), qt(3, "b", 4), qt(4, "a", 2),
+			},
+			moves: map[int]int{1: 2, 2: 4, 3: 1, 4: 3},
+		},
+		{
+			name: "再生中はSpotifyのキューに追加済みの曲を並び替えない",
+			s: &Session{QueueOrderType: QueueOrderFair, StateType: Play, QueueHead: 0, QueueTracks: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), qt(2, "a", 2), qt(3, "a", 3), qt(4, "a", 4), qt(5, "b", 5),
+			}},
+			want: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), qt(2, "a", 2), qt(3, "b", 5), qt(4, "a", 3), qt(5, "a", 4),
+			},
+			moves: map[int]int{3: 4, 4: 5, 5: 3},
+		},
+		{
+			name: "最近再生されていないユーザの曲が先になる",
+			s: &Session{QueueOrderType: QueueOrderFair, StateType: Stop, QueueTracks: []*QueueTrack{
+				qt(0, "b", 0), qt(1, "a", 1), qt(2, "b", 2),
+			}},
+			want: []*QueueTrack{
+				qt(0, "b", 0), qt(1, "a", 1), qt(2, "b", 2),
+			},
+			moves: map[int]int{},
+		},
+		{
+			name: "投票モードでは投票数の多い順に並び替え、同じ投票数の曲は追加された順番にする",
+			s: &Session{QueueOrderType: QueueOrderVote, StateType: Stop, QueueTracks: []*QueueTrack{
+				qt(0, "a", 0), qt(1, "a", 1), {Index: 2, URI: "b2", AddedBy: "b", AddedAt: addedAt.Add(2 * time.Minute), Votes: 1}, qt(3, "c", 3), {Index: 4, URI: "a4", AddedBy: "a", AddedAt: addedAt.Add(4 * time.Minute), Votes: 2},
+			}},
+			want: []*QueueTrack{
+				qt(0, "a", 0), {Index: 1, URI: "a4", AddedBy: "a", AddedAt: addedAt.Add(4 * time.Minute), Votes: 2}, {Index: 2, URI: "b2", AddedBy: "b", AddedAt: addedAt.Add(2 * time.Minute), Votes: 1}, qt(3, "a", 1), qt(4, "c", 3),
+			},
+			moves: map[int]int{1: 3, 3: 4, 4: 1},
+		},
+		{
+			name: "ゲストが追加した曲は表示名ごとにまとめる",
+			s: &Session{QueueOrderType: QueueOrderFair, StateType: Stop, QueueTracks: []*QueueTrack{
+				{Index: 0, URI: "g0", AddedByName: "ゲスト", AddedAt: addedAt},
+				{Index: 1, URI: "g1", AddedByName: "ゲスト", AddedAt: addedAt.Add(time.Minute)},
+				{Index: 2, URI: "g2", AddedByName: "ゲスト", AddedAt: addedAt.Add(2 * time.Minute)},
+				qt(3, "a", 3),
+			}},
+			want: []*QueueTrack{
+				{Index: 0, URI: "g0", AddedByName: "ゲスト", AddedAt: addedAt},
+				qt(1, "a", 3),
+				{Index: 2, URI: "g1", AddedByName: "ゲスト", AddedAt: addedAt.Add(time.Minute)},
+				{Index: 3, URI: "g2", AddedByName: "ゲスト", AddedAt: addedAt.Add(2 * time.Minute)},
+			},
+			moves: map[int]int{1: 2, 2: 3, 3: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.s.ReorderQueueTracks()
+			if !cmp.Equal(got, tt.moves) {
+				t.Errorf("ReorderQueueTracks() diff = %v", cmp.Diff(tt.moves, got))
+			}
+			if !cmp.Equal(tt.s.QueueTracks, tt.want) {
+				t.Errorf("ReorderQueueTracks() QueueTracks diff = %v", cmp.Diff(tt.want, tt.s.QueueTracks))
+			}
+		})
+	}
+}
+
+func TestSession_ShouldAutoplay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		s    *Session
+		want bool
+	}{
+		{
+			name: "自動再生が有効でheadが最後の曲の時はtrue",
+			s:    &Session{Autoplay: true, QueueHead: 1, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: true,
+		},
+		{
+			name: "headの後ろに曲がある時はfalse",
+			s:    &Session{Autoplay: true, QueueHead: 0, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: false,
+		},
+		{
+			name: "自動再生が無効の時はfalse",
+			s:    &Session{Autoplay: false, QueueHead: 1, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: false,
+		},
+		{
+			name: "キューが空の時はシードにする曲が無いのでfalse",
+			s:    &Session{Autoplay: true, QueueHead: 0},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.ShouldAutoplay(); got != tt.want {
+				t.Errorf("ShouldAutoplay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_ShouldPullFromFallbackPlaylist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		s    *Session
+		want bool
+	}{
+		{
+			name: "ハウスプレイリストが設定されていてheadが最後の曲の時はtrue",
+			s:    &Session{FallbackPlaylistURI: "spotify:playlist:playlist_id", QueueHead: 1, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: true,
+		},
+		{
+			name: "headの後ろに曲がある時はfalse",
+			s:    &Session{FallbackPlaylistURI: "spotify:playlist:playlist_id", QueueHead: 0, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: false,
+		},
+		{
+			name: "ハウスプレイリストが設定されていない時はfalse",
+			s:    &Session{FallbackPlaylistURI: "", QueueHead: 1, QueueTracks: []*QueueTrack{{Index: 0}, {Index: 1}}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.ShouldPullFromFallbackPlaylist(); got != tt.want {
+				t.Errorf("ShouldPullFromFallbackPlaylist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_NextFallbackTrackURI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		s                 *Session
+		playlistTrackURIs []string
+		want              string
+	}{
+		{
+			name: "キューに入ったことがない曲のうちプレイリストの先頭に近い曲",
+			s: &Session{QueueTracks: []*QueueTrack{
+				{Index: 0, URI: "spotify:track:1"},
+				{Index: 1, URI: "spotify:track:3"},
+			}},
+			playlistTrackURIs: []string{"spotify:track:1", "spotify:track:2", "spotify:track:3", "spotify:track:4"},
+			want:              "spotify:track:2",
+		},
+		{
+			name: "全ての曲がキューに入ったことがある時は最後にキューに入ったのが最も古い曲",
+			s: &Session{QueueTracks: []*QueueTrack{
+				{Index: 0, URI: "spotify:track:1"},
+				{Index: 1, URI: "spotify:track:2"},
+				{Index: 2, URI: "spotify:track:1"},
+				{Index: 3, URI: "spotify:track:3"},
+			}},
+			playlistTrackURIs: []string{"spotify:track:1", "spotify:track:2", "spotify:track:3"},
+			want:              "spotify:track:2",
+		},
+		{
+			name:              "プレイリストが空の時は空文字列",
+			s:                 &Session{QueueTracks: []*QueueTrack{{Index: 0, URI: "spotify:track:1"}}},
+			playlistTrackURIs: []string{},
+			want:              "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.NextFallbackTrackURI(tt.playlistTrackURIs); got != tt.want {
+				t.Errorf("NextFallbackTrackURI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSession_AutoplaySeedTrackURIs(t *testing.T) {
+	t.Parallel()
+
+	queueTracks := make([]*QueueTrack, 8)
+	for i := range queueTracks {
+		queueTracks[i] = &QueueTrack{Index: i, URI: fmt.Sprintf("spotify:track:%d", i)}
+	}
+
+	tests := []struct {
+		name string
+		s    *Session
+		want []string
+	}{
+		{
+			name: "headまでの曲のうち最近の5曲",
+			s:    &Session{QueueHead: 6, QueueTracks: queueTracks},
+			want: []string{"spotify:track:2", "spotify:track:3", "spotify:track:4", "spotify:track:5", "spotify:track:6"},
+		},
+		{
+			name: "headまでの曲が5曲未満の時は全ての曲",
+			s:    &Session{QueueHead: 1, QueueTracks: queueTracks},
+			want: []string{"spotify:track:0", "spotify:track:1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.AutoplaySeedTrackURIs(); !cmp.Equal(got, tt.want) {
+				t.Errorf("AutoplaySeedTrackURIs() diff = %v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
 func TestSession_TrackURIShouldBeAddedWhenHandleTrackEnd(t *testing.T) {
 	t.Parallel()
 
@@ -516,3 +1255,67 @@ func TestSession_IsPlayingCorrectTrack(t *testing.T) {
 		})
 	}
 }
+
+func TestSession_ExportTrackURIs(t *testing.T) {
+	t.Parallel()
+
+	queueTracks := []*QueueTrack{
+		{Index: 0, URI: "spotify:track:0"},
+		{Index: 1, URI: "spotify:track:1"},
+		{Index: 2, URI: "spotify:track:2"},
+	}
+
+	tests := []struct {
+		name   string
+		s      *Session
+		target PlaylistExportTarget
+		want   []string
+	}{
+		{
+			name:   "playedの場合はheadより前の曲",
+			s:      &Session{QueueHead: 2, QueueTracks: queueTracks},
+			target: PlaylistExportPlayed,
+			want:   []string{"spotify:track:0", "spotify:track:1"},
+		},
+		{
+			name:   "全ての曲を再生し終わった後のplayedはキューの全ての曲",
+			s:      &Session{QueueHead: 3, QueueTracks: queueTracks},
+			target: PlaylistExportPlayed,
+			want:   []string{"spotify:track:0", "spotify:track:1", "spotify:track:2"},
+		},
+		{
+			name:   "allの場合はキューの全ての曲",
+			s:      &Session{QueueHead: 0, QueueTracks: queueTracks},
+			target: PlaylistExportAll,
+			want:   []string{"spotify:track:0", "spotify:track:1", "spotify:track:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.ExportTrackURIs(tt.target); !cmp.Equal(got, tt.want) {
+				t.Errorf("ExportTrackURIs() diff = %v", cmp.Diff(got, tt.want))
+			}
+		})
+	}
+}
+
+func TestSession_History(t *testing.T) {
+	t.Parallel()
+
+	queueTracks := []*QueueTrack{
+		{Index: 0, URI: "spotify:track:0"},
+		{Index: 1, URI: "spotify:track:1"},
+		{Index: 2, URI: "spotify:track:2"},
+	}
+	tracks := []*Track{{URI: "spotify:track:0"}, nil, {URI: "spotify:track:2"}}
+
+	s := &Session{QueueHead: 1, QueueTracks: queueTracks}
+	want := []*HistoryTrack{
+		{QueueTrack: queueTracks[0], Track: tracks[0], Status: HistoryTrackPlayed},
+		{QueueTrack: queueTracks[1], Track: nil, Status: HistoryTrackPending},
+		{QueueTrack: queueTracks[2], Track: tracks[2], Status: HistoryTrackPending},
+	}
+	if got := s.History(tracks); !cmp.Equal(got, want) {
+		t.Errorf("History() diff = %v", cmp.Diff(got, want))
+	}
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+)
+
+// SkipVoteThresholdType は投票で曲をスキップするのに必要な票数の決め方を表します。
+type SkipVoteThresholdType string
+
+const (
+	// SkipVoteThresholdCount は決まった票数が集まったらスキップします。
+	SkipVoteThresholdCount SkipVoteThresholdType = "COUNT"
+	// SkipVoteThresholdRatio はセッションに接続しているクライアント数に対して決まった割合の票が集まったらスキップします。
+	SkipVoteThresholdRatio SkipVoteThresholdType = "RATIO"
+)
+
+var skipVoteThresholdTypes = []SkipVoteThresholdType{SkipVoteThresholdCount, SkipVoteThresholdRatio}
+
+// DefaultSkipVoteThreshold はセッション作成時に指定がなかった場合のスキップ投票の閾値です。
+// 接続しているクライアントの過半数が投票したらスキップします。
+var DefaultSkipVoteThreshold = SkipVoteThreshold{Type: SkipVoteThresholdRatio, Value: 0.5}
+
+// String はfmt.Stringerを満たすメソッドです。
+func (t SkipVoteThresholdType) String() string {
+	return string(t)
+}
+
+// SkipVoteThreshold は投票で曲をスキップするのに必要な票数の閾値を表します。
+// TypeがCOUNTの場合はValueが票数、RATIOの場合はValueが接続しているクライアント数に対する割合になります。
+type SkipVoteThreshold struct {
+	Type  SkipVoteThresholdType
+	Value float64
+}
+
+// NewSkipVoteThreshold はSkipVoteThresholdを生成します。
+// COUNTの場合は1以上の整数、RATIOの場合は0より大きく1以下の値のみ受け付けます。
+func NewSkipVoteThreshold(thresholdType string, value float64) (SkipVoteThreshold, error) {
+	for _, t := range skipVoteThresholdTypes {
+		if t.String() != thresholdType {
+			continue
+		}
+		switch t {
+		case SkipVoteThresholdCount:
+			if value < 1 || value != math.Trunc(value) {
+				return SkipVoteThreshold{}, fmt.Errorf("count = %v: %w", value, ErrInvalidSkipVoteThreshold)
+			}
+		case SkipVoteThresholdRatio:
+			if value <= 0 || 1 < value {
+				return SkipVoteThreshold{}, fmt.Errorf("ratio = %v: %w", value, ErrInvalidSkipVoteThreshold)
+			}
+		}
+		return SkipVoteThreshold{Type: t, Value: value}, nil
+	}
+	return SkipVoteThreshold{}, fmt.Errorf("thresholdType = %s: %w", thresholdType, ErrInvalidSkipVoteThreshold)
+}
+
+// RequiredVotes はセッションに接続しているクライアント数から、スキップに必要な票数を返します。
+// 必要な票数は常に1票以上になります。
+func (t SkipVoteThreshold) RequiredVotes(listeners int) int {
+	required := int(t.Value)
+	if t.Type == SkipVoteThresholdRatio {
+		required = int(math.Ceil(t.Value * float64(listeners)))
+	}
+	if required < 1 {
+		return 1
+	}
+	return required
+}
+
+// SkipVote はセッションの現在の曲(head)に対するスキップ投票の途中経過を表します。
+type SkipVote struct {
+	Votes    int  `json:"votes"`
+	Required int  `json:"required"`
+	Skipped  bool `json:"skipped"`
+}
+
+// NewSkipVote はSkipVoteのポインタを生成します。
+// 閾値に達した後もheadが進むまでは投票できるので、二重にスキップしないように票数がちょうど閾値に達した投票でだけスキップします。
+func NewSkipVote(votes, required int) *SkipVote {
+	return &SkipVote{
+		Votes:    votes,
+		Required: required,
+		Skipped:  votes == required,
+	}
+}
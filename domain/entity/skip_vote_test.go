@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewSkipVoteThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		thresholdType string
+		value         float64
+		want          SkipVoteThreshold
+		wantErr       error
+	}{
+		{
+			name:          "票数で指定できる",
+			thresholdType: "COUNT",
+			value:         3,
+			want:          SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3},
+			wantErr:       nil,
+		},
+		{
+			name:          "割合で指定できる",
+			thresholdType: "RATIO",
+			value:         0.5,
+			want:          SkipVoteThreshold{Type: SkipVoteThresholdRatio, Value: 0.5},
+			wantErr:       nil,
+		},
+		{
+			name:          "票数が整数でないとErrInvalidSkipVoteThreshold",
+			thresholdType: "COUNT",
+			value:         1.5,
+			want:          SkipVoteThreshold{},
+			wantErr:       ErrInvalidSkipVoteThreshold,
+		},
+		{
+			name:          "票数が0だとErrInvalidSkipVoteThreshold",
+			thresholdType: "COUNT",
+			value:         0,
+			want:          SkipVoteThreshold{},
+			wantErr:       ErrInvalidSkipVoteThreshold,
+		},
+		{
+			name:          "割合が1より大きいとErrInvalidSkipVoteThreshold",
+			thresholdType: "RATIO",
+			value:         1.5,
+			want:          SkipVoteThreshold{},
+			wantErr:       ErrInvalidSkipVoteThreshold,
+		},
+		{
+			name:          "無効な種類だとErrInvalidSkipVoteThreshold",
+			thresholdType: "invalid",
+			value:         1,
+			want:          SkipVoteThreshold{},
+			wantErr:       ErrInvalidSkipVoteThreshold,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSkipVoteThreshold(tt.thresholdType, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewSkipVoteThreshold() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NewSkipVoteThreshold() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipVoteThreshold_RequiredVotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		threshold SkipVoteThreshold
+		listeners int
+		want      int
+	}{
+		{
+			name:      "票数で指定した場合はクライアント数に関わらずその票数が必要",
+			threshold: SkipVoteThreshold{Type: SkipVoteThresholdCount, Value: 3},
+			listeners: 10,
+			want:      3,
+		},
+		{
+			name:      "割合で指定した場合はクライアント数に割合をかけて切り上げた票数が必要",
+			threshold: SkipVoteThreshold{Type: SkipVoteThresholdRatio, Value: 0.5},
+			listeners: 5,
+			want:      3,
+		},
+		{
+			name:      "クライアントが接続していなくても1票は必要",
+			threshold: SkipVoteThreshold{Type: SkipVoteThresholdRatio, Value: 0.5},
+			listeners: 0,
+			want:      1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.threshold.RequiredVotes(tt.listeners); got != tt.want {
+				t.Errorf("RequiredVotes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package entity
 
-import "time"
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
 
 // Track は曲を表す構造体です。
 type Track struct {
@@ -43,3 +49,100 @@ func (cpi *CurrentPlayingInfo) Remain() time.Duration {
 	}
 	return cpi.Track.Duration - cpi.Progress
 }
+
+// SpotifyURIType はSpotifyのURIが指すリソースの種類を表します。
+type SpotifyURIType string
+
+const (
+	SpotifyURITypeTrack    SpotifyURIType = "track"
+	SpotifyURITypeAlbum    SpotifyURIType = "album"
+	SpotifyURITypePlaylist SpotifyURIType = "playlist"
+)
+
+// ParseSpotifyURI は spotify:<type>:<id> 形式のURIをリソースの種類とIDに分解します。
+// 古い形式のプレイリストのURI(spotify:user:<user_id>:playlist:<id>)にも対応しています。
+func ParseSpotifyURI(uri string) (SpotifyURIType, string, error) {
+	parts := strings.Split(uri, ":")
+	if len(parts) == 5 && parts[0] == "spotify" && parts[1] == "user" && parts[3] == "playlist" && parts[4] != "" {
+		return SpotifyURITypePlaylist, parts[4], nil
+	}
+	if len(parts) != 3 || parts[0] != "spotify" || parts[2] == "" {
+		return "", "", fmt.Errorf("uri=%s: %w", uri, ErrInvalidSpotifyURI)
+	}
+
+	switch uriType := SpotifyURIType(parts[1]); uriType {
+	case SpotifyURITypeTrack, SpotifyURITypeAlbum, SpotifyURITypePlaylist:
+		return uriType, parts[2], nil
+	}
+	return "", "", fmt.Errorf("unsupported type uri=%s: %w", uri, ErrInvalidSpotifyURI)
+}
+
+// spotifyIDPattern はSpotifyのリソースのIDの形式(22文字のbase62)を表します。
+var spotifyIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// NormalizeSpotifyURI はユーザが入力した曲・アルバム・プレイリストの指定を spotify:<type>:<id> 形式のURIに変換します。
+// SpotifyのURIの他に、共有リンク(https://open.spotify.com/track/<id>?si=...)と曲のIDのみの指定を受け付けます。
+func NormalizeSpotifyURI(input string) (string, error) {
+	input = strings.TrimSpace(input)
+
+	if strings.HasPrefix(input, "spotify:") {
+		uriType, id, err := ParseSpotifyURI(input)
+		if err != nil {
+			return "", fmt.Errorf("parse spotify uri: %w", err)
+		}
+		return toSpotifyURI(uriType, id), nil
+	}
+
+	if spotifyIDPattern.MatchString(input) {
+		return toSpotifyURI(SpotifyURITypeTrack, input), nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || (u.Host != "open.spotify.com" && u.Host != "play.spotify.com") {
+		return "", fmt.Errorf("input=%s: %w", input, ErrInvalidSpotifyURI)
+	}
+	uriType, id, err := parseSpotifyURLPath(u.Path)
+	if err != nil {
+		return "", fmt.Errorf("input=%s: %w", input, err)
+	}
+	return toSpotifyURI(uriType, id), nil
+}
+
+// NormalizePlaylistURI はユーザが入力したプレイリストの指定を spotify:playlist:<id> 形式のURIに変換します。
+// NormalizeSpotifyURIと同じ形式を受け付けますが、プレイリスト以外を指している場合はErrInvalidSpotifyURIを返します。
+func NormalizePlaylistURI(input string) (string, error) {
+	uri, err := NormalizeSpotifyURI(input)
+	if err != nil {
+		return "", fmt.Errorf("normalize spotify uri: %w", err)
+	}
+	if uriType, _, _ := ParseSpotifyURI(uri); uriType != SpotifyURITypePlaylist {
+		return "", fmt.Errorf("uri=%s is not playlist: %w", uri, ErrInvalidSpotifyURI)
+	}
+	return uri, nil
+}
+
+// parseSpotifyURLPath は共有リンクのパスをリソースの種類とIDに分解します。
+// 言語指定(/intl-ja/...)や埋め込み用(/embed/...)のパス、ユーザ名を含む古い形式のプレイリストのパスにも対応しています。
+func parseSpotifyURLPath(path string) (SpotifyURIType, string, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > 0 && (strings.HasPrefix(parts[0], "intl-") || parts[0] == "embed") {
+		parts = parts[1:]
+	}
+	if len(parts) == 4 && parts[0] == "user" && parts[2] == "playlist" {
+		parts = parts[2:]
+	}
+	if len(parts) != 2 || !spotifyIDPattern.MatchString(parts[1]) {
+		return "", "", fmt.Errorf("path=%s: %w", path, ErrInvalidSpotifyURI)
+	}
+
+	switch uriType := SpotifyURIType(parts[0]); uriType {
+	case SpotifyURITypeTrack, SpotifyURITypeAlbum, SpotifyURITypePlaylist:
+		return uriType, parts[1], nil
+	}
+	return "", "", fmt.Errorf("unsupported type path=%s: %w", path, ErrInvalidSpotifyURI)
+}
+
+// toSpotifyURI はリソースの種類とIDから spotify:<type>:<id> 形式のURIを生成します。
+func toSpotifyURI(uriType SpotifyURIType, id string) string {
+	return fmt.Sprintf("spotify:%s:%s", uriType, id)
+}
@@ -0,0 +1,198 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSpotifyURI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		uri      string
+		wantType SpotifyURIType
+		wantID   string
+		wantErr  error
+	}{
+		{
+			name:     "曲のURI",
+			uri:      "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			wantType: SpotifyURITypeTrack,
+			wantID:   "5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr:  nil,
+		},
+		{
+			name:     "アルバムのURI",
+			uri:      "spotify:album:2up3OPMp9Tb4dAKM2erWXQ",
+			wantType: SpotifyURITypeAlbum,
+			wantID:   "2up3OPMp9Tb4dAKM2erWXQ",
+			wantErr:  nil,
+		},
+		{
+			name:     "プレイリストのURI",
+			uri:      "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantType: SpotifyURITypePlaylist,
+			wantID:   "37i9dQZF1DXcBWIGoYBM5M",
+			wantErr:  nil,
+		},
+		{
+			name:     "ユーザ名を含む古い形式のプレイリストのURI",
+			uri:      "spotify:user:spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantType: SpotifyURITypePlaylist,
+			wantID:   "37i9dQZF1DXcBWIGoYBM5M",
+			wantErr:  nil,
+		},
+		{
+			name:    "アーティストのURIは対応していない",
+			uri:     "spotify:artist:0OdUWJ0sBjDrqHygGUXeCF",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+		{
+			name:    "IDが空",
+			uri:     "spotify:track:",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+		{
+			name:    "SpotifyのURIではない",
+			uri:     "valid_uri",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotType, gotID, err := ParseSpotifyURI(tt.uri)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ParseSpotifyURI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotType != tt.wantType {
+				t.Errorf("ParseSpotifyURI() gotType = %v, want %v", gotType, tt.wantType)
+			}
+			if gotID != tt.wantID {
+				t.Errorf("ParseSpotifyURI() gotID = %v, want %v", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestNormalizeSpotifyURI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "曲のURIはそのまま",
+			input:   "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			want:    "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr: nil,
+		},
+		{
+			name:    "ユーザ名を含む古い形式のプレイリストのURIは新しい形式になる",
+			input:   "spotify:user:spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			want:    "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantErr: nil,
+		},
+		{
+			name:    "曲の共有リンクはクエリパラメータを取り除いてURIになる",
+			input:   "https://open.spotify.com/track/5uQ0vKy2973Y9IUCd1wMEF?si=abcdefg",
+			want:    "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr: nil,
+		},
+		{
+			name:    "言語指定を含むアルバムの共有リンク",
+			input:   "https://open.spotify.com/intl-ja/album/2up3OPMp9Tb4dAKM2erWXQ",
+			want:    "spotify:album:2up3OPMp9Tb4dAKM2erWXQ",
+			wantErr: nil,
+		},
+		{
+			name:    "前後の空白を取り除いたプレイリストの共有リンク",
+			input:   " https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abcdefg\n",
+			want:    "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantErr: nil,
+		},
+		{
+			name:    "IDのみの場合は曲のURIになる",
+			input:   "5uQ0vKy2973Y9IUCd1wMEF",
+			want:    "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr: nil,
+		},
+		{
+			name:    "アーティストの共有リンクは対応していない",
+			input:   "https://open.spotify.com/artist/0OdUWJ0sBjDrqHygGUXeCF",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+		{
+			name:    "Spotify以外のURLは対応していない",
+			input:   "https://example.com/track/5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+		{
+			name:    "IDの形式ではない文字列は対応していない",
+			input:   "never gonna give you up",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeSpotifyURI(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizeSpotifyURI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeSpotifyURI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePlaylistURI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "プレイリストのURIはそのまま",
+			input:   "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			want:    "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantErr: nil,
+		},
+		{
+			name:    "プレイリストの共有リンクはURIになる",
+			input:   "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abcdefg",
+			want:    "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+			wantErr: nil,
+		},
+		{
+			name:    "曲のURIはErrInvalidSpotifyURI",
+			input:   "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+		{
+			name:    "IDのみの場合は曲とみなされるのでErrInvalidSpotifyURI",
+			input:   "37i9dQZF1DXcBWIGoYBM5M",
+			wantErr: ErrInvalidSpotifyURI,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePlaylistURI(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NormalizePlaylistURI() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePlaylistURI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
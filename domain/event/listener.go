@@ -0,0 +1,12 @@
+//go:generate mockgen -source=$GOFILE -destination=../mock_$GOPACKAGE/$GOFILE
+
+package event
+
+import "github.com/camphor-/relaym-server/domain/entity"
+
+// ListenerCounter はセッションに接続しているリスナーを数えたり、一覧を取得したりするインターフェースです。
+// 同じユーザが複数のクライアントから接続している場合も一人として扱います。
+type ListenerCounter interface {
+	CountListeners(sessionID string) int
+	Listeners(sessionID string) []*entity.Listener
+}
@@ -13,4 +13,9 @@ type Pusher interface {
 type PushMessage struct {
 	SessionID string
 	Msg       *entity.Event
+	// ParticipantID が空でない場合は、指定した参加者(ユーザIDまたはゲストID)のクライアントにのみ送信します。
+	ParticipantID string
+	// Disconnect がtrueの場合は、メッセージを送信した後にセッションに接続している全てのクライアントとの接続を切断します。
+	// ParticipantIDが指定されている場合は、その参加者のクライアントとの接続のみを切断します。
+	Disconnect bool
 }
@@ -35,6 +35,21 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 	return m.recorder
 }
 
+// DeleteExpiredGuests mocks base method.
+func (m *MockAuth) DeleteExpiredGuests() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredGuests")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredGuests indicates an expected call of DeleteExpiredGuests.
+func (mr *MockAuthMockRecorder) DeleteExpiredGuests() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredGuests", reflect.TypeOf((*MockAuth)(nil).DeleteExpiredGuests))
+}
+
 // DeleteState mocks base method.
 func (m *MockAuth) DeleteState(state string) error {
 	m.ctrl.T.Helper()
@@ -49,6 +64,21 @@ func (mr *MockAuthMockRecorder) DeleteState(state interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteState", reflect.TypeOf((*MockAuth)(nil).DeleteState), state)
 }
 
+// FindGuestByToken mocks base method.
+func (m *MockAuth) FindGuestByToken(token string) (*entity.Guest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGuestByToken", token)
+	ret0, _ := ret[0].(*entity.Guest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindGuestByToken indicates an expected call of FindGuestByToken.
+func (mr *MockAuthMockRecorder) FindGuestByToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGuestByToken", reflect.TypeOf((*MockAuth)(nil).FindGuestByToken), token)
+}
+
 // FindStateByState mocks base method.
 func (m *MockAuth) FindStateByState(state string) (*entity.AuthState, error) {
 	m.ctrl.T.Helper()
@@ -94,6 +124,20 @@ func (mr *MockAuthMockRecorder) GetUserIDFromSession(sessionID interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDFromSession", reflect.TypeOf((*MockAuth)(nil).GetUserIDFromSession), sessionID)
 }
 
+// StoreGuest mocks base method.
+func (m *MockAuth) StoreGuest(token string, guest *entity.Guest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreGuest", token, guest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreGuest indicates an expected call of StoreGuest.
+func (mr *MockAuthMockRecorder) StoreGuest(token, guest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreGuest", reflect.TypeOf((*MockAuth)(nil).StoreGuest), token, guest)
+}
+
 // StoreORUpdateToken mocks base method.
 func (m *MockAuth) StoreORUpdateToken(userID string, token *oauth2.Token) error {
 	m.ctrl.T.Helper()
@@ -37,11 +37,12 @@ func (m *MockSession) EXPECT() *MockSessionMockRecorder {
 }
 
 // ArchiveSessionsForBatch mocks base method.
-func (m *MockSession) ArchiveSessionsForBatch() error {
+func (m *MockSession) ArchiveSessionsForBatch() ([]*entity.ArchivedSession, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ArchiveSessionsForBatch")
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]*entity.ArchivedSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ArchiveSessionsForBatch indicates an expected call of ArchiveSessionsForBatch.
@@ -50,6 +51,64 @@ func (mr *MockSessionMockRecorder) ArchiveSessionsForBatch() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveSessionsForBatch", reflect.TypeOf((*MockSession)(nil).ArchiveSessionsForBatch))
 }
 
+// CountSkipVotes mocks base method.
+func (m *MockSession) CountSkipVotes(ctx context.Context, sessionID string, index int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSkipVotes", ctx, sessionID, index)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSkipVotes indicates an expected call of CountSkipVotes.
+func (mr *MockSessionMockRecorder) CountSkipVotes(ctx, sessionID, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSkipVotes", reflect.TypeOf((*MockSession)(nil).CountSkipVotes), ctx, sessionID, index)
+}
+
+// Delete mocks base method.
+func (m *MockSession) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockSessionMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSession)(nil).Delete), ctx, id)
+}
+
+// DeleteQueueTrack mocks base method.
+func (m *MockSession) DeleteQueueTrack(ctx context.Context, sessionID string, index int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQueueTrack", ctx, sessionID, index)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteQueueTrack indicates an expected call of DeleteQueueTrack.
+func (mr *MockSessionMockRecorder) DeleteQueueTrack(ctx, sessionID, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQueueTrack", reflect.TypeOf((*MockSession)(nil).DeleteQueueTrack), ctx, sessionID, index)
+}
+
+// DeleteQueueTrackVote mocks base method.
+func (m *MockSession) DeleteQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQueueTrackVote", ctx, sessionID, index, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteQueueTrackVote indicates an expected call of DeleteQueueTrackVote.
+func (mr *MockSessionMockRecorder) DeleteQueueTrackVote(ctx, sessionID, index, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQueueTrackVote", reflect.TypeOf((*MockSession)(nil).DeleteQueueTrackVote), ctx, sessionID, index, userID)
+}
+
 // DoInTx mocks base method.
 func (m *MockSession) DoInTx(ctx context.Context, f func(context.Context) (interface{}, error)) (interface{}, error) {
 	m.ctrl.T.Helper()
@@ -95,6 +154,21 @@ func (mr *MockSessionMockRecorder) FindByIDForUpdate(ctx, id interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDForUpdate", reflect.TypeOf((*MockSession)(nil).FindByIDForUpdate), ctx, id)
 }
 
+// FindByUserID mocks base method.
+func (m *MockSession) FindByUserID(ctx context.Context, userID string, filter entity.SessionListFilter) ([]*entity.SessionWithUser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userID, filter)
+	ret0, _ := ret[0].([]*entity.SessionWithUser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockSessionMockRecorder) FindByUserID(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockSession)(nil).FindByUserID), ctx, userID, filter)
+}
+
 // FindCreatorTokenBySessionID mocks base method.
 func (m *MockSession) FindCreatorTokenBySessionID(arg0 context.Context, arg1 string) (*oauth2.Token, string, error) {
 	m.ctrl.T.Helper()
@@ -111,18 +185,134 @@ func (mr *MockSessionMockRecorder) FindCreatorTokenBySessionID(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCreatorTokenBySessionID", reflect.TypeOf((*MockSession)(nil).FindCreatorTokenBySessionID), arg0, arg1)
 }
 
+// FindIDByJoinCode mocks base method.
+func (m *MockSession) FindIDByJoinCode(ctx context.Context, joinCode string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIDByJoinCode", ctx, joinCode)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIDByJoinCode indicates an expected call of FindIDByJoinCode.
+func (mr *MockSessionMockRecorder) FindIDByJoinCode(ctx, joinCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIDByJoinCode", reflect.TypeOf((*MockSession)(nil).FindIDByJoinCode), ctx, joinCode)
+}
+
+// FindMemberRole mocks base method.
+func (m *MockSession) FindMemberRole(ctx context.Context, sessionID, userID string) (entity.SessionRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMemberRole", ctx, sessionID, userID)
+	ret0, _ := ret[0].(entity.SessionRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMemberRole indicates an expected call of FindMemberRole.
+func (mr *MockSessionMockRecorder) FindMemberRole(ctx, sessionID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMemberRole", reflect.TypeOf((*MockSession)(nil).FindMemberRole), ctx, sessionID, userID)
+}
+
+// IsBanned mocks base method.
+func (m *MockSession) IsBanned(ctx context.Context, sessionID, participantID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBanned", ctx, sessionID, participantID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBanned indicates an expected call of IsBanned.
+func (mr *MockSessionMockRecorder) IsBanned(ctx, sessionID, participantID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBanned", reflect.TypeOf((*MockSession)(nil).IsBanned), ctx, sessionID, participantID)
+}
+
+// IsMember mocks base method.
+func (m *MockSession) IsMember(ctx context.Context, sessionID, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMember", ctx, sessionID, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMember indicates an expected call of IsMember.
+func (mr *MockSessionMockRecorder) IsMember(ctx, sessionID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMember", reflect.TypeOf((*MockSession)(nil).IsMember), ctx, sessionID, userID)
+}
+
+// MoveQueueTrack mocks base method.
+func (m *MockSession) MoveQueueTrack(ctx context.Context, sessionID string, from, to int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveQueueTrack", ctx, sessionID, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveQueueTrack indicates an expected call of MoveQueueTrack.
+func (mr *MockSessionMockRecorder) MoveQueueTrack(ctx, sessionID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveQueueTrack", reflect.TypeOf((*MockSession)(nil).MoveQueueTrack), ctx, sessionID, from, to)
+}
+
+// StoreBan mocks base method.
+func (m *MockSession) StoreBan(ctx context.Context, ban *entity.SessionBan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreBan", ctx, ban)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreBan indicates an expected call of StoreBan.
+func (mr *MockSessionMockRecorder) StoreBan(ctx, ban interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreBan", reflect.TypeOf((*MockSession)(nil).StoreBan), ctx, ban)
+}
+
+// StoreMember mocks base method.
+func (m *MockSession) StoreMember(ctx context.Context, member *entity.SessionMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreMember", ctx, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreMember indicates an expected call of StoreMember.
+func (mr *MockSessionMockRecorder) StoreMember(ctx, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreMember", reflect.TypeOf((*MockSession)(nil).StoreMember), ctx, member)
+}
+
 // StoreQueueTrack mocks base method.
-func (m *MockSession) StoreQueueTrack(arg0 context.Context, arg1 *entity.QueueTrackToStore) error {
+func (m *MockSession) StoreQueueTrack(ctx context.Context, queueTrack *entity.QueueTrackToStore, index int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreQueueTrack", arg0, arg1)
+	ret := m.ctrl.Call(m, "StoreQueueTrack", ctx, queueTrack, index)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreQueueTrack indicates an expected call of StoreQueueTrack.
-func (mr *MockSessionMockRecorder) StoreQueueTrack(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockSessionMockRecorder) StoreQueueTrack(ctx, queueTrack, index interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreQueueTrack", reflect.TypeOf((*MockSession)(nil).StoreQueueTrack), ctx, queueTrack, index)
+}
+
+// StoreQueueTrackVote mocks base method.
+func (m *MockSession) StoreQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreQueueTrackVote", ctx, sessionID, index, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreQueueTrackVote indicates an expected call of StoreQueueTrackVote.
+func (mr *MockSessionMockRecorder) StoreQueueTrackVote(ctx, sessionID, index, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreQueueTrack", reflect.TypeOf((*MockSession)(nil).StoreQueueTrack), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreQueueTrackVote", reflect.TypeOf((*MockSession)(nil).StoreQueueTrackVote), ctx, sessionID, index, userID)
 }
 
 // StoreSession mocks base method.
@@ -139,6 +329,20 @@ func (mr *MockSessionMockRecorder) StoreSession(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreSession", reflect.TypeOf((*MockSession)(nil).StoreSession), arg0, arg1)
 }
 
+// StoreSkipVote mocks base method.
+func (m *MockSession) StoreSkipVote(ctx context.Context, sessionID string, index int, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreSkipVote", ctx, sessionID, index, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreSkipVote indicates an expected call of StoreSkipVote.
+func (mr *MockSessionMockRecorder) StoreSkipVote(ctx, sessionID, index, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreSkipVote", reflect.TypeOf((*MockSession)(nil).StoreSkipVote), ctx, sessionID, index, userID)
+}
+
 // Update mocks base method.
 func (m *MockSession) Update(arg0 context.Context, arg1 *entity.Session) error {
 	m.ctrl.T.Helper()
@@ -152,3 +356,45 @@ func (mr *MockSessionMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockSession)(nil).Update), arg0, arg1)
 }
+
+// UpdateQueueTrackIndexes mocks base method.
+func (m *MockSession) UpdateQueueTrackIndexes(ctx context.Context, sessionID string, indexes map[int]int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQueueTrackIndexes", ctx, sessionID, indexes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateQueueTrackIndexes indicates an expected call of UpdateQueueTrackIndexes.
+func (mr *MockSessionMockRecorder) UpdateQueueTrackIndexes(ctx, sessionID, indexes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQueueTrackIndexes", reflect.TypeOf((*MockSession)(nil).UpdateQueueTrackIndexes), ctx, sessionID, indexes)
+}
+
+// UpdateQueueTrackPlayback mocks base method.
+func (m *MockSession) UpdateQueueTrackPlayback(ctx context.Context, queueTrack *entity.QueueTrack) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQueueTrackPlayback", ctx, queueTrack)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateQueueTrackPlayback indicates an expected call of UpdateQueueTrackPlayback.
+func (mr *MockSessionMockRecorder) UpdateQueueTrackPlayback(ctx, queueTrack interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQueueTrackPlayback", reflect.TypeOf((*MockSession)(nil).UpdateQueueTrackPlayback), ctx, queueTrack)
+}
+
+// UpdateWithoutActivity mocks base method.
+func (m *MockSession) UpdateWithoutActivity(arg0 context.Context, arg1 *entity.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithoutActivity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWithoutActivity indicates an expected call of UpdateWithoutActivity.
+func (mr *MockSessionMockRecorder) UpdateWithoutActivity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithoutActivity", reflect.TypeOf((*MockSession)(nil).UpdateWithoutActivity), arg0, arg1)
+}
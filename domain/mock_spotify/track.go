@@ -35,6 +35,80 @@ func (m *MockTrackClient) EXPECT() *MockTrackClientMockRecorder {
 	return m.recorder
 }
 
+// AddTracksToPlaylist mocks base method.
+func (m *MockTrackClient) AddTracksToPlaylist(ctx context.Context, playlistID string, trackURIs []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTracksToPlaylist", ctx, playlistID, trackURIs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTracksToPlaylist indicates an expected call of AddTracksToPlaylist.
+func (mr *MockTrackClientMockRecorder) AddTracksToPlaylist(ctx, playlistID, trackURIs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTracksToPlaylist", reflect.TypeOf((*MockTrackClient)(nil).AddTracksToPlaylist), ctx, playlistID, trackURIs)
+}
+
+// CreatePlaylist mocks base method.
+func (m *MockTrackClient) CreatePlaylist(ctx context.Context, spotifyUserID, name, description string, public bool) (*entity.Playlist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlaylist", ctx, spotifyUserID, name, description, public)
+	ret0, _ := ret[0].(*entity.Playlist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePlaylist indicates an expected call of CreatePlaylist.
+func (mr *MockTrackClientMockRecorder) CreatePlaylist(ctx, spotifyUserID, name, description, public interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlaylist", reflect.TypeOf((*MockTrackClient)(nil).CreatePlaylist), ctx, spotifyUserID, name, description, public)
+}
+
+// GetAlbumTrackURIs mocks base method.
+func (m *MockTrackClient) GetAlbumTrackURIs(ctx context.Context, albumID string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlbumTrackURIs", ctx, albumID, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlbumTrackURIs indicates an expected call of GetAlbumTrackURIs.
+func (mr *MockTrackClientMockRecorder) GetAlbumTrackURIs(ctx, albumID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlbumTrackURIs", reflect.TypeOf((*MockTrackClient)(nil).GetAlbumTrackURIs), ctx, albumID, limit)
+}
+
+// GetPlaylistTrackURIs mocks base method.
+func (m *MockTrackClient) GetPlaylistTrackURIs(ctx context.Context, playlistID string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaylistTrackURIs", ctx, playlistID, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaylistTrackURIs indicates an expected call of GetPlaylistTrackURIs.
+func (mr *MockTrackClientMockRecorder) GetPlaylistTrackURIs(ctx, playlistID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaylistTrackURIs", reflect.TypeOf((*MockTrackClient)(nil).GetPlaylistTrackURIs), ctx, playlistID, limit)
+}
+
+// GetRecommendationTrackURIs mocks base method.
+func (m *MockTrackClient) GetRecommendationTrackURIs(ctx context.Context, seedTrackURIs []string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecommendationTrackURIs", ctx, seedTrackURIs, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecommendationTrackURIs indicates an expected call of GetRecommendationTrackURIs.
+func (mr *MockTrackClientMockRecorder) GetRecommendationTrackURIs(ctx, seedTrackURIs, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecommendationTrackURIs", reflect.TypeOf((*MockTrackClient)(nil).GetRecommendationTrackURIs), ctx, seedTrackURIs, limit)
+}
+
 // GetTracksFromURI mocks base method.
 func (m *MockTrackClient) GetTracksFromURI(ctx context.Context, trackURIs []string) ([]*entity.Track, error) {
 	m.ctrl.T.Helper()
@@ -13,6 +13,9 @@ type Auth interface {
 	GetTokenByUserID(userID string) (*oauth2.Token, error)
 	StoreSession(sessionID, userID string) error
 	GetUserIDFromSession(sessionID string) (string, error)
+	StoreGuest(token string, guest *entity.Guest) error
+	FindGuestByToken(token string) (*entity.Guest, error)
+	DeleteExpiredGuests() (int64, error)
 
 	StoreState(authState *entity.AuthState) error
 	FindStateByState(state string) (*entity.AuthState, error)
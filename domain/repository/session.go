@@ -13,10 +13,27 @@ import (
 type Session interface {
 	FindByID(ctx context.Context, id string) (*entity.Session, error)
 	FindByIDForUpdate(ctx context.Context, id string) (*entity.Session, error)
+	FindIDByJoinCode(ctx context.Context, joinCode string) (string, error)
+	FindByUserID(ctx context.Context, userID string, filter entity.SessionListFilter) ([]*entity.SessionWithUser, error)
 	StoreSession(context.Context, *entity.Session) error
 	Update(context.Context, *entity.Session) error
-	StoreQueueTrack(context.Context, *entity.QueueTrackToStore) error
+	UpdateWithoutActivity(context.Context, *entity.Session) error
+	Delete(ctx context.Context, id string) error
+	StoreQueueTrack(ctx context.Context, queueTrack *entity.QueueTrackToStore, index int) error
+	DeleteQueueTrack(ctx context.Context, sessionID string, index int) error
+	MoveQueueTrack(ctx context.Context, sessionID string, from, to int) error
+	UpdateQueueTrackIndexes(ctx context.Context, sessionID string, indexes map[int]int) error
+	UpdateQueueTrackPlayback(ctx context.Context, queueTrack *entity.QueueTrack) error
+	StoreQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) error
+	DeleteQueueTrackVote(ctx context.Context, sessionID string, index int, userID string) (bool, error)
+	StoreSkipVote(ctx context.Context, sessionID string, index int, userID string) error
+	CountSkipVotes(ctx context.Context, sessionID string, index int) (int, error)
+	FindMemberRole(ctx context.Context, sessionID string, userID string) (entity.SessionRole, error)
+	StoreMember(ctx context.Context, member *entity.SessionMember) error
+	IsMember(ctx context.Context, sessionID string, userID string) (bool, error)
+	StoreBan(ctx context.Context, ban *entity.SessionBan) error
+	IsBanned(ctx context.Context, sessionID string, participantID string) (bool, error)
 	FindCreatorTokenBySessionID(context.Context, string) (*oauth2.Token, string, error)
-	ArchiveSessionsForBatch() error
+	ArchiveSessionsForBatch() ([]*entity.ArchivedSession, error)
 	DoInTx(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error)
 }
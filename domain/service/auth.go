@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 
+	"github.com/camphor-/relaym-server/domain/entity"
+
 	"golang.org/x/oauth2"
 )
 
@@ -13,6 +15,7 @@ var (
 	userIDKey    ContextKey = "userIDKey"
 	creatorIDKey ContextKey = "creatorIDKey"
 	tokenKey     ContextKey = "tokenKey"
+	guestKey     ContextKey = "guestKey"
 )
 
 // SetUserIDToContext はユーザIDをContextにセットします。
@@ -36,6 +39,14 @@ func SetTokenToContext(ctx context.Context, token *oauth2.Token) context.Context
 	return context.WithValue(ctx, tokenKey, token)
 }
 
+// SetGuestToContext はゲストをContextにセットします。
+func SetGuestToContext(ctx context.Context, guest *entity.Guest) context.Context {
+	if guest != nil {
+		return context.WithValue(ctx, guestKey, guest)
+	}
+	return ctx
+}
+
 // GetUserIDFromContext はContextからユーザIDを取得します。
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	v := ctx.Value(userIDKey)
@@ -57,6 +68,25 @@ func GetTokenFromContext(ctx context.Context) (*oauth2.Token, bool) {
 	return token, ok
 }
 
+// GetGuestFromContext はContextからゲストを取得します。
+func GetGuestFromContext(ctx context.Context) (*entity.Guest, bool) {
+	v := ctx.Value(guestKey)
+	guest, ok := v.(*entity.Guest)
+	return guest, ok
+}
+
+// GetParticipantIDFromContext はContextからセッションの参加者を識別するIDを取得します。
+// ログインしているユーザの場合はユーザID、ゲストの場合はゲストIDを返し、どちらでもない場合は空文字列を返します。
+func GetParticipantIDFromContext(ctx context.Context) string {
+	if userID, ok := GetUserIDFromContext(ctx); ok && userID != "" {
+		return userID
+	}
+	if guest, ok := GetGuestFromContext(ctx); ok {
+		return guest.ID
+	}
+	return ""
+}
+
 // NewContextFromContext は既存のContextに含まれるトークンなどをコピーした上で、新しいContextを生成します。
 // これは、goroutine内のループなど、HTTPリクエスト終了後も生き残って欲しいContextを作るのに使われます。
 func NewBackgroundContextFromContext(prevCtx context.Context) context.Context {
@@ -73,6 +103,10 @@ func NewBackgroundContextFromContext(prevCtx context.Context) context.Context {
 	if ok {
 		ctx = SetTokenToContext(ctx, token)
 	}
+	guest, ok := GetGuestFromContext(prevCtx)
+	if ok {
+		ctx = SetGuestToContext(ctx, guest)
+	}
 	return ctx
 
 }
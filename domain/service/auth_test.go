@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/camphor-/relaym-server/domain/entity"
+
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"golang.org/x/oauth2"
@@ -123,3 +125,43 @@ func TestGetTokenFromContext(t *testing.T) {
 		})
 	}
 }
+
+func TestGetParticipantIDFromContext(t *testing.T) {
+	t.Parallel()
+
+	guest := &entity.Guest{ID: "guestID", Nickname: "nickname"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{
+			name: "ログインしているユーザはユーザIDを返す",
+			ctx:  SetUserIDToContext(context.Background(), "userID"),
+			want: "userID",
+		},
+		{
+			name: "ゲストはゲストIDを返す",
+			ctx:  SetGuestToContext(context.Background(), guest),
+			want: "guestID",
+		},
+		{
+			name: "ユーザIDとゲストの両方がセットされているときはユーザIDを優先する",
+			ctx:  SetGuestToContext(SetUserIDToContext(context.Background(), "userID"), guest),
+			want: "userID",
+		},
+		{
+			name: "どちらもセットされていないと空文字列を返す",
+			ctx:  context.Background(),
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetParticipantIDFromContext(tt.ctx); got != tt.want {
+				t.Errorf("GetParticipantIDFromContext() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,4 +12,9 @@ import (
 type TrackClient interface {
 	Search(ctx context.Context, q string) ([]*entity.Track, error)
 	GetTracksFromURI(ctx context.Context, trackURIs []string) ([]*entity.Track, error)
+	GetAlbumTrackURIs(ctx context.Context, albumID string, limit int) ([]string, error)
+	GetPlaylistTrackURIs(ctx context.Context, playlistID string, limit int) ([]string, error)
+	GetRecommendationTrackURIs(ctx context.Context, seedTrackURIs []string, limit int) ([]string, error)
+	CreatePlaylist(ctx context.Context, spotifyUserID, name, description string, public bool) (*entity.Playlist, error)
+	AddTracksToPlaylist(ctx context.Context, playlistID string, trackURIs []string) error
 }
@@ -48,11 +48,11 @@ func main() {
 
 	userUC := usecase.NewUserUseCase(spotifyCli, userRepo)
 	authUC := usecase.NewAuthUseCase(spotifyCli, spotifyCli, authRepo, userRepo, sessionRepo)
-	sessionTimerUC := usecase.NewSessionTimerUseCase(sessionRepo, spotifyCli, hub, syncCheckTimerManager)
+	sessionTimerUC := usecase.NewSessionTimerUseCase(sessionRepo, spotifyCli, spotifyCli, hub, syncCheckTimerManager)
 	sessionUC := usecase.NewSessionUseCase(sessionRepo, userRepo, spotifyCli, spotifyCli, spotifyCli, hub, sessionTimerUC)
-	sessionStateUC := usecase.NewSessionStateUseCase(sessionRepo, spotifyCli, hub, sessionTimerUC)
+	sessionStateUC := usecase.NewSessionStateUseCase(sessionRepo, spotifyCli, hub, hub, sessionTimerUC)
 	trackUC := usecase.NewTrackUseCase(spotifyCli)
-	batchUC := usecase.NewBatchUseCase(sessionRepo, hub)
+	batchUC := usecase.NewBatchUseCase(sessionRepo, authRepo, spotifyCli, hub, syncCheckTimerManager)
 
 	s := web.NewServer(authUC, userUC, sessionUC, sessionStateUC, trackUC, batchUC, hub)
 
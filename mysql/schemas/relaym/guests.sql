@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `guests` (
+  `id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT '曲の追加や投票でユーザーIDの代わりに使うゲストのID（不変）',
+  `token` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT 'Cookieに保存してゲストを識別するトークン（不変）',
+  `nickname` VARCHAR(255) NOT NULL COMMENT 'ゲストが指定したニックネーム（不変）',
+  `expires_at` datetime NOT NULL COMMENT 'ゲストの有効期限。過ぎるとトークンが使えなくなり、バッチで削除される（不変）',
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `guests_token_uindex` (`token` ASC) VISIBLE,
+  INDEX `guests_expires_at_index` (`expires_at` ASC) VISIBLE)
+ENGINE = InnoDB;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `queue_track_votes` (
+  `session_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL,
+  `index` INT NOT NULL COMMENT '投票された曲のqueue_tracksでのindex（曲の削除や並び替えに合わせて変化する）',
+  `user_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT '投票したユーザーID（不変）',
+  `voted_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '投票された日時（不変）',
+  PRIMARY KEY (`session_id`, `index`, `user_id`),
+  INDEX `queue_track_votes_user_id_idx` (`user_id` ASC, `session_id` ASC) VISIBLE,
+  CONSTRAINT `queue_track_votes_queue_tracks_fk`
+    FOREIGN KEY (`session_id`, `index`)
+    REFERENCES `queue_tracks` (`session_id`, `index`)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE)
+ENGINE = InnoDB;
@@ -1,8 +1,15 @@
 CREATE TABLE IF NOT EXISTS `queue_tracks` (
-  `index` INT NOT NULL COMMENT 'session内でのindex（0-indexed）（不変）',
+  `index` INT NOT NULL COMMENT 'session内でのindex（0-indexed）（未再生の曲の削除や並び替えで変化する）',
   `uri` VARCHAR(255) NOT NULL COMMENT 'Spotify APIから返ってくるuri（不変）',
   `session_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL,
+  `added_by` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL DEFAULT '' COMMENT '曲を追加したユーザーID（ログインしていないユーザーの場合は空文字列）（不変）',
+  `added_by_name` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '曲を追加した時点でのユーザーの表示名（不変）',
+  `added_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '曲が追加された日時（不変）',
+  `started_at` datetime NULL DEFAULT NULL COMMENT '曲の再生を開始した日時（まだ再生していない場合はNULL）（可変）',
+  `ended_at` datetime NULL DEFAULT NULL COMMENT '曲の再生が終了した日時（まだ終了していない場合はNULL）（可変）',
+  `end_reason` ENUM('FINISHED','SKIPPED','INTERRUPTED') NULL DEFAULT NULL COMMENT '曲の再生が終了した理由（まだ終了していない場合はNULL）（可変）',
   PRIMARY KEY (`session_id`, `index`),
+  INDEX `queue_tracks_added_by_idx` (`added_by` ASC, `session_id` ASC) VISIBLE,
   CONSTRAINT `tracks_session_id_fk`
     FOREIGN KEY (`session_id`)
     REFERENCES `sessions` (`id`)
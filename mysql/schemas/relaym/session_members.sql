@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `session_members` (
+  `session_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL,
+  `user_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT '役割を割り当てられたユーザーID（不変）',
+  `role` ENUM('CO_HOST','LISTENER') NOT NULL COMMENT 'セッションにおける役割。作成者はsessions.creator_idで管理するので含まない（可変）',
+  PRIMARY KEY (`session_id`, `user_id`),
+  INDEX `session_members_user_id_fk_idx` (`user_id` ASC) VISIBLE,
+  CONSTRAINT `session_members_session_id_fk`
+    FOREIGN KEY (`session_id`)
+    REFERENCES `sessions` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE,
+  CONSTRAINT `session_members_user_id_fk`
+    FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE)
+ENGINE = InnoDB;
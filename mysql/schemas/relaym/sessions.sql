@@ -1,15 +1,28 @@
 CREATE TABLE IF NOT EXISTS `sessions` (
   `id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT 'セッションID（不変）',
-  `name` VARCHAR(255) NOT NULL COMMENT 'Sessionの名前（不変）',
+  `name` VARCHAR(255) NOT NULL COMMENT 'Sessionの名前（可変）',
+  `description` VARCHAR(1000) NOT NULL DEFAULT '' COMMENT 'Sessionの説明(設定されていない場合は空文字列)（可変）',
   `creator_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT 'sessionの作成者のユーザーID（不変）',
   `queue_head` INT NOT NULL COMMENT 'プレイヤーにセットされている曲のindex（0-indexed）（可変）',
   `state_type` ENUM('PLAY','PAUSE','STOP','ARCHIVED') NOT NULL,
   `device_id` varchar(255) COLLATE utf8mb4_bin NOT NULL COMMENT '再生する端末のID(存在しない場合は空文字列)',
-  `expired_at` datetime NOT NULL,
+  `expired_at` datetime NOT NULL COMMENT 'expiration_policy_typeがFIXEDの場合にアーカイブされる日時（可変）',
+  `expiration_policy_type` ENUM('FIXED','IDLE','NEVER') NOT NULL DEFAULT 'FIXED' COMMENT 'アーカイブするタイミングの決め方。FIXEDは作成もしくはアーカイブの解除から、IDLEは最後の操作から、NEVERはアーカイブしない（不変）',
+  `expiration_hours` INT NOT NULL DEFAULT '72' COMMENT 'アーカイブするまでの時間。NEVERの場合は0（不変）',
   `allow_to_control_by_others` TINYINT(1) NOT NULL DEFAULT '0',
   `progress_when_paused` INT NOT NULL DEFAULT '0',
+  `needs_spotify_queue_reset` TINYINT(1) NOT NULL DEFAULT '0' COMMENT '一時停止中にSpotifyのキューに追加済みの曲が変更されて、再開するときに積み直す必要があるかどうか（可変）',
+  `queue_order_type` ENUM('INSERTION','FAIR','VOTE') NOT NULL DEFAULT 'INSERTION' COMMENT 'キューの曲の並び順の決め方（可変）',
+  `skip_vote_threshold_type` ENUM('COUNT','RATIO') NOT NULL DEFAULT 'RATIO' COMMENT 'スキップ投票の閾値の種類（可変）',
+  `skip_vote_threshold` DOUBLE NOT NULL DEFAULT '0.5' COMMENT 'スキップ投票の閾値。COUNTの場合は票数、RATIOの場合は接続しているクライアント数に対する割合（可変）',
+  `autoplay` TINYINT(1) NOT NULL DEFAULT '0' COMMENT 'キューの曲が無くなったときにおすすめの曲を自動で追加するかどうか（可変）',
+  `fallback_playlist_uri` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'キューの曲が無くなったときに曲を追加するハウスプレイリストのURI(設定されていない場合は空文字列)（可変）',
+  `last_activity_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最後に曲の追加や再生などの操作が行われた日時（可変）',
+  `join_code` CHAR(6) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NULL DEFAULT NULL COMMENT 'セッションに参加するための短いコード。アーカイブされている場合はNULL（可変）',
   PRIMARY KEY (`id`),
+  UNIQUE INDEX `sessions_join_code_uindex` (`join_code` ASC) VISIBLE,
   INDEX `sessions_user_id_fk_idx` (`creator_id` ASC) VISIBLE,
+  INDEX `sessions_last_activity_at_idx` (`last_activity_at` DESC, `id` DESC) VISIBLE,
   CONSTRAINT `sessions_user_id_fk`
     FOREIGN KEY (`creator_id`)
     REFERENCES `users` (`id`)
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `skip_votes` (
+  `session_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL,
+  `index` INT NOT NULL COMMENT '投票された時点でのheadの曲のindex（不変）',
+  `user_id` VARCHAR(255) CHARACTER SET 'utf8mb4' COLLATE 'utf8mb4_bin' NOT NULL COMMENT '投票したユーザーID（不変）',
+  `voted_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '投票された日時（不変）',
+  PRIMARY KEY (`session_id`, `index`, `user_id`),
+  CONSTRAINT `skip_votes_session_id_fk`
+    FOREIGN KEY (`session_id`)
+    REFERENCES `sessions` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE)
+ENGINE = InnoDB;
@@ -23,7 +23,13 @@ type Client struct {
 func NewClient(cfg *config.Spotify) *Client {
 	auth := spotifyauth.New(
 		spotifyauth.WithRedirectURL(cfg.RedirectURL()),
-		spotifyauth.WithScopes(spotifyauth.ScopeUserReadPrivate, spotifyauth.ScopeUserReadPlaybackState, spotifyauth.ScopeUserModifyPlaybackState),
+		spotifyauth.WithScopes(
+			spotifyauth.ScopeUserReadPrivate,
+			spotifyauth.ScopeUserReadPlaybackState,
+			spotifyauth.ScopeUserModifyPlaybackState,
+			spotifyauth.ScopePlaylistModifyPublic,
+			spotifyauth.ScopePlaylistModifyPrivate,
+		),
 		spotifyauth.WithClientID(cfg.ClientID()),
 		spotifyauth.WithClientSecret(cfg.ClientSecret()))
 	return &Client{auth: auth, cache: cache.New(10*time.Minute, 20*time.Minute)}
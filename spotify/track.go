@@ -3,6 +3,7 @@ package spotify
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
@@ -93,6 +94,145 @@ func (c *Client) GetTracksFromURI(ctx context.Context, trackURIs []string) ([]*e
 	return tracks, nil
 }
 
+// GetAlbumTrackURIs はSpotify APIを通して、与えられたアルバムに含まれる曲のURIを先頭から最大limit曲取得します。
+func (c *Client) GetAlbumTrackURIs(ctx context.Context, albumID string, limit int) ([]string, error) {
+	token, ok := service.GetTokenFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("token not found")
+	}
+	cli := spotify.New(c.auth.Client(ctx, token), spotify.WithAcceptLanguage("ja,en;q=0.9"))
+
+	page, err := cli.GetAlbumTracks(ctx, spotify.ID(albumID), spotify.Limit(50))
+	if err != nil {
+		return nil, fmt.Errorf("get album tracks id=%s: %w", albumID, err)
+	}
+
+	uris := make([]string, 0, limit)
+	for {
+		for _, track := range page.Tracks {
+			if len(uris) >= limit {
+				return uris, nil
+			}
+			uris = append(uris, string(track.URI))
+		}
+
+		if err := cli.NextPage(ctx, page); err != nil {
+			if errors.Is(err, spotify.ErrNoMorePages) {
+				return uris, nil
+			}
+			return nil, fmt.Errorf("get next page of album tracks id=%s: %w", albumID, err)
+		}
+	}
+}
+
+// GetPlaylistTrackURIs はSpotify APIを通して、与えられたプレイリストに含まれる曲のURIを先頭から最大limit曲取得します。
+// ポッドキャストのエピソードやローカルファイルなど、キューに追加できない曲は除外されます。
+func (c *Client) GetPlaylistTrackURIs(ctx context.Context, playlistID string, limit int) ([]string, error) {
+	token, ok := service.GetTokenFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("token not found")
+	}
+	cli := spotify.New(c.auth.Client(ctx, token), spotify.WithAcceptLanguage("ja,en;q=0.9"))
+
+	page, err := cli.GetPlaylistItems(ctx, spotify.ID(playlistID), spotify.Limit(100))
+	if err != nil {
+		return nil, fmt.Errorf("get playlist items id=%s: %w", playlistID, err)
+	}
+
+	uris := make([]string, 0, limit)
+	for {
+		for _, item := range page.Items {
+			if len(uris) >= limit {
+				return uris, nil
+			}
+			if item.IsLocal || item.Track.Track == nil {
+				continue
+			}
+			uris = append(uris, string(item.Track.Track.URI))
+		}
+
+		if err := cli.NextPage(ctx, page); err != nil {
+			if errors.Is(err, spotify.ErrNoMorePages) {
+				return uris, nil
+			}
+			return nil, fmt.Errorf("get next page of playlist items id=%s: %w", playlistID, err)
+		}
+	}
+}
+
+// GetRecommendationTrackURIs はSpotify APIを通して、与えられた曲をシードにしたおすすめの曲のURIを最大limit曲取得します。
+// シードにできる曲は最大5曲までです。
+func (c *Client) GetRecommendationTrackURIs(ctx context.Context, seedTrackURIs []string, limit int) ([]string, error) {
+	token, ok := service.GetTokenFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("token not found")
+	}
+	cli := spotify.New(c.auth.Client(ctx, token), spotify.WithAcceptLanguage("ja,en;q=0.9"))
+
+	seeds := spotify.Seeds{Tracks: make([]spotify.ID, len(seedTrackURIs))}
+	for i, trackURI := range seedTrackURIs {
+		seeds.Tracks[i] = spotify.ID(strings.Replace(trackURI, "spotify:track:", "", 1))
+	}
+
+	recommendations, err := cli.GetRecommendations(ctx, seeds, nil, spotify.Limit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("get recommendations seeds=%v: %w", seedTrackURIs, err)
+	}
+
+	uris := make([]string, len(recommendations.Tracks))
+	for i, track := range recommendations.Tracks {
+		uris[i] = string(track.URI)
+	}
+	return uris, nil
+}
+
+// CreatePlaylist はSpotify APIを通して、与えられたユーザのアカウントに空のプレイリストを作成します。
+func (c *Client) CreatePlaylist(ctx context.Context, spotifyUserID, name, description string, public bool) (*entity.Playlist, error) {
+	token, ok := service.GetTokenFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("token not found")
+	}
+	cli := spotify.New(c.auth.Client(ctx, token))
+
+	playlist, err := cli.CreatePlaylistForUser(ctx, spotifyUserID, name, description, public, false)
+	if err != nil {
+		return nil, fmt.Errorf("create playlist for user id=%s: %w", spotifyUserID, err)
+	}
+	return &entity.Playlist{
+		ID:  playlist.ID.String(),
+		URI: string(playlist.URI),
+		URL: playlist.ExternalURLs["spotify"],
+	}, nil
+}
+
+// AddTracksToPlaylist はSpotify APIを通して、与えられたプレイリストの最後に曲を追加します。
+// 1回のリクエストで追加できる曲数に上限があるので、分割してリクエストします。
+func (c *Client) AddTracksToPlaylist(ctx context.Context, playlistID string, trackURIs []string) error {
+	const maxTracksPerRequest = 100
+
+	token, ok := service.GetTokenFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("token not found")
+	}
+	cli := spotify.New(c.auth.Client(ctx, token))
+
+	for start := 0; start < len(trackURIs); start += maxTracksPerRequest {
+		end := start + maxTracksPerRequest
+		if len(trackURIs) < end {
+			end = len(trackURIs)
+		}
+
+		ids := make([]spotify.ID, 0, end-start)
+		for _, trackURI := range trackURIs[start:end] {
+			ids = append(ids, spotify.ID(strings.Replace(trackURI, "spotify:track:", "", 1)))
+		}
+		if _, err := cli.AddTracksToPlaylist(ctx, spotify.ID(playlistID), ids...); err != nil {
+			return fmt.Errorf("add tracks to playlist id=%s: %w", playlistID, err)
+		}
+	}
+	return nil
+}
+
 func (c *Client) idsToCacheKey(ids []spotify.ID) string {
 	buff := bytes.Buffer{}
 	for _, id := range ids {
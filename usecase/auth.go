@@ -125,6 +125,29 @@ func (u *AuthUseCase) GetUserIDFromSession(sessionID string) (string, error) {
 	return userID, nil
 }
 
+// CreateGuest は指定されたニックネームのゲストを作成し、ゲストを識別するトークンとゲストを返します。
+func (u *AuthUseCase) CreateGuest(nickname string) (string, *entity.Guest, error) {
+	guest, err := entity.NewGuest(nickname)
+	if err != nil {
+		return "", nil, fmt.Errorf("new guest: %w", err)
+	}
+
+	token := uuid.New().String()
+	if err := u.repo.StoreGuest(token, guest); err != nil {
+		return "", nil, fmt.Errorf("store guest guestID=%s: %w", guest.ID, err)
+	}
+	return token, guest, nil
+}
+
+// GetGuestFromToken はトークンから対応するゲストを返します。
+func (u *AuthUseCase) GetGuestFromToken(token string) (*entity.Guest, error) {
+	guest, err := u.repo.FindGuestByToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("find guest by token: %w", err)
+	}
+	return guest, nil
+}
+
 // RefreshAccessToken はリフレッシュトークンを使用してアクセストークンを更新し保存します。
 func (u *AuthUseCase) RefreshAccessToken(userID string, token *oauth2.Token) (*oauth2.Token, error) {
 	if token.Valid() {
@@ -1,30 +1,104 @@
 package usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/event"
 	"github.com/camphor-/relaym-server/domain/repository"
+	"github.com/camphor-/relaym-server/domain/service"
+	"github.com/camphor-/relaym-server/domain/spotify"
+	"github.com/camphor-/relaym-server/log"
 )
 
 // BatchUseCase はセッションに関するユースケースです。
 type BatchUseCase struct {
 	sessionRepo repository.Session
+	authRepo    repository.Auth
+	playerCli   spotify.Player
 	pusher      event.Pusher
+	tm          *entity.SyncCheckTimerManager
 }
 
 // NewBatchUseCase はSessionUseCaseのポインタを生成します。
-func NewBatchUseCase(sessionRepo repository.Session, pusher event.Pusher) *BatchUseCase {
+func NewBatchUseCase(sessionRepo repository.Session, authRepo repository.Auth, playerCli spotify.Player, pusher event.Pusher, tm *entity.SyncCheckTimerManager) *BatchUseCase {
 	return &BatchUseCase{
 		sessionRepo: sessionRepo,
+		authRepo:    authRepo,
+		playerCli:   playerCli,
 		pusher:      pusher,
+		tm:          tm,
 	}
 }
 
-// ArchiveOldSessions は古いSessionのstateをArchivedに変更します
-func (s *BatchUseCase) ArchiveOldSessions() error {
-	if err := s.sessionRepo.ArchiveSessionsForBatch(); err != nil {
-		return fmt.Errorf("call ArchiveSessionsForBatch: %w", err)
+// ArchiveOldSessions は古いSessionのstateをArchivedに変更し、アーカイブしたSessionのIDを返します。
+// アーカイブしたSessionは再生中であれば作成者のトークンで再生を止め、タイマーを止めてからEventArchivedを送ります。
+// 一部のSessionの再生を止められなくても、残りのSessionの処理は続けます。
+func (s *BatchUseCase) ArchiveOldSessions(ctx context.Context) ([]string, error) {
+	archived, err := s.sessionRepo.ArchiveSessionsForBatch()
+	if err != nil {
+		return nil, fmt.Errorf("call ArchiveSessionsForBatch: %w", err)
+	}
+
+	logger := log.New()
+	ids := make([]string, len(archived))
+	for i, a := range archived {
+		ids[i] = a.ID
+		if err := s.stopArchivedSession(ctx, a); err != nil {
+			logger.Errorj(map[string]interface{}{
+				"message":   "archiveOldSessions: failed to stop archived session",
+				"sessionID": a.ID,
+				"error":     err.Error(),
+			})
+		}
+		s.pusher.Push(&event.PushMessage{
+			SessionID: a.ID,
+			Msg:       entity.EventArchived,
+		})
+	}
+	return ids, nil
+}
+
+// DeleteExpiredGuests は期限切れのゲストを削除し、削除した件数を返します。
+func (s *BatchUseCase) DeleteExpiredGuests(ctx context.Context) (int64, error) {
+	n, err := s.authRepo.DeleteExpiredGuests()
+	if err != nil {
+		return 0, fmt.Errorf("call DeleteExpiredGuests: %w", err)
+	}
+	return n, nil
+}
+
+// stopArchivedSession はバッチでアーカイブされたSessionのタイマーと再生を止めます。
+// タイマーはサーバを再起動すると失われるので、再生を止めるかどうかはアーカイブされる前のstateで判断します。
+func (s *BatchUseCase) stopArchivedSession(ctx context.Context, archived *entity.ArchivedSession) error {
+	s.tm.DeleteTimer(archived.ID)
+
+	if archived.PrevStateType != entity.Play && archived.PrevStateType != entity.Pause {
+		return nil
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, archived.ID)
+	if err != nil {
+		return fmt.Errorf("find session id=%s: %w", archived.ID, err)
+	}
+
+	if archived.PrevStateType == entity.Play {
+		token, _, err := s.sessionRepo.FindCreatorTokenBySessionID(ctx, archived.ID)
+		if err != nil {
+			return fmt.Errorf("find creator token session id=%s: %w", archived.ID, err)
+		}
+		if err := s.playerCli.Pause(service.SetTokenToContext(ctx, token), session.DeviceID); err != nil && !errors.Is(err, entity.ErrActiveDeviceNotFound) {
+			return fmt.Errorf("call pause api session id=%s: %w", archived.ID, err)
+		}
+	}
+
+	if qt := session.EndHeadTrack(time.Now().UTC(), entity.QueueTrackInterrupted); qt != nil {
+		if err := s.sessionRepo.UpdateQueueTrackPlayback(ctx, qt); err != nil {
+			return fmt.Errorf("update queue track playback session id=%s: %w", archived.ID, err)
+		}
 	}
 	return nil
 }
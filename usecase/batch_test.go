@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/camphor-/relaym-server/domain/entity"
+	"github.com/camphor-/relaym-server/domain/event"
+	"github.com/camphor-/relaym-server/domain/mock_event"
+	"github.com/camphor-/relaym-server/domain/mock_repository"
+	"github.com/camphor-/relaym-server/domain/mock_spotify"
+	"github.com/camphor-/relaym-server/domain/service"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+)
+
+func TestBatchUseCase_ArchiveOldSessions(t *testing.T) {
+	t.Parallel()
+
+	playingSession := &entity.Session{
+		ID:        "playing_session_id",
+		CreatorID: "creator_id",
+		DeviceID:  "device_id",
+		StateType: entity.Archived,
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:5uQ0vKy2973Y9IUCd1wMEF", SessionID: "playing_session_id", StartedAt: time.Now().Add(-1 * time.Minute)},
+		},
+	}
+	pausedSession := &entity.Session{
+		ID:        "paused_session_id",
+		CreatorID: "creator_id",
+		DeviceID:  "device_id",
+		StateType: entity.Archived,
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:5uQ0vKy2973Y9IUCd1wMEF", SessionID: "paused_session_id", StartedAt: time.Now().Add(-1 * time.Minute)},
+		},
+	}
+	token := &oauth2.Token{AccessToken: "access_token"}
+
+	tests := []struct {
+		name                     string
+		timerSessionID           string
+		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		want                     []string
+		wantErr                  bool
+	}{
+		{
+			name:           "再生中だったセッションは作成者のトークンで再生を止めてタイマーを止め、ARCHIVEDを送る",
+			timerSessionID: "playing_session_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").DoAndReturn(func(ctx context.Context, deviceID string) error {
+					if got, ok := service.GetTokenFromContext(ctx); !ok || got.AccessToken != "access_token" {
+						t.Errorf("Pause() is not called with creator token: %v", got)
+					}
+					return nil
+				})
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{SessionID: "playing_session_id", Msg: entity.EventArchived})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return([]*entity.ArchivedSession{{ID: "playing_session_id", PrevStateType: entity.Play}}, nil)
+				m.EXPECT().FindByID(gomock.Any(), "playing_session_id").Return(playingSession, nil)
+				m.EXPECT().FindCreatorTokenBySessionID(gomock.Any(), "playing_session_id").Return(token, "creator_id", nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackInterrupted}).Return(nil)
+			},
+			want:    []string{"playing_session_id"},
+			wantErr: false,
+		},
+		{
+			name:           "サーバの再起動でタイマーが無くても再生中だったセッションは再生を止める",
+			timerSessionID: "",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").Return(nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{SessionID: "playing_session_id", Msg: entity.EventArchived})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return([]*entity.ArchivedSession{{ID: "playing_session_id", PrevStateType: entity.Play}}, nil)
+				m.EXPECT().FindByID(gomock.Any(), "playing_session_id").Return(playingSession, nil)
+				m.EXPECT().FindCreatorTokenBySessionID(gomock.Any(), "playing_session_id").Return(token, "creator_id", nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackInterrupted}).Return(nil)
+			},
+			want:    []string{"playing_session_id"},
+			wantErr: false,
+		},
+		{
+			name:                "一時停止中だったセッションは再生を止めずに曲を中断したものとして記録する",
+			timerSessionID:      "",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{SessionID: "paused_session_id", Msg: entity.EventArchived})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return([]*entity.ArchivedSession{{ID: "paused_session_id", PrevStateType: entity.Pause}}, nil)
+				m.EXPECT().FindByID(gomock.Any(), "paused_session_id").Return(pausedSession, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackInterrupted}).Return(nil)
+			},
+			want:    []string{"paused_session_id"},
+			wantErr: false,
+		},
+		{
+			name:                "停止中だったセッションは再生を止めずにARCHIVEDを送る",
+			timerSessionID:      "",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{SessionID: "stopped_session_id", Msg: entity.EventArchived})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return([]*entity.ArchivedSession{{ID: "stopped_session_id", PrevStateType: entity.Stop}}, nil)
+			},
+			want:    []string{"stopped_session_id"},
+			wantErr: false,
+		},
+		{
+			name:           "再生を止められなくても他のセッションの処理を続けてARCHIVEDを送る",
+			timerSessionID: "playing_session_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").Return(errors.New("unknown error"))
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{SessionID: "playing_session_id", Msg: entity.EventArchived})
+				m.EXPECT().Push(&event.PushMessage{SessionID: "stopped_session_id", Msg: entity.EventArchived})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return([]*entity.ArchivedSession{
+					{ID: "playing_session_id", PrevStateType: entity.Play},
+					{ID: "stopped_session_id", PrevStateType: entity.Stop},
+				}, nil)
+				m.EXPECT().FindByID(gomock.Any(), "playing_session_id").Return(playingSession, nil)
+				m.EXPECT().FindCreatorTokenBySessionID(gomock.Any(), "playing_session_id").Return(token, "creator_id", nil)
+			},
+			want:    []string{"playing_session_id", "stopped_session_id"},
+			wantErr: false,
+		},
+		{
+			name:                "アーカイブに失敗するとエラー",
+			timerSessionID:      "",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().ArchiveSessionsForBatch().Return(nil, errors.New("unknown error"))
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPlayer := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerFn(mockPlayer)
+			mockPusher := mock_event.NewMockPusher(ctrl)
+			tt.prepareMockPusherFn(mockPusher)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			syncCheckTimerManager := entity.NewSyncCheckTimerManager()
+			if tt.timerSessionID != "" {
+				timer := syncCheckTimerManager.CreateExpiredTimer(tt.timerSessionID)
+				timer.SetDuration(5 * time.Minute)
+			}
+
+			uc := NewBatchUseCase(mockSessionRepo, nil, mockPlayer, mockPusher, syncCheckTimerManager)
+			got, err := uc.ArchiveOldSessions(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ArchiveOldSessions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !cmp.Equal(got, tt.want) {
+				t.Errorf("ArchiveOldSessions() diff = %v", cmp.Diff(tt.want, got))
+			}
+			if tt.timerSessionID != "" {
+				if _, exists := syncCheckTimerManager.GetTimer(tt.timerSessionID); exists {
+					t.Errorf("ArchiveOldSessions() timer of session %s is not deleted", tt.timerSessionID)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchUseCase_DeleteExpiredGuests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		prepareMockAuthRepoFn func(m *mock_repository.MockAuth)
+		want                  int64
+		wantErr               bool
+	}{
+		{
+			name: "期限切れのゲストを削除して件数を返す",
+			prepareMockAuthRepoFn: func(m *mock_repository.MockAuth) {
+				m.EXPECT().DeleteExpiredGuests().Return(int64(3), nil)
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "削除に失敗するとエラー",
+			prepareMockAuthRepoFn: func(m *mock_repository.MockAuth) {
+				m.EXPECT().DeleteExpiredGuests().Return(int64(0), errors.New("unknown error"))
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthRepo := mock_repository.NewMockAuth(ctrl)
+			tt.prepareMockAuthRepoFn(mockAuthRepo)
+
+			uc := NewBatchUseCase(nil, mockAuthRepo, nil, nil, nil)
+			got, err := uc.DeleteExpiredGuests(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteExpiredGuests() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DeleteExpiredGuests() = %d, want = %d", got, tt.want)
+			}
+		})
+	}
+}
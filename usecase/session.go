@@ -2,12 +2,16 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/event"
 	"github.com/camphor-/relaym-server/domain/repository"
+	"github.com/camphor-/relaym-server/domain/service"
 	"github.com/camphor-/relaym-server/domain/spotify"
+	"github.com/camphor-/relaym-server/log"
 )
 
 // SessionUseCase はセッションに関するユースケースです。
@@ -34,54 +38,501 @@ func NewSessionUseCase(sessionRepo repository.Session, userRepo repository.User,
 	}
 }
 
-// EnqueueTrack はセッションのqueueにTrackを追加します。
-func (s *SessionUseCase) EnqueueTrack(ctx context.Context, sessionID string, trackURI string) error {
-	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+// EnqueueTracks はセッションのqueueの指定された位置にTrackを追加します。
+// アルバムやプレイリストのURIが渡された場合は、含まれる曲に展開して追加します。
+// 一度に追加できる曲数はlimitまでで、超える場合はどの曲も追加しません。
+func (s *SessionUseCase) EnqueueTracks(ctx context.Context, sessionID string, uris []string, position entity.EnqueuePosition, limit int) error {
+	trackURIs, err := s.expandToTrackURIs(ctx, uris, limit)
 	if err != nil {
-		return fmt.Errorf("FindByID sessionID=%s: %w", sessionID, err)
+		return fmt.Errorf("expand to track uris: %w", err)
 	}
 
-	err = s.sessionRepo.StoreQueueTrack(ctx, &entity.QueueTrackToStore{
-		URI:       trackURI,
+	addedBy, addedByName, err := s.queueTrackAdder(ctx)
+	if err != nil {
+		return fmt.Errorf("get queue track adder: %w", err)
+	}
+
+	addedAt := time.Now().UTC()
+	queueTracks := make([]*entity.QueueTrackToStore, len(trackURIs))
+	for i, trackURI := range trackURIs {
+		queueTracks[i] = &entity.QueueTrackToStore{
+			URI:         trackURI,
+			SessionID:   sessionID,
+			AddedBy:     addedBy,
+			AddedByName: addedByName,
+			AddedAt:     addedAt,
+		}
+	}
+
+	if _, err := s.sessionRepo.DoInTx(ctx, s.enqueueTracksTx(sessionID, queueTracks, position)); err != nil {
+		return fmt.Errorf("enqueue tracks transaction: %w", err)
+	}
+
+	s.pusher.Push(&event.PushMessage{
 		SessionID: sessionID,
+		Msg:       entity.EventAddTrack,
 	})
+
+	return nil
+}
+
+func (s *SessionUseCase) enqueueTracksTx(sessionID string, queueTracks []*entity.QueueTrackToStore, position entity.EnqueuePosition) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		session, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("FindByIDForUpdate sessionID=%s: %w", sessionID, err)
+		}
+
+		if err := ensureNotBanned(ctx, s.sessionRepo, session, service.GetParticipantIDFromContext(ctx)); err != nil {
+			return nil, fmt.Errorf("ensure not banned: %w", err)
+		}
+
+		index := session.EnqueueIndex(position)
+		shouldResetSpotifyQueue := false
+		for _, queueTrack := range queueTracks {
+			err = s.sessionRepo.StoreQueueTrack(ctx, queueTrack, index)
+			if err != nil {
+				return nil, fmt.Errorf("StoreQueueTrack URI=%s, sessionID=%s: %w", queueTrack.URI, sessionID, err)
+			}
+
+			if index < len(session.QueueTracks) {
+				// 既にSpotifyのキューに積まれている曲より前に割り込む場合は、Spotify側のキューも積み直す必要がある
+				shouldResetSpotifyQueue = shouldResetSpotifyQueue || session.IsEnqueuedToSpotify(index)
+				session.InsertQueueTrack(index, queueTrack)
+				index++
+				continue
+			}
+
+			if session.ShouldCallEnqueueAPINow() {
+				err = s.playerCli.Enqueue(ctx, queueTrack.URI, session.DeviceID)
+				if err != nil {
+					return nil, fmt.Errorf("Enqueue URI=%s, sessionID=%s: %w", queueTrack.URI, sessionID, err)
+				}
+			}
+			session.AppendQueueTrack(queueTrack)
+			index++
+		}
+
+		if indexes := session.ReorderQueueTracks(); len(indexes) > 0 {
+			if err := s.sessionRepo.UpdateQueueTrackIndexes(ctx, sessionID, indexes); err != nil {
+				return nil, fmt.Errorf("UpdateQueueTrackIndexes sessionID=%s: %w", sessionID, err)
+			}
+		}
+
+		if shouldResetSpotifyQueue {
+			if err := s.requestSpotifyQueueReset(ctx, session); err != nil {
+				return nil, fmt.Errorf("request spotify queue reset session id=%s: %w", sessionID, err)
+			}
+		}
+		return nil, nil
+	}
+}
+
+// queueTrackAdder は曲を追加しようとしているユーザのIDと表示名を返します。
+// ゲストの場合はゲストIDとニックネームを返します。
+// ログインもゲストの登録もしていないユーザの場合はIDが空文字列になり、表示名はゲスト用のものになります。
+func (s *SessionUseCase) queueTrackAdder(ctx context.Context) (string, string, error) {
+	userID, _ := service.GetUserIDFromContext(ctx)
+	if userID == "" {
+		if guest, ok := service.GetGuestFromContext(ctx); ok {
+			return guest.ID, guest.Nickname, nil
+		}
+		return "", entity.GuestDisplayName, nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", "", fmt.Errorf("FindByID userID=%s: %w", userID, err)
+	}
+	return user.ID, user.DisplayName, nil
+}
+
+// authorizeControl は参加者がセッションの再生やキューを操作できるかどうかを、追放されているかどうかとセッションにおける役割をもとに確認します。
+// 作成者の場合と、追放されていない参加者で他人による操作が許可されている場合は、役割を取得するまでもなく操作できます。
+func authorizeControl(ctx context.Context, sessionRepo repository.Session, sess *entity.Session, participantID string) error {
+	if sess.IsCreator(participantID) {
+		return nil
+	}
+	if err := ensureNotBanned(ctx, sessionRepo, sess, participantID); err != nil {
+		return err
+	}
+	if sess.AllowToControlByOthers {
+		return nil
+	}
+	// ログインもゲストの登録もしていないユーザには役割を割り当てられないので、常にリスナーになる
+	if participantID == "" {
+		return fmt.Errorf("not allowed to control session: %w", entity.ErrSessionNotAllowToControlOthers)
+	}
+
+	role, err := sessionRepo.FindMemberRole(ctx, sess.ID, participantID)
+	if err != nil {
+		return fmt.Errorf("find member role session id=%s participant id=%s: %w", sess.ID, participantID, err)
+	}
+	if !sess.CanBeControlledBy(role) {
+		return fmt.Errorf("not allowed to control session role=%s: %w", role, entity.ErrSessionNotAllowToControlOthers)
+	}
+	return nil
+}
+
+// authorizeClone はユーザがセッションを複製できるかどうか確認します。
+// 作成者と役割を割り当てられたメンバーのみ複製でき、追放されたユーザは複製できません。
+func authorizeClone(ctx context.Context, sessionRepo repository.Session, sess *entity.Session, userID string) error {
+	if sess.IsCreator(userID) {
+		return nil
+	}
+	if err := ensureNotBanned(ctx, sessionRepo, sess, userID); err != nil {
+		return err
+	}
+
+	isMember, err := sessionRepo.IsMember(ctx, sess.ID, userID)
 	if err != nil {
-		return fmt.Errorf("StoreQueueTrack URI=%s, sessionID=%s: %w", trackURI, sessionID, err)
+		return fmt.Errorf("find member session id=%s user id=%s: %w", sess.ID, userID, err)
+	}
+	if !isMember {
+		return fmt.Errorf("user id=%s: %w", userID, entity.ErrUserIsNotSessionMember)
 	}
+	return nil
+}
 
-	if session.ShouldCallEnqueueAPINow() {
-		err = s.playerCli.Enqueue(ctx, trackURI, session.DeviceID)
+// ensureNotBanned は参加者がセッションから追放されていないことを確認します。
+// 作成者は追放できず、ログインもゲストの登録もしていないユーザは識別できないので、どちらも確認しません。
+func ensureNotBanned(ctx context.Context, sessionRepo repository.Session, sess *entity.Session, participantID string) error {
+	if participantID == "" || sess.IsCreator(participantID) {
+		return nil
+	}
+
+	banned, err := sessionRepo.IsBanned(ctx, sess.ID, participantID)
+	if err != nil {
+		return fmt.Errorf("find ban session id=%s participant id=%s: %w", sess.ID, participantID, err)
+	}
+	if banned {
+		return fmt.Errorf("participant id=%s: %w", participantID, entity.ErrParticipantBanned)
+	}
+	return nil
+}
+
+// expandToTrackURIs はアルバムやプレイリストのURIを含まれる曲のURIに展開し、Track URIを返します。
+// 共有リンクや曲のIDのみが渡された場合はURIに変換し、直接指定された曲がSpotifyに存在するかどうかも確認します。
+// 展開した曲数がlimitを超える場合は、一部の曲だけを追加せずにErrTooManyTracksToEnqueueを返します。
+func (s *SessionUseCase) expandToTrackURIs(ctx context.Context, uris []string, limit int) ([]string, error) {
+	trackURIs := make([]string, 0, len(uris))
+	var specifiedTrackURIs []string
+	for _, input := range uris {
+		uri, err := entity.NormalizeSpotifyURI(input)
+		if err != nil {
+			return nil, fmt.Errorf("normalize uri: %w", err)
+		}
+		uriType, id, err := entity.ParseSpotifyURI(uri)
 		if err != nil {
-			return fmt.Errorf("Enqueue URI=%s, sessionID=%s: %w", trackURI, sessionID, err)
+			return nil, fmt.Errorf("parse uri: %w", err)
 		}
+
+		remain := limit - len(trackURIs)
+		if remain <= 0 {
+			return nil, fmt.Errorf("limit=%d: %w", limit, entity.ErrTooManyTracksToEnqueue)
+		}
+
+		// 上限を超えるかどうか判定するために、残りの曲数より1曲多く取得する
+		switch uriType {
+		case entity.SpotifyURITypeTrack:
+			trackURIs = append(trackURIs, uri)
+			specifiedTrackURIs = append(specifiedTrackURIs, uri)
+		case entity.SpotifyURITypeAlbum:
+			albumTrackURIs, err := s.trackCli.GetAlbumTrackURIs(ctx, id, remain+1)
+			if err != nil {
+				return nil, fmt.Errorf("get album track uris id=%s: %w", id, err)
+			}
+			trackURIs = append(trackURIs, albumTrackURIs...)
+		case entity.SpotifyURITypePlaylist:
+			playlistTrackURIs, err := s.trackCli.GetPlaylistTrackURIs(ctx, id, remain+1)
+			if err != nil {
+				return nil, fmt.Errorf("get playlist track uris id=%s: %w", id, err)
+			}
+			trackURIs = append(trackURIs, playlistTrackURIs...)
+		}
+
+		if len(trackURIs) > limit {
+			return nil, fmt.Errorf("limit=%d: %w", limit, entity.ErrTooManyTracksToEnqueue)
+		}
+	}
+
+	if err := s.validateTrackURIs(ctx, specifiedTrackURIs); err != nil {
+		return nil, fmt.Errorf("validate track uris: %w", err)
+	}
+	return trackURIs, nil
+}
+
+// validateTrackURIs は全ての曲がSpotifyに存在するかどうかを確認します。
+// Spotify APIは存在しない曲のIDに対してnullを返すので、その場合はErrTrackNotFoundを返します。
+func (s *SessionUseCase) validateTrackURIs(ctx context.Context, trackURIs []string) error {
+	if len(trackURIs) == 0 {
+		return nil
+	}
+
+	tracks, err := s.trackCli.GetTracksFromURI(ctx, trackURIs)
+	if err != nil {
+		return fmt.Errorf("get tracks from uri: %w", err)
+	}
+	for i, track := range tracks {
+		if track == nil {
+			return fmt.Errorf("uri=%s: %w", trackURIs[i], entity.ErrTrackNotFound)
+		}
+	}
+	return nil
+}
+
+// RemoveQueueTrack はセッションのqueueからまだ再生されていない曲を削除します。
+func (s *SessionUseCase) RemoveQueueTrack(ctx context.Context, sessionID string, index int) error {
+	if _, err := s.sessionRepo.DoInTx(ctx, s.removeQueueTrackTx(sessionID, index)); err != nil {
+		return fmt.Errorf("remove queue track transaction: %w", err)
 	}
+
 	s.pusher.Push(&event.PushMessage{
 		SessionID: sessionID,
-		Msg:       entity.EventAddTrack,
+		Msg:       entity.EventQueueChanged,
 	})
+	return nil
+}
 
+func (s *SessionUseCase) removeQueueTrackTx(sessionID string, index int) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID := service.GetParticipantIDFromContext(ctx)
+		if err := authorizeControl(ctx, s.sessionRepo, sess, userID); err != nil {
+			return nil, fmt.Errorf("authorize to control queue: %w", err)
+		}
+
+		// 削除する曲が既にSpotifyのキューに積まれている場合は、Spotify側のキューも積み直す必要がある
+		shouldResetSpotifyQueue := sess.IsEnqueuedToSpotify(index)
+
+		if err := sess.RemoveQueueTrack(index); err != nil {
+			return nil, fmt.Errorf("remove queue track index=%d: %w", index, err)
+		}
+
+		if err := s.sessionRepo.DeleteQueueTrack(ctx, sessionID, index); err != nil {
+			return nil, fmt.Errorf("delete queue track session id=%s index=%d: %w", sessionID, index, err)
+		}
+
+		if shouldResetSpotifyQueue {
+			if err := s.requestSpotifyQueueReset(ctx, sess); err != nil {
+				return nil, fmt.Errorf("request spotify queue reset session id=%s: %w", sessionID, err)
+			}
+		}
+		return nil, nil
+	}
+}
+
+// MoveQueueTrack はセッションのqueueのまだ再生されていない曲を指定した位置に移動します。
+func (s *SessionUseCase) MoveQueueTrack(ctx context.Context, sessionID string, from, to int) error {
+	if _, err := s.sessionRepo.DoInTx(ctx, s.moveQueueTrackTx(sessionID, from, to)); err != nil {
+		return fmt.Errorf("move queue track transaction: %w", err)
+	}
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID: sessionID,
+		Msg:       entity.EventQueueChanged,
+	})
 	return nil
 }
 
-// CreateSession は与えられたセッション名のセッションを作成します。
-func (s *SessionUseCase) CreateSession(ctx context.Context, sessionName string, creatorID string, allowToControlByOthers bool) (*entity.SessionWithUser, error) {
+func (s *SessionUseCase) moveQueueTrackTx(sessionID string, from, to int) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID := service.GetParticipantIDFromContext(ctx)
+		if err := authorizeControl(ctx, s.sessionRepo, sess, userID); err != nil {
+			return nil, fmt.Errorf("authorize to control queue: %w", err)
+		}
+
+		// 移動元か移動先のどちらかが既にSpotifyのキューに積まれている範囲にある場合は、Spotify側のキューも積み直す必要がある
+		shouldResetSpotifyQueue := sess.IsEnqueuedToSpotify(from) || sess.IsEnqueuedToSpotify(to)
+
+		if err := sess.MoveQueueTrack(from, to); err != nil {
+			return nil, fmt.Errorf("move queue track from=%d to=%d: %w", from, to, err)
+		}
+
+		if from == to {
+			return nil, nil
+		}
+
+		if err := s.sessionRepo.MoveQueueTrack(ctx, sessionID, from, to); err != nil {
+			return nil, fmt.Errorf("move queue track session id=%s from=%d to=%d: %w", sessionID, from, to, err)
+		}
+
+		if shouldResetSpotifyQueue {
+			if err := s.requestSpotifyQueueReset(ctx, sess); err != nil {
+				return nil, fmt.Errorf("request spotify queue reset session id=%s: %w", sessionID, err)
+			}
+		}
+		return nil, nil
+	}
+}
+
+// ToggleQueueTrackVote はセッションのqueueのまだ再生されていない曲に対するユーザの投票を切り替えます。
+// まだ投票していない場合は投票し、既に投票している場合は投票を取り消します。
+// 投票したかどうかと、投票数と並び替えを反映した後の曲を返します。
+func (s *SessionUseCase) ToggleQueueTrackVote(ctx context.Context, sessionID string, index int) (bool, *entity.QueueTrack, error) {
+	userID := service.GetParticipantIDFromContext(ctx)
+
+	v, err := s.sessionRepo.DoInTx(ctx, s.toggleQueueTrackVoteTx(sessionID, index, userID))
+	if err != nil {
+		return false, nil, fmt.Errorf("toggle queue track vote transaction: %w", err)
+	}
+	res := v.(*toggleQueueTrackVoteResponse)
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID: sessionID,
+		Msg:       entity.EventQueueChanged,
+	})
+	return res.voted, res.queueTrack, nil
+}
+
+func (s *SessionUseCase) toggleQueueTrackVoteTx(sessionID string, index int, userID string) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		if err := ensureNotBanned(ctx, s.sessionRepo, sess, userID); err != nil {
+			return nil, fmt.Errorf("toggle queue track vote: %w", err)
+		}
+
+		deleted, err := s.sessionRepo.DeleteQueueTrackVote(ctx, sessionID, index, userID)
+		if err != nil {
+			return nil, fmt.Errorf("delete queue track vote index=%d: %w", index, err)
+		}
+
+		if deleted {
+			if err := sess.RemoveQueueTrackVote(index); err != nil {
+				return nil, fmt.Errorf("remove queue track vote: %w", err)
+			}
+		} else {
+			if err := sess.AddQueueTrackVote(index); err != nil {
+				return nil, fmt.Errorf("add queue track vote: %w", err)
+			}
+			if err := s.sessionRepo.StoreQueueTrackVote(ctx, sessionID, index, userID); err != nil {
+				return nil, fmt.Errorf("store queue track vote index=%d: %w", index, err)
+			}
+		}
+
+		// 並び替えでindexが変わるので、先に曲を取得しておく
+		queueTrack := sess.QueueTracks[index]
+		if indexes := sess.ReorderQueueTracks(); len(indexes) > 0 {
+			if err := s.sessionRepo.UpdateQueueTrackIndexes(ctx, sessionID, indexes); err != nil {
+				return nil, fmt.Errorf("UpdateQueueTrackIndexes sessionID=%s: %w", sessionID, err)
+			}
+		}
+		return &toggleQueueTrackVoteResponse{voted: !deleted, queueTrack: queueTrack}, nil
+	}
+}
+
+type toggleQueueTrackVoteResponse struct {
+	voted      bool
+	queueTrack *entity.QueueTrack
+}
+
+// requestSpotifyQueueReset はSpotifyのキューをセッションのキューと一致するように積み直す必要があることを記録します。
+// Spotifyのキューに追加済みの曲(head+1, head+2)が変わるときだけ呼び出してください。それより後ろの曲はまだSpotifyに追加されていません。
+// 積み直すと再生中の曲が途切れてしまうので、ここではSpotifyを操作せず、次の曲に進むときか一時停止から再開するときに積み直します。
+func (s *SessionUseCase) requestSpotifyQueueReset(ctx context.Context, sess *entity.Session) error {
+	sess.NeedsSpotifyQueueReset = true
+	if err := s.sessionRepo.Update(ctx, sess); err != nil {
+		return fmt.Errorf("update session id=%s: %w", sess.ID, err)
+	}
+	return nil
+}
+
+// rebuildSpotifyQueue はSpotifyのキューを空にしてから、headの曲をpositionから再生し直して後続の曲を追加します。
+// Spotifyのキューから特定の曲を取り除くAPIは存在しないので、キューを積み直すにはこの方法しかありません。
+func rebuildSpotifyQueue(ctx context.Context, playerCli spotify.Player, sess *entity.Session, position time.Duration) error {
+	trackURIs := sess.TrackURIsToSyncWithSpotify()
+	if err := playerCli.DeleteAllTracksInQueue(ctx, sess.DeviceID, trackURIs[0]); err != nil {
+		return fmt.Errorf("call DeleteAllTracksInQueue: %w", err)
+	}
+	if err := playerCli.PlayWithTracksAndPosition(ctx, sess.DeviceID, trackURIs[:1], position); err != nil {
+		return fmt.Errorf("call play api with tracks %v: %w", trackURIs[:1], err)
+	}
+	for _, trackURI := range trackURIs[1:] {
+		if err := playerCli.Enqueue(ctx, trackURI, sess.DeviceID); err != nil {
+			return fmt.Errorf("call add queue api trackURI=%s: %w", trackURI, err)
+		}
+	}
+	return nil
+}
+
+// CreateSession は与えられた設定のセッションを作成します。
+func (s *SessionUseCase) CreateSession(ctx context.Context, creatorID string, settings entity.SessionSettings, expirationPolicy entity.ExpirationPolicy) (*entity.SessionWithUser, error) {
 	creator, err := s.userRepo.FindByID(creatorID)
 	if err != nil {
 		return nil, fmt.Errorf("FindByID userID=%s: %w", creatorID, err)
 	}
 
-	newSession, err := entity.NewSession(sessionName, creatorID, allowToControlByOthers)
+	newSession, err := entity.NewSession(creatorID, settings, expirationPolicy)
 	if err != nil {
-		return nil, fmt.Errorf("NewSession sessionName=%s: %w", sessionName, err)
+		return nil, fmt.Errorf("NewSession creatorID=%s: %w", creatorID, err)
 	}
 
 	err = s.sessionRepo.StoreSession(ctx, newSession)
 	if err != nil {
-		return nil, fmt.Errorf("StoreSession sessionName=%s: %w", sessionName, err)
+		return nil, fmt.Errorf("StoreSession sessionName=%s: %w", newSession.Name, err)
 	}
 	return entity.NewSessionWithUser(newSession, creator), nil
 }
 
+// CloneSession は指定されたセッションを複製して、creatorIDのユーザが作成した新しいセッションを作成します。
+// 元のセッションの作成者と役割を割り当てられたメンバーのみ複製できます。
+// 新しいセッションと引き継ぐキューの曲は同じトランザクションで保存します。
+func (s *SessionUseCase) CloneSession(ctx context.Context, sessionID string, creatorID string, opt entity.SessionCloneOption) (*entity.SessionWithUser, error) {
+	creator, err := s.userRepo.FindByID(creatorID)
+	if err != nil {
+		return nil, fmt.Errorf("FindByID userID=%s: %w", creatorID, err)
+	}
+
+	v, err := s.sessionRepo.DoInTx(ctx, s.cloneSessionTx(sessionID, creatorID, opt))
+	if err != nil {
+		return nil, fmt.Errorf("clone session transaction id=%s: %w", sessionID, err)
+	}
+	cloned := v.(*entity.Session)
+	return entity.NewSessionWithUser(cloned, creator), nil
+}
+
+func (s *SessionUseCase) cloneSessionTx(sessionID string, creatorID string, opt entity.SessionCloneOption) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		if err := authorizeClone(ctx, s.sessionRepo, sess, creatorID); err != nil {
+			return nil, fmt.Errorf("authorize to clone session: %w", err)
+		}
+
+		cloned, queueTracks, err := sess.Clone(creatorID, opt, time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("clone session id=%s: %w", sessionID, err)
+		}
+
+		if err := s.sessionRepo.StoreSession(ctx, cloned); err != nil {
+			return nil, fmt.Errorf("StoreSession sessionName=%s: %w", cloned.Name, err)
+		}
+
+		for i, queueTrack := range queueTracks {
+			if err := s.sessionRepo.StoreQueueTrack(ctx, queueTrack, i); err != nil {
+				return nil, fmt.Errorf("StoreQueueTrack URI=%s, sessionID=%s: %w", queueTrack.URI, cloned.ID, err)
+			}
+		}
+		return cloned, nil
+	}
+}
+
 // CanConnectToPusher はイベントをクライアントにプッシュするためのコネクションを貼れるかどうかチェックします。
 func (s *SessionUseCase) CanConnectToPusher(ctx context.Context, sessionID string) error {
 	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
@@ -89,6 +540,10 @@ func (s *SessionUseCase) CanConnectToPusher(ctx context.Context, sessionID strin
 		return fmt.Errorf("find session id=%s: %w", sessionID, err)
 	}
 
+	if err := ensureNotBanned(ctx, s.sessionRepo, sess, service.GetParticipantIDFromContext(ctx)); err != nil {
+		return fmt.Errorf("ensure not banned: %w", err)
+	}
+
 	// セッションが再生中なのに同期チェックがされていなかったら始める
 	// サーバ再起動でタイマーがなくなると、イベントが正しくクライアントに送られなくなるのでこのタイミングで復旧させる。
 	if exists := s.timerUC.existsTimer(sessionID); !exists && sess.IsPlaying() {
@@ -99,6 +554,25 @@ func (s *SessionUseCase) CanConnectToPusher(ctx context.Context, sessionID strin
 	return nil
 }
 
+// IdentifyListener はWebSocketで接続するユーザをListenerとして識別します。
+// ゲストはゲストIDとニックネームで識別します。
+// ログインもゲストの登録もしていないユーザは接続をまたいで識別できず、スキップの投票もできないため、nilを返してListenerとして扱いません。
+func (s *SessionUseCase) IdentifyListener(ctx context.Context) (*entity.Listener, error) {
+	userID, ok := service.GetUserIDFromContext(ctx)
+	if !ok || userID == "" {
+		if guest, ok := service.GetGuestFromContext(ctx); ok {
+			return entity.NewGuestListener(guest.ID, guest.Nickname), nil
+		}
+		return nil, nil
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("find user id=%s: %w", userID, err)
+	}
+	return entity.NewUserListener(user), nil
+}
+
 // SetDevice は指定されたidのセッションの作成者と再生する端末を紐付けて再生するデバイスを指定します。
 func (s *SessionUseCase) SetDevice(ctx context.Context, sessionID string, deviceID string) error {
 	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
@@ -106,6 +580,11 @@ func (s *SessionUseCase) SetDevice(ctx context.Context, sessionID string, device
 		return fmt.Errorf("find session id=%s: %w", sessionID, err)
 	}
 
+	userID := service.GetParticipantIDFromContext(ctx)
+	if err := authorizeControl(ctx, s.sessionRepo, sess, userID); err != nil {
+		return fmt.Errorf("authorize to set device: %w", err)
+	}
+
 	sess.DeviceID = deviceID
 	if err := s.sessionRepo.Update(ctx, sess); err != nil {
 		return fmt.Errorf("update device id: device_id=%s session_id=%s: %w", deviceID, sess.ID, err)
@@ -114,6 +593,262 @@ func (s *SessionUseCase) SetDevice(ctx context.Context, sessionID string, device
 	return nil
 }
 
+// SetFallbackPlaylist はキューの曲が無くなったときに曲を追加するハウスプレイリストを設定します。
+// 空文字列を指定した場合はハウスプレイリストの設定を解除します。セッションの作成者のみ設定できます。
+func (s *SessionUseCase) SetFallbackPlaylist(ctx context.Context, sessionID string, playlistURI string) error {
+	if _, err := s.sessionRepo.DoInTx(ctx, s.setFallbackPlaylistTx(sessionID, playlistURI)); err != nil {
+		return fmt.Errorf("set fallback playlist transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *SessionUseCase) setFallbackPlaylistTx(sessionID string, playlistURI string) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID, _ := service.GetUserIDFromContext(ctx)
+		if !sess.IsCreator(userID) {
+			return nil, fmt.Errorf("set fallback playlist user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+		}
+
+		sess.FallbackPlaylistURI = playlistURI
+		if err := s.sessionRepo.Update(ctx, sess); err != nil {
+			return nil, fmt.Errorf("update fallback playlist uri=%s session id=%s: %w", playlistURI, sess.ID, err)
+		}
+		return nil, nil
+	}
+}
+
+// UpdateSettings はセッションの名前や説明、他人による操作の許可などの設定を変更します。
+// セッションの作成者のみ変更でき、変更後は接続しているクライアントに設定が変更されたことを通知します。
+func (s *SessionUseCase) UpdateSettings(ctx context.Context, sessionID string, settings entity.SessionSettings) error {
+	if _, err := s.sessionRepo.DoInTx(ctx, s.updateSettingsTx(sessionID, settings)); err != nil {
+		return fmt.Errorf("update settings transaction: %w", err)
+	}
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID: sessionID,
+		Msg:       entity.EventSettingsChanged,
+	})
+	return nil
+}
+
+func (s *SessionUseCase) updateSettingsTx(sessionID string, settings entity.SessionSettings) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID, _ := service.GetUserIDFromContext(ctx)
+		if !sess.IsCreator(userID) {
+			return nil, fmt.Errorf("update settings user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+		}
+
+		if indexes := sess.UpdateSettings(settings); len(indexes) > 0 {
+			if err := s.sessionRepo.UpdateQueueTrackIndexes(ctx, sessionID, indexes); err != nil {
+				return nil, fmt.Errorf("UpdateQueueTrackIndexes sessionID=%s: %w", sessionID, err)
+			}
+		}
+		if err := s.sessionRepo.Update(ctx, sess); err != nil {
+			return nil, fmt.Errorf("update settings session id=%s: %w", sess.ID, err)
+		}
+		return nil, nil
+	}
+}
+
+// DeleteSession はセッションとキューの曲などセッションに紐づくデータを削除します。
+// 再生中の場合はSpotifyの再生を一時停止してタイマーを止め、接続しているクライアントにセッションが削除されたことを通知してから接続を切断します。
+// セッションの作成者のみ削除できます。
+func (s *SessionUseCase) DeleteSession(ctx context.Context, sessionID string) error {
+	v, err := s.sessionRepo.DoInTx(ctx, s.deleteSessionTx(sessionID))
+	if err != nil {
+		return fmt.Errorf("delete session transaction: %w", err)
+	}
+	sess := v.(*entity.Session)
+
+	// トランザクションが失敗したときにタイマーだけが止まらないように、削除が確定してからタイマーを止めて通知する
+	s.timerUC.deleteTimer(sessionID)
+
+	// セッションは既に削除されているので、Spotifyの一時停止に失敗してもログを出すだけにする
+	if sess.StateType == entity.Play {
+		if err := s.playerCli.Pause(ctx, sess.DeviceID); err != nil && !errors.Is(err, entity.ErrActiveDeviceNotFound) {
+			logger := log.New()
+			logger.Errorj(map[string]interface{}{"message": "failed to pause deleted session", "sessionID": sessionID, "error": err.Error()})
+		}
+	}
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID:  sessionID,
+		Msg:        entity.EventDeleted,
+		Disconnect: true,
+	})
+	return nil
+}
+
+func (s *SessionUseCase) deleteSessionTx(sessionID string) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID, _ := service.GetUserIDFromContext(ctx)
+		if !sess.IsCreator(userID) {
+			return nil, fmt.Errorf("delete session user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+		}
+
+		if err := s.sessionRepo.Delete(ctx, sess.ID); err != nil {
+			return nil, fmt.Errorf("delete session id=%s: %w", sess.ID, err)
+		}
+		return sess, nil
+	}
+}
+
+// SetMemberRole はセッションに参加するユーザの役割を変更します。
+// 共同ホストにしたユーザは、他人による操作が許可されていなくても再生やキューを操作できるようになります。
+// セッションの作成者のみ変更できます。
+func (s *SessionUseCase) SetMemberRole(ctx context.Context, sessionID string, memberUserID string, role entity.SessionRole) error {
+	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("find session id=%s: %w", sessionID, err)
+	}
+
+	userID, _ := service.GetUserIDFromContext(ctx)
+	if !sess.IsCreator(userID) {
+		return fmt.Errorf("set member role user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+	}
+
+	if _, err := s.userRepo.FindByID(memberUserID); err != nil {
+		return fmt.Errorf("find member user id=%s: %w", memberUserID, err)
+	}
+
+	member, err := sess.NewSessionMember(memberUserID, role)
+	if err != nil {
+		return fmt.Errorf("new session member: %w", err)
+	}
+	if err := s.sessionRepo.StoreMember(ctx, member); err != nil {
+		return fmt.Errorf("store session member session id=%s user id=%s: %w", sessionID, memberUserID, err)
+	}
+	return nil
+}
+
+// BanParticipant は参加者をセッションから追放します。
+// 追放した参加者は曲の追加や再生の操作ができなくなり、WebSocketの接続も切断されます。
+// removeQueueTracksがtrueの場合は、追放した参加者が追加したまだ再生されていない曲もキューから削除します。
+// セッションの作成者のみ追放できます。
+func (s *SessionUseCase) BanParticipant(ctx context.Context, sessionID string, participantID string, removeQueueTracks bool) error {
+	v, err := s.sessionRepo.DoInTx(ctx, s.banParticipantTx(sessionID, participantID, removeQueueTracks))
+	if err != nil {
+		return fmt.Errorf("ban participant transaction: %w", err)
+	}
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID:     sessionID,
+		Msg:           entity.EventBanned,
+		ParticipantID: participantID,
+		Disconnect:    true,
+	})
+	if removed := v.(bool); removed {
+		s.pusher.Push(&event.PushMessage{
+			SessionID: sessionID,
+			Msg:       entity.EventQueueChanged,
+		})
+	}
+	return nil
+}
+
+// banParticipantTx は参加者を追放し、キューの曲を削除したかどうかを返します。
+func (s *SessionUseCase) banParticipantTx(sessionID string, participantID string, removeQueueTracks bool) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		sess, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		userID, _ := service.GetUserIDFromContext(ctx)
+		if !sess.IsCreator(userID) {
+			return nil, fmt.Errorf("ban participant user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+		}
+
+		ban, err := sess.NewSessionBan(participantID)
+		if err != nil {
+			return nil, fmt.Errorf("new session ban: %w", err)
+		}
+		if err := s.sessionRepo.StoreBan(ctx, ban); err != nil {
+			return nil, fmt.Errorf("store session ban session id=%s participant id=%s: %w", sessionID, participantID, err)
+		}
+
+		if !removeQueueTracks {
+			return false, nil
+		}
+
+		// 後ろの曲から削除するので、まだ削除していない曲のindexはずれない
+		indexes := sess.PendingQueueTrackIndexesAddedBy(participantID)
+		shouldResetSpotifyQueue := false
+		for _, index := range indexes {
+			// 削除する曲が既にSpotifyのキューに積まれている場合は、Spotify側のキューも積み直す必要がある
+			shouldResetSpotifyQueue = shouldResetSpotifyQueue || sess.IsEnqueuedToSpotify(index)
+
+			if err := sess.RemoveQueueTrack(index); err != nil {
+				return nil, fmt.Errorf("remove queue track index=%d: %w", index, err)
+			}
+			if err := s.sessionRepo.DeleteQueueTrack(ctx, sessionID, index); err != nil {
+				return nil, fmt.Errorf("delete queue track session id=%s index=%d: %w", sessionID, index, err)
+			}
+		}
+
+		if shouldResetSpotifyQueue {
+			if err := s.requestSpotifyQueueReset(ctx, sess); err != nil {
+				return nil, fmt.Errorf("request spotify queue reset session id=%s: %w", sessionID, err)
+			}
+		}
+		return len(indexes) > 0, nil
+	}
+}
+
+// ExportPlaylist はセッションの曲を、セッションの作成者のSpotifyアカウントに新しいプレイリストとして書き出します。
+// プレイリスト名が空文字列の場合はセッション名を使います。セッションの作成者のみ書き出せます。
+func (s *SessionUseCase) ExportPlaylist(ctx context.Context, sessionID string, target entity.PlaylistExportTarget, name string, public bool) (*entity.Playlist, error) {
+	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+	}
+
+	userID, _ := service.GetUserIDFromContext(ctx)
+	if !sess.IsCreator(userID) {
+		return nil, fmt.Errorf("export playlist user id=%s: %w", userID, entity.ErrUserIsNotSessionCreator)
+	}
+
+	trackURIs := sess.ExportTrackURIs(target)
+	if len(trackURIs) == 0 {
+		return nil, fmt.Errorf("export playlist session id=%s target=%s: %w", sessionID, target, entity.ErrNoTracksToExport)
+	}
+
+	creator, err := s.userRepo.FindByID(sess.CreatorID)
+	if err != nil {
+		return nil, fmt.Errorf("FindByID userID=%s: %w", sess.CreatorID, err)
+	}
+
+	if name == "" {
+		name = sess.Name
+	}
+	description := fmt.Sprintf("Relaymのセッション「%s」で流れた曲", sess.Name)
+
+	playlist, err := s.trackCli.CreatePlaylist(ctx, creator.SpotifyUserID, name, description, public)
+	if err != nil {
+		return nil, fmt.Errorf("create playlist name=%s: %w", name, err)
+	}
+
+	if err := s.trackCli.AddTracksToPlaylist(ctx, playlist.ID, trackURIs); err != nil {
+		return nil, fmt.Errorf("add tracks to playlist id=%s: %w", playlist.ID, err)
+	}
+	return playlist, nil
+}
+
 // GetSession は指定されたidからsessionの情報を返します
 func (s *SessionUseCase) GetSession(ctx context.Context, sessionID string) (*entity.SessionWithUser, []*entity.Track, *entity.CurrentPlayingInfo, error) {
 	session, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
@@ -157,9 +892,10 @@ func (s *SessionUseCase) GetSession(ctx context.Context, sessionID string) (*ent
 
 	if err := session.IsPlayingCorrectTrack(cpi); err != nil {
 		s.timerUC.deleteTimer(session.ID)
-		s.timerUC.handleInterrupt(session)
+		s.timerUC.handleInterrupt(ctx, session)
 
-		if updateErr := s.sessionRepo.Update(ctx, session); updateErr != nil {
+		// 参加者の操作ではないので最後の操作日時は更新しない
+		if updateErr := s.sessionRepo.UpdateWithoutActivity(ctx, session); updateErr != nil {
 			return nil, nil, nil, fmt.Errorf("update session id=%s: %v: %w", session.ID, err, updateErr)
 		}
 	}
@@ -167,6 +903,63 @@ func (s *SessionUseCase) GetSession(ctx context.Context, sessionID string) (*ent
 	return entity.NewSessionWithUser(session, creator), tracks, cpi, nil
 }
 
+// GetHistory は指定されたidのセッションのキューの全ての曲を、Spotifyから取得した曲の情報と再生状況と合わせて返します。
+func (s *SessionUseCase) GetHistory(ctx context.Context, sessionID string) (*entity.Session, []*entity.HistoryTrack, error) {
+	sess, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+	}
+
+	trackURIs := make([]string, len(sess.QueueTracks))
+	for i, queueTrack := range sess.QueueTracks {
+		trackURIs[i] = queueTrack.URI
+	}
+
+	tracks, err := s.trackCli.GetTracksFromURI(ctx, trackURIs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get tracks: track_uris=%s: %w", trackURIs, err)
+	}
+
+	return sess, sess.History(tracks), nil
+}
+
+// GetSessionIDByJoinCode は参加用のコードに対応するセッションのIDを返します。
+func (s *SessionUseCase) GetSessionIDByJoinCode(ctx context.Context, joinCode string) (string, error) {
+	id, err := s.sessionRepo.FindIDByJoinCode(ctx, joinCode)
+	if err != nil {
+		return "", fmt.Errorf("find session id join code=%s: %w", joinCode, err)
+	}
+	return id, nil
+}
+
+// GetMySessions はログインしているユーザが作成したか参加したセッションの一覧を、最後の操作日時が新しい順に返します。
+// filter.Limitが1以上MaxSessionListLimit以下でない場合はErrInvalidSessionListLimitを返します。
+// 次のページが存在する場合は、次のページを取得するためのカーソルも返します。
+func (s *SessionUseCase) GetMySessions(ctx context.Context, filter entity.SessionListFilter) ([]*entity.SessionWithUser, *entity.SessionListCursor, error) {
+	userID, ok := service.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, nil, errors.New("get user id from context")
+	}
+
+	if filter.Limit < 1 || entity.MaxSessionListLimit < filter.Limit {
+		return nil, nil, fmt.Errorf("limit=%d: %w", filter.Limit, entity.ErrInvalidSessionListLimit)
+	}
+
+	// 次のページが存在するかどうかを調べるために1件多く取得する
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	sessions, err := s.sessionRepo.FindByUserID(ctx, userID, filter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find sessions user id=%s: %w", userID, err)
+	}
+
+	if len(sessions) <= limit {
+		return sessions, nil, nil
+	}
+	sessions = sessions[:limit]
+	return sessions, entity.NewSessionListCursor(sessions[limit-1].Session), nil
+}
+
 // GetActiveDevices はログインしているユーザがSpotifyを起動している端末を取得します。
 func (s *SessionUseCase) GetActiveDevices(ctx context.Context) ([]*entity.Device, error) {
 	return s.userCli.GetActiveDevices(ctx)
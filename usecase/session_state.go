@@ -15,15 +15,16 @@ import (
 
 // SessionStateUseCase はセッションの再生に関するユースケースです。
 type SessionStateUseCase struct {
-	sessionRepo repository.Session
-	playerCli   spotify.Player
-	pusher      event.Pusher
-	timerUC     *SessionTimerUseCase
+	sessionRepo     repository.Session
+	playerCli       spotify.Player
+	pusher          event.Pusher
+	listenerCounter event.ListenerCounter
+	timerUC         *SessionTimerUseCase
 }
 
 // NewSessionPlayerUseCase はSessionPlayerUseCaseのポインタを生成します。
-func NewSessionStateUseCase(sessionRepo repository.Session, playerCli spotify.Player, pusher event.Pusher, timerUC *SessionTimerUseCase) *SessionStateUseCase {
-	return &SessionStateUseCase{sessionRepo: sessionRepo, playerCli: playerCli, pusher: pusher, timerUC: timerUC}
+func NewSessionStateUseCase(sessionRepo repository.Session, playerCli spotify.Player, pusher event.Pusher, listenerCounter event.ListenerCounter, timerUC *SessionTimerUseCase) *SessionStateUseCase {
+	return &SessionStateUseCase{sessionRepo: sessionRepo, playerCli: playerCli, pusher: pusher, listenerCounter: listenerCounter, timerUC: timerUC}
 }
 
 // NextTrack は指定されたidのsessionを次の曲に進めます
@@ -33,22 +34,27 @@ func (s *SessionStateUseCase) NextTrack(ctx context.Context, sessionID string) e
 		return fmt.Errorf("find session id=%s: %w", sessionID, err)
 	}
 
-	userID, _ := service.GetUserIDFromContext(ctx)
-	if !session.AllowToControlByOthers && !session.IsCreator(userID) {
-		return fmt.Errorf("not allowd to control session: %w", entity.ErrSessionNotAllowToControlOthers)
+	userID := service.GetParticipantIDFromContext(ctx)
+	if err := authorizeControl(ctx, s.sessionRepo, session, userID); err != nil {
+		return fmt.Errorf("authorize to control session: %w", err)
 	}
 
+	return s.goNextTrack(ctx, session)
+}
+
+// goNextTrack はsessionのstateに応じて次の曲に進めます。
+func (s *SessionStateUseCase) goNextTrack(ctx context.Context, session *entity.Session) error {
 	switch session.StateType {
 	case entity.Play:
-		if err = s.nextTrackInPlay(ctx, sessionID); err != nil {
+		if err := s.nextTrackInPlay(ctx, session.ID); err != nil {
 			return fmt.Errorf("go next track in play session id=%s: %w", session.ID, err)
 		}
 	case entity.Pause:
-		if err = s.nextTrackInPause(ctx, sessionID); err != nil {
+		if err := s.nextTrackInPause(ctx, session.ID); err != nil {
 			return fmt.Errorf("go next track in pause session id=%s: %w", session.ID, err)
 		}
 	case entity.Stop:
-		if err = s.nextTrackInStop(ctx, sessionID); err != nil {
+		if err := s.nextTrackInStop(ctx, session.ID); err != nil {
 			return fmt.Errorf("go next track in stop session id=%s: %w", session.ID, err)
 		}
 	case entity.Archived:
@@ -58,6 +64,72 @@ func (s *SessionStateUseCase) NextTrack(ctx context.Context, sessionID string) e
 	return nil
 }
 
+// VoteToSkip は指定されたidのsessionの現在の曲(head)に対してスキップ投票します。
+// 投票数がセッションに設定された閾値に達した場合は、NextTrackと同じように次の曲に進めます。
+func (s *SessionStateUseCase) VoteToSkip(ctx context.Context, sessionID string) (*entity.SkipVote, error) {
+	userID := service.GetParticipantIDFromContext(ctx)
+
+	v, err := s.sessionRepo.DoInTx(ctx, s.voteToSkipTx(sessionID, userID))
+	if err != nil {
+		return nil, fmt.Errorf("vote to skip transaction: %w", err)
+	}
+	res := v.(*voteToSkipResponse)
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID: sessionID,
+		Msg:       entity.NewEventSkipVote(res.session.QueueHead, res.skipVote),
+	})
+
+	if res.skipVote.Skipped {
+		if err := s.goNextTrack(ctx, res.session); err != nil {
+			return nil, fmt.Errorf("skip by vote: %w", err)
+		}
+	}
+	return res.skipVote, nil
+}
+
+// GetListeners は指定されたidのセッションにWebSocketで接続しているリスナーの一覧を取得します。
+func (s *SessionStateUseCase) GetListeners(ctx context.Context, sessionID string) ([]*entity.Listener, error) {
+	if _, err := s.sessionRepo.FindByID(ctx, sessionID); err != nil {
+		return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+	}
+	return s.listenerCounter.Listeners(sessionID), nil
+}
+
+func (s *SessionStateUseCase) voteToSkipTx(sessionID, userID string) func(ctx context.Context) (interface{}, error) {
+	return func(ctx context.Context) (interface{}, error) {
+		session, err := s.sessionRepo.FindByIDForUpdate(ctx, sessionID)
+		if err != nil {
+			return nil, fmt.Errorf("find session id=%s: %w", sessionID, err)
+		}
+
+		if err := ensureNotBanned(ctx, s.sessionRepo, session, userID); err != nil {
+			return nil, fmt.Errorf("vote to skip: %w", err)
+		}
+
+		if err := session.CanVoteToSkip(); err != nil {
+			return nil, fmt.Errorf("vote to skip: %w", err)
+		}
+
+		if err := s.sessionRepo.StoreSkipVote(ctx, sessionID, session.QueueHead, userID); err != nil {
+			return nil, fmt.Errorf("store skip vote index=%d: %w", session.QueueHead, err)
+		}
+
+		votes, err := s.sessionRepo.CountSkipVotes(ctx, sessionID, session.QueueHead)
+		if err != nil {
+			return nil, fmt.Errorf("count skip votes index=%d: %w", session.QueueHead, err)
+		}
+
+		required := session.SkipVoteThreshold.RequiredVotes(s.listenerCounter.CountListeners(sessionID))
+		return &voteToSkipResponse{session: session, skipVote: entity.NewSkipVote(votes, required)}, nil
+	}
+}
+
+type voteToSkipResponse struct {
+	session  *entity.Session
+	skipVote *entity.SkipVote
+}
+
 // nextTrackInPlay はsessionのstateがPLAYの時のnextTrackの処理を行います
 func (s *SessionStateUseCase) nextTrackInPlay(ctx context.Context, sessionID string) error {
 	// NextChを通してstartTrackEndTriggerに次の曲への遷移を通知
@@ -84,10 +156,30 @@ func (s *SessionStateUseCase) nextTrackInPauseTx(sessionID string) func(ctx cont
 		if err != nil {
 			return nil, fmt.Errorf("find session: %w", err)
 		}
-		if err := s.playerCli.GoNextTrack(ctx, session.DeviceID); err != nil {
-			return nil, fmt.Errorf("GoNextTrack: %w", err)
+
+		// 最後の曲をスキップするときはSpotifyのキューが空なので、スキップする前に曲を追加しておく
+		if res, err := s.timerUC.fillQueueInTransaction(ctx, session); res != nil {
+			if err != nil {
+				return nil, fmt.Errorf("fill queue: %w", err)
+			}
+			// Spotifyのキューに追加できずにINTERRUPTになった
+			if err := s.sessionRepo.Update(ctx, session); err != nil {
+				return nil, fmt.Errorf("update session id=%s: %w", session.ID, err)
+			}
+			return nil, nil
 		}
 
+		// Spotifyのキューを積み直す必要がある場合は、Spotifyのキューの次の曲が正しくないので再開するときまでSpotifyを操作しない
+		syncWithSpotify := !session.NeedsSpotifyQueueReset
+
+		if syncWithSpotify {
+			if err := s.playerCli.GoNextTrack(ctx, session.DeviceID); err != nil {
+				return nil, fmt.Errorf("GoNextTrack: %w", err)
+			}
+		}
+
+		s.timerUC.recordPlayback(ctx, session.EndHeadTrack(time.Now().UTC(), entity.QueueTrackSkipped))
+
 		if err := session.GoNextTrack(); err != nil && errors.Is(err, entity.ErrSessionAllTracksFinished) {
 			s.timerUC.handleAllTrackFinish(session)
 			if err := s.sessionRepo.Update(ctx, session); err != nil {
@@ -97,8 +189,10 @@ func (s *SessionStateUseCase) nextTrackInPauseTx(sessionID string) func(ctx cont
 		}
 
 		// GoNextTrackだけだと次の曲の再生が始まってしまう
-		if err := s.playerCli.Pause(ctx, session.DeviceID); err != nil {
-			return nil, fmt.Errorf("call pause api: %w", err)
+		if syncWithSpotify {
+			if err := s.playerCli.Pause(ctx, session.DeviceID); err != nil {
+				return nil, fmt.Errorf("call pause api: %w", err)
+			}
 		}
 
 		if err := s.sessionRepo.Update(ctx, session); err != nil {
@@ -106,7 +200,7 @@ func (s *SessionStateUseCase) nextTrackInPauseTx(sessionID string) func(ctx cont
 		}
 
 		track := session.TrackURIShouldBeAddedWhenHandleTrackEnd()
-		if track != "" {
+		if syncWithSpotify && track != "" {
 			if err := s.playerCli.Enqueue(ctx, track, session.DeviceID); err != nil {
 				return nil, fmt.Errorf("enqueue error session id=%s: %w", session.ID, err)
 			}
@@ -141,6 +235,11 @@ func (s *SessionStateUseCase) nextTrackInStopTx(sessionID string) func(ctx conte
 			return nil, fmt.Errorf("nextTrackInStop: %w", entity.ErrNextQueueTrackNotFound)
 		}
 
+		// STOPのheadはまだ再生を始めていない曲のことが多いので、再生を始めていた曲だけ終了を記録して履歴に残す
+		if !session.HeadTrack().StartedAt.IsZero() {
+			s.timerUC.recordPlayback(ctx, session.EndHeadTrack(time.Now().UTC(), entity.QueueTrackSkipped))
+		}
+
 		if err := session.GoNextTrack(); err != nil && errors.Is(err, entity.ErrSessionAllTracksFinished) {
 			s.timerUC.handleAllTrackFinish(session)
 			if err := s.sessionRepo.Update(ctx, session); err != nil {
@@ -173,9 +272,9 @@ func (s *SessionStateUseCase) ChangeSessionState(ctx context.Context, sessionID
 		return fmt.Errorf("state type from %s to %s: %w", session.StateType, st, entity.ErrChangeSessionStateNotPermit)
 	}
 
-	userID, _ := service.GetUserIDFromContext(ctx)
-	if !session.AllowToControlByOthers && !session.IsCreator(userID) {
-		return fmt.Errorf("not allowd to control state: %w", entity.ErrSessionNotAllowToControlOthers)
+	userID := service.GetParticipantIDFromContext(ctx)
+	if err := authorizeControl(ctx, s.sessionRepo, session, userID); err != nil {
+		return fmt.Errorf("authorize to control state: %w", err)
 	}
 
 	switch st {
@@ -223,6 +322,9 @@ func (s *SessionStateUseCase) playORResume(ctx context.Context, sess *entity.Ses
 		return fmt.Errorf("move to play id=%s: %w", sess.ID, err)
 	}
 
+	// 一時停止からの再開の場合は、既に再生を開始した日時が記録されているので記録し直さない
+	s.timerUC.recordPlayback(ctx, sess.StartHeadTrack(time.Now().UTC()))
+
 	if err := s.sessionRepo.Update(ctx, sess); err != nil {
 		return fmt.Errorf("update session id=%s: %w", sess.ID, err)
 	}
@@ -238,6 +340,14 @@ func (s *SessionStateUseCase) playORResume(ctx context.Context, sess *entity.Ses
 }
 
 func (s *SessionStateUseCase) pauseToPlay(ctx context.Context, sess *entity.Session) error {
+	// 一時停止中にSpotifyのキューに追加済みの曲が変わった場合は、再開するときにキューを積み直す
+	if sess.NeedsSpotifyQueueReset {
+		if err := rebuildSpotifyQueue(ctx, s.playerCli, sess, sess.ProgressWhenPaused); err != nil {
+			return fmt.Errorf("rebuild spotify queue: %w", err)
+		}
+		return nil
+	}
+
 	if err := s.playerCli.PlayWithTracksAndPosition(ctx, sess.DeviceID, []string{sess.HeadTrack().URI}, sess.ProgressWhenPaused); err != nil {
 		return fmt.Errorf("call play api: %w", err)
 	}
@@ -315,6 +425,10 @@ func (s *SessionStateUseCase) archive(ctx context.Context, session *entity.Sessi
 		return nil
 	}
 
+	if session.StateType == entity.Play || session.StateType == entity.Pause {
+		s.timerUC.recordPlayback(ctx, session.EndHeadTrack(time.Now().UTC(), entity.QueueTrackInterrupted))
+	}
+
 	s.timerUC.deleteTimer(session.ID)
 
 	session.MoveToArchived()
@@ -354,6 +468,8 @@ func (s *SessionStateUseCase) stop(ctx context.Context, session *entity.Session)
 // sessionの作成者からのみ呼び出しが可能です
 func (s *SessionStateUseCase) archiveToStop(ctx context.Context, session *entity.Session) error {
 	session.MoveToStop()
+	// アーカイブしたときに参加用のコードは解放しているので、新しく割り当てる
+	session.RegenerateJoinCode()
 
 	session.UpdateExpiredAt()
 
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/camphor-/relaym-server/domain/mock_repository"
 	"github.com/camphor-/relaym-server/domain/mock_spotify"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestSessionStateUseCase_nextTrackInPauseTx(t *testing.T) {
@@ -64,6 +66,7 @@ func TestSessionStateUseCase_nextTrackInPauseTx(t *testing.T) {
 						AllowToControlByOthers: true,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackSkipped}).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
@@ -143,6 +146,7 @@ func TestSessionStateUseCase_nextTrackInPauseTx(t *testing.T) {
 						AllowToControlByOthers: true,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackSkipped}).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
@@ -187,6 +191,102 @@ func TestSessionStateUseCase_nextTrackInPauseTx(t *testing.T) {
 			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
 			wantErr:               false,
 		},
+		{
+			name:                "Pauseでハウスプレイリストが設定されていると最後の曲をスキップする前にプレイリストの曲を追加して次の曲に遷移し、202",
+			sessionID:           "sessionID",
+			userID:              "userID",
+			addToTimerSessionID: "sessionID",
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {
+				gomock.InOrder(
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:playlist2", "deviceID").Return(nil),
+					m.EXPECT().GoNextTrack(gomock.Any(), "deviceID").Return(nil),
+					m.EXPECT().Pause(gomock.Any(), "deviceID").Return(nil),
+				)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(
+					&entity.Session{
+						ID:        "sessionID",
+						DeviceID:  "deviceID",
+						StateType: entity.Pause,
+						QueueHead: 1,
+						QueueTracks: []*entity.QueueTrack{
+							{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+							{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+						},
+						FallbackPlaylistURI: "spotify:playlist:playlistID",
+					}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackSkipped}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				gomock.InOrder(
+					m.EXPECT().Push(&event.PushMessage{
+						SessionID: "sessionID",
+						Msg:       entity.EventAddTrack,
+					}),
+					m.EXPECT().Push(&event.PushMessage{
+						SessionID: "sessionID",
+						Msg:       entity.NewEventNextTrack(2),
+					}),
+				)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlistID", entity.FallbackPlaylistTrackLimit).
+					Return([]string{"spotify:track:track_uri1", "spotify:track:playlist2"}, nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			wantErr:               false,
+		},
+		{
+			name:                   "PauseでSpotifyのキューを積み直す必要があるときはSpotifyを操作せずに次の曲に遷移し、202",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			addToTimerSessionID:    "sessionID",
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(
+					&entity.Session{
+						ID:        "sessionID",
+						DeviceID:  "deviceID",
+						StateType: entity.Pause,
+						QueueHead: 0,
+						QueueTracks: []*entity.QueueTrack{
+							{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+							{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+							{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+							{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID"},
+						},
+						ProgressWhenPaused:     10 * time.Second,
+						NeedsSpotifyQueueReset: true,
+					}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackSkipped}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), &entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Pause,
+					QueueHead: 1,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+						{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+						{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID"},
+					},
+					ProgressWhenPaused:     0,
+					NeedsSpotifyQueueReset: true,
+				}).Return(nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.NewEventNextTrack(1),
+				})
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			wantErr:               false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -209,6 +309,206 @@ func TestSessionStateUseCase_nextTrackInPauseTx(t *testing.T) {
 	}
 }
 
+func TestSessionStateUseCase_voteToSkipTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(state entity.StateType, threshold entity.SkipVoteThreshold) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "name",
+			CreatorID: "creatorID",
+			DeviceID:  "deviceID",
+			StateType: state,
+			QueueHead: 1,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+			},
+			SkipVoteThreshold: threshold,
+		}
+	}
+
+	tests := []struct {
+		name                         string
+		sessionID                    string
+		userID                       string
+		prepareMockSessionRepoFn     func(m *mock_repository.MockSession)
+		prepareMockListenerCounterFn func(m *mock_event.MockListenerCounter)
+		want                         *entity.SkipVote
+		wantErr                      error
+	}{
+		{
+			name:      "閾値に達していないときはスキップしない",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, entity.DefaultSkipVoteThreshold), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 1, "userID").Return(nil)
+				m.EXPECT().CountSkipVotes(gomock.Any(), "sessionID", 1).Return(2, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {
+				m.EXPECT().CountListeners("sessionID").Return(5)
+			},
+			want:    &entity.SkipVote{Votes: 2, Required: 3, Skipped: false},
+			wantErr: nil,
+		},
+		{
+			name:      "接続しているクライアント数に対する割合の閾値に達するとスキップする",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Pause, entity.DefaultSkipVoteThreshold), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 1, "userID").Return(nil)
+				m.EXPECT().CountSkipVotes(gomock.Any(), "sessionID", 1).Return(2, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {
+				m.EXPECT().CountListeners("sessionID").Return(4)
+			},
+			want:    &entity.SkipVote{Votes: 2, Required: 2, Skipped: true},
+			wantErr: nil,
+		},
+		{
+			name:      "票数の閾値に達するとクライアント数に関わらずスキップする",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, entity.SkipVoteThreshold{Type: entity.SkipVoteThresholdCount, Value: 1}), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 1, "userID").Return(nil)
+				m.EXPECT().CountSkipVotes(gomock.Any(), "sessionID", 1).Return(1, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {
+				m.EXPECT().CountListeners("sessionID").Return(10)
+			},
+			want:    &entity.SkipVote{Votes: 1, Required: 1, Skipped: true},
+			wantErr: nil,
+		},
+		{
+			name:      "同じ曲に2回投票するとErrSkipVoteAlreadyExisted",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, entity.DefaultSkipVoteThreshold), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 1, "userID").Return(entity.ErrSkipVoteAlreadyExisted)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {},
+			want:                         nil,
+			wantErr:                      entity.ErrSkipVoteAlreadyExisted,
+		},
+		{
+			name:      "STOPのときは投票できずErrChangeSessionStateNotPermit",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Stop, entity.DefaultSkipVoteThreshold), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {},
+			want:                         nil,
+			wantErr:                      entity.ErrChangeSessionStateNotPermit,
+		},
+		{
+			name:      "追放された参加者は投票できずErrParticipantBanned",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, entity.DefaultSkipVoteThreshold), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(true, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {},
+			want:                         nil,
+			wantErr:                      entity.ErrParticipantBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			mockListenerCounter := mock_event.NewMockListenerCounter(ctrl)
+			tt.prepareMockListenerCounterFn(mockListenerCounter)
+
+			s := NewSessionStateUseCase(mockSessionRepo, nil, nil, mockListenerCounter, nil)
+			got, err := s.voteToSkipTx(tt.sessionID, tt.userID)(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("voteToSkipTx() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if res := got.(*voteToSkipResponse); !cmp.Equal(res.skipVote, tt.want) {
+				t.Errorf("voteToSkipTx() diff = %v", cmp.Diff(tt.want, res.skipVote))
+			}
+		})
+	}
+}
+
+func TestSessionStateUseCase_VoteToSkip(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := &entity.Session{
+		ID:        "sessionID",
+		CreatorID: "creatorID",
+		DeviceID:  "deviceID",
+		StateType: entity.Play,
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+			{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+			{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+		},
+		SkipVoteThreshold: entity.SkipVoteThreshold{Type: entity.SkipVoteThresholdCount, Value: 2},
+	}
+
+	mockSessionRepo := mock_repository.NewMockSession(ctrl)
+	mockSessionRepo.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+			return f(ctx)
+		}).Times(2)
+	mockSessionRepo.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil).Times(2)
+	mockSessionRepo.EXPECT().IsBanned(gomock.Any(), "sessionID", gomock.Any()).Return(false, nil).Times(2)
+	mockSessionRepo.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 0, gomock.Any()).Return(nil).Times(2)
+	// 閾値の2票に達した後、headが進む前にもう1票届く
+	gomock.InOrder(
+		mockSessionRepo.EXPECT().CountSkipVotes(gomock.Any(), "sessionID", 0).Return(2, nil),
+		mockSessionRepo.EXPECT().CountSkipVotes(gomock.Any(), "sessionID", 0).Return(3, nil),
+	)
+	mockPusher := mock_event.NewMockPusher(ctrl)
+	mockPusher.EXPECT().Push(gomock.Any()).Times(2)
+	mockListenerCounter := mock_event.NewMockListenerCounter(ctrl)
+	mockListenerCounter.EXPECT().CountListeners("sessionID").Return(5).Times(2)
+
+	syncCheckTimerManager := entity.NewSyncCheckTimerManager()
+	timer := syncCheckTimerManager.CreateExpiredTimer("sessionID")
+	timerUC := NewSessionTimerUseCase(mockSessionRepo, nil, nil, mockPusher, syncCheckTimerManager)
+	uc := NewSessionStateUseCase(mockSessionRepo, nil, mockPusher, mockListenerCounter, timerUC)
+
+	for i, userID := range []string{"userID1", "userID2"} {
+		ctx := service.SetUserIDToContext(context.Background(), userID)
+		got, err := uc.VoteToSkip(ctx, "sessionID")
+		if err != nil {
+			t.Fatalf("VoteToSkip() error = %v", err)
+		}
+		if wantSkipped := i == 0; got.Skipped != wantSkipped {
+			t.Errorf("VoteToSkip() Skipped = %v, want %v", got.Skipped, wantSkipped)
+		}
+	}
+
+	// PLAYのときはNextChを通して次の曲に進むので、NextChに送られた回数がスキップした回数になる
+	if got := len(timer.NextCh()); got != 1 {
+		t.Errorf("VoteToSkip() skipped %d times, want 1", got)
+	}
+}
+
 func TestSessionStateUseCase_nextTrackInStopTx(t *testing.T) {
 	t.Parallel()
 
@@ -225,7 +525,7 @@ func TestSessionStateUseCase_nextTrackInStopTx(t *testing.T) {
 		wantErr                  bool
 	}{
 		{
-			name:                   "STOPかつ次の曲が存在する時に次の曲にSTOPのまま遷移,202",
+			name:                   "STOPかつ次の曲が存在する時に次の曲にSTOPのまま遷移し、まだ再生していない曲の終了は記録しない,202",
 			sessionID:              "sessionID",
 			userID:                 "userID",
 			addToTimerSessionID:    "sessionID",
@@ -289,6 +589,37 @@ func TestSessionStateUseCase_nextTrackInStopTx(t *testing.T) {
 			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
 			wantErr:               false,
 		},
+		{
+			name:                   "STOPでも再生を始めていた曲をスキップしたときは終了を記録する,202",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			addToTimerSessionID:    "sessionID",
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(
+					&entity.Session{
+						ID:        "sessionID",
+						DeviceID:  "deviceID",
+						StateType: "STOP",
+						QueueHead: 0,
+						QueueTracks: []*entity.QueueTrack{
+							{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID", StartedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+							{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+						},
+					}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackSkipped}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.NewEventNextTrack(1),
+				})
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			wantErr:               false,
+		},
 		{
 			name:                   "STOPかつ次の曲が存在しない時にErrNextQueueTrackNotFound,400",
 			sessionID:              "sessionID",
@@ -349,6 +680,70 @@ func TestSessionStateUseCase_nextTrackInStopTx(t *testing.T) {
 }
 
 // モックの準備
+func TestSessionStateUseCase_pauseToPlay(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(needsSpotifyQueueReset bool) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			DeviceID:  "deviceID",
+			StateType: entity.Pause,
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+				{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID"},
+			},
+			ProgressWhenPaused:     10 * time.Second,
+			NeedsSpotifyQueueReset: needsSpotifyQueueReset,
+		}
+	}
+
+	tests := []struct {
+		name                   string
+		session                *entity.Session
+		prepareMockPlayerCliFn func(m *mock_spotify.MockPlayer)
+		wantErr                bool
+	}{
+		{
+			name:    "Spotifyのキューを積み直す必要がないときは一時停止した位置からheadの曲を再生する",
+			session: newSession(false),
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().PlayWithTracksAndPosition(gomock.Any(), "deviceID", []string{"spotify:track:track_uri1"}, 10*time.Second).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:    "一時停止中にSpotifyのキューに追加済みの曲が変わったときはキューを積み直してから一時停止した位置から再生する",
+			session: newSession(true),
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {
+				gomock.InOrder(
+					m.EXPECT().DeleteAllTracksInQueue(gomock.Any(), "deviceID", "spotify:track:track_uri1").Return(nil),
+					m.EXPECT().PlayWithTracksAndPosition(gomock.Any(), "deviceID", []string{"spotify:track:track_uri1"}, 10*time.Second).Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:track_uri2", "deviceID").Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:track_uri3", "deviceID").Return(nil),
+				)
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			uc := newSessionStateUseCaseForTest(t, ctrl, tt.prepareMockPlayerCliFn, func(m *mock_spotify.MockTrackClient) {},
+				func(m *mock_event.MockPusher) {}, func(m *mock_repository.MockUser) {}, func(m *mock_repository.MockSession) {}, "")
+
+			if err := uc.pauseToPlay(context.Background(), tt.session); (err != nil) != tt.wantErr {
+				t.Errorf("pauseToPlay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func newSessionStateUseCaseForTest(
 	t *testing.T,
 	ctrl *gomock.Controller,
@@ -375,7 +770,7 @@ func newSessionStateUseCaseForTest(
 		timer := syncCheckTimerManager.CreateExpiredTimer(sessionID)
 		timer.SetDuration(5 * time.Minute)
 	}
-	timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockPusher, syncCheckTimerManager)
-	return NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, timerUC)
+	timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockTrackCli, mockPusher, syncCheckTimerManager)
+	return NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, nil, timerUC)
 
 }
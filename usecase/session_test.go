@@ -2,12 +2,18 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/mock_repository"
+	"github.com/camphor-/relaym-server/domain/mock_spotify"
+	"github.com/camphor-/relaym-server/domain/service"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestSessionUseCase_CanConnectToPusher(t *testing.T) {
@@ -69,7 +75,7 @@ func TestSessionUseCase_CanConnectToPusher(t *testing.T) {
 			mockSessionRepo := mock_repository.NewMockSession(ctrl)
 			tt.prepareMockSessionRepoFn(mockSessionRepo)
 			syncCheckTimerManager := entity.NewSyncCheckTimerManager()
-			stUC := NewSessionTimerUseCase(nil, &FakePlayer{}, nil, syncCheckTimerManager)
+			stUC := NewSessionTimerUseCase(nil, &FakePlayer{}, nil, nil, syncCheckTimerManager)
 			s := NewSessionUseCase(mockSessionRepo, nil, &FakePlayer{}, nil, nil, nil, stUC)
 
 			if err := s.CanConnectToPusher(context.Background(), tt.sessionID); (err != nil) != tt.wantErr {
@@ -79,6 +85,811 @@ func TestSessionUseCase_CanConnectToPusher(t *testing.T) {
 	}
 }
 
+func TestSessionUseCase_enqueueTracksTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(state entity.StateType, tracks int) *entity.Session {
+		queueTracks := make([]*entity.QueueTrack, tracks)
+		for i := range queueTracks {
+			queueTracks[i] = &entity.QueueTrack{Index: i, URI: fmt.Sprintf("spotify:track:track_uri%d", i+1), SessionID: "sessionID"}
+		}
+		return &entity.Session{
+			ID:                     "sessionID",
+			Name:                   "name",
+			CreatorID:              "creatorID",
+			DeviceID:               "deviceID",
+			StateType:              state,
+			QueueHead:              0,
+			QueueTracks:            queueTracks,
+			AllowToControlByOthers: true,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		uris                     []string
+		position                 entity.EnqueuePosition
+		prepareMockPlayerCliFn   func(m *mock_spotify.MockPlayer)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  error
+	}{
+		{
+			name:                   "lastを指定するとキューの最後に追加される",
+			sessionID:              "sessionID",
+			uris:                   []string{"spotify:track:new_uri1"},
+			position:               entity.EnqueuePositionLast,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, 5), nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 5).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "STOPでnextを指定するとheadの直後に追加した順番で挿入され、Spotifyのキューは操作しない",
+			sessionID:              "sessionID",
+			uris:                   []string{"spotify:track:new_uri1", "spotify:track:new_uri2"},
+			position:               entity.EnqueuePositionNext,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Stop, 5), nil)
+				gomock.InOrder(
+					m.EXPECT().StoreQueueTrack(gomock.Any(), &entity.QueueTrackToStore{URI: "spotify:track:new_uri1", SessionID: "sessionID"}, 1).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), &entity.QueueTrackToStore{URI: "spotify:track:new_uri2", SessionID: "sessionID"}, 2).Return(nil),
+				)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでnextを指定するとheadの直後に挿入され、再生を途切れさせないように次の曲に進むときにキューを積み直すことを記録する",
+			sessionID:              "sessionID",
+			uris:                   []string{"spotify:track:new_uri1"},
+			position:               entity.EnqueuePositionNext,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, 5), nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 1).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name:      "PLAYでheadより後ろに曲が無いときはnextを指定してもキューの最後に追加してEnqueueを叩く",
+			sessionID: "sessionID",
+			uris:      []string{"spotify:track:new_uri1"},
+			position:  entity.EnqueuePositionNext,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:new_uri1", "deviceID").Return(nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, 1), nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockPlayerCli := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerCliFn(mockPlayerCli)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayerCli, nil, nil, entity.NewSyncCheckTimerManager())
+			s := NewSessionUseCase(mockSessionRepo, nil, mockPlayerCli, nil, nil, nil, timerUC)
+
+			queueTracks := make([]*entity.QueueTrackToStore, len(tt.uris))
+			for i, uri := range tt.uris {
+				queueTracks[i] = &entity.QueueTrackToStore{URI: uri, SessionID: tt.sessionID}
+			}
+			if _, err := s.enqueueTracksTx(tt.sessionID, queueTracks, tt.position)(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("enqueueTracksTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionUseCase_removeQueueTrackTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(state entity.StateType, allowToControlByOthers bool) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "name",
+			CreatorID: "creatorID",
+			DeviceID:  "deviceID",
+			StateType: state,
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+				{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID"},
+			},
+			AllowToControlByOthers: allowToControlByOthers,
+			ProgressWhenPaused:     10 * time.Second,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		userID                   string
+		index                    int
+		prepareMockPlayerCliFn   func(m *mock_spotify.MockPlayer)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  error
+	}{
+		{
+			name:                   "STOPのときはSpotifyのキューを操作せずに曲が削除される",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  1,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Stop, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでSpotifyのキューに追加されていない曲を削除するときはSpotifyのキューを操作しない",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  3,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 3).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでSpotifyのキューに追加されている曲を削除するときは、再生を途切れさせないように次の曲に進むときにキューを積み直すことを記録する",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  1,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 1).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PAUSEでSpotifyのキューに追加されている曲を削除するときは再生を始めずに、再開するときにキューを積み直すことを記録する",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  2,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Pause, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 2).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "headの曲は削除できない",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  0,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr: entity.ErrQueueTrackNotEditable,
+		},
+		{
+			name:                   "存在しないindexを指定するとErrQueueTrackNotFound",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  4,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr: entity.ErrQueueTrackNotFound,
+		},
+		{
+			name:                   "作成者以外の操作が許可されていないセッションで作成者以外が削除しようとするとErrSessionNotAllowToControlOthers",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  1,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, false), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().FindMemberRole(gomock.Any(), "sessionID", "userID").Return(entity.SessionRoleListener, nil)
+			},
+			wantErr: entity.ErrSessionNotAllowToControlOthers,
+		},
+		{
+			name:                   "作成者以外の操作が許可されていないセッションでも共同ホストは削除できる",
+			sessionID:              "sessionID",
+			userID:                 "userID",
+			index:                  3,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, false), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().FindMemberRole(gomock.Any(), "sessionID", "userID").Return(entity.SessionRoleCoHost, nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 3).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "追放された参加者は他人による操作が許可されていても削除できない",
+			sessionID:              "sessionID",
+			userID:                 "bannedUserID",
+			index:                  3,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, true), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "bannedUserID").Return(true, nil)
+			},
+			wantErr: entity.ErrParticipantBanned,
+		},
+		{
+			name:                   "作成者以外の操作が許可されていないセッションでも作成者は削除できる",
+			sessionID:              "sessionID",
+			userID:                 "creatorID",
+			index:                  3,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play, false), nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 3).Return(nil)
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockPlayerCli := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerCliFn(mockPlayerCli)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayerCli, nil, nil, entity.NewSyncCheckTimerManager())
+			s := NewSessionUseCase(mockSessionRepo, nil, mockPlayerCli, nil, nil, nil, timerUC)
+
+			ctx := service.SetUserIDToContext(context.Background(), tt.userID)
+			if _, err := s.removeQueueTrackTx(tt.sessionID, tt.index)(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("removeQueueTrackTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionUseCase_moveQueueTrackTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(state entity.StateType) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "name",
+			CreatorID: "creatorID",
+			DeviceID:  "deviceID",
+			StateType: state,
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+				{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID"},
+				{Index: 4, URI: "spotify:track:track_uri5", SessionID: "sessionID"},
+			},
+			AllowToControlByOthers: true,
+			ProgressWhenPaused:     10 * time.Second,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		from                     int
+		to                       int
+		prepareMockPlayerCliFn   func(m *mock_spotify.MockPlayer)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  error
+	}{
+		{
+			name:                   "STOPのときはSpotifyのキューを操作せずに曲が移動される",
+			sessionID:              "sessionID",
+			from:                   4,
+			to:                     1,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Stop), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().MoveQueueTrack(gomock.Any(), "sessionID", 4, 1).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでSpotifyのキューに追加されていない範囲で移動するときはSpotifyのキューを操作しない",
+			sessionID:              "sessionID",
+			from:                   4,
+			to:                     3,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().MoveQueueTrack(gomock.Any(), "sessionID", 4, 3).Return(nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでSpotifyのキューに追加されている範囲に移動するときは、再生を途切れさせないように次の曲に進むときにキューを積み直すことを記録する",
+			sessionID:              "sessionID",
+			from:                   4,
+			to:                     1,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().MoveQueueTrack(gomock.Any(), "sessionID", 4, 1).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "PAUSEでSpotifyのキューに追加されている曲を範囲外に移動するときは再生を始めずに、再開するときにキューを積み直すことを記録する",
+			sessionID:              "sessionID",
+			from:                   1,
+			to:                     4,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Pause), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().MoveQueueTrack(gomock.Any(), "sessionID", 1, 4).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "同じ位置を指定したときは何もしない",
+			sessionID:              "sessionID",
+			from:                   2,
+			to:                     2,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr: nil,
+		},
+		{
+			name:                   "headの位置には移動できない",
+			sessionID:              "sessionID",
+			from:                   3,
+			to:                     0,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr: entity.ErrQueueTrackNotEditable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockPlayerCli := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerCliFn(mockPlayerCli)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayerCli, nil, nil, entity.NewSyncCheckTimerManager())
+			s := NewSessionUseCase(mockSessionRepo, nil, mockPlayerCli, nil, nil, nil, timerUC)
+
+			ctx := service.SetUserIDToContext(context.Background(), "userID")
+			if _, err := s.moveQueueTrackTx(tt.sessionID, tt.from, tt.to)(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("moveQueueTrackTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSessionUseCase_banParticipantTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(state entity.StateType) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "name",
+			CreatorID: "creatorID",
+			DeviceID:  "deviceID",
+			StateType: state,
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID", AddedBy: "bannedUserID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID", AddedBy: "bannedUserID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID", AddedBy: "otherUserID"},
+				{Index: 3, URI: "spotify:track:track_uri4", SessionID: "sessionID", AddedBy: "bannedUserID"},
+			},
+			ProgressWhenPaused: 10 * time.Second,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		userID                   string
+		participantID            string
+		removeQueueTracks        bool
+		prepareMockPlayerCliFn   func(m *mock_spotify.MockPlayer)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		want                     interface{}
+		wantErr                  error
+	}{
+		{
+			name:                   "キューの曲を削除しない場合は追放のみ保存する",
+			userID:                 "creatorID",
+			participantID:          "bannedUserID",
+			removeQueueTracks:      false,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "sessionID", ParticipantID: "bannedUserID"}).Return(nil)
+			},
+			want:    false,
+			wantErr: nil,
+		},
+		{
+			name:                   "STOPのときはSpotifyのキューを操作せずに追放した参加者のまだ再生されていない曲を後ろから削除する",
+			userID:                 "creatorID",
+			participantID:          "bannedUserID",
+			removeQueueTracks:      true,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Stop), nil)
+				m.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "sessionID", ParticipantID: "bannedUserID"}).Return(nil)
+				gomock.InOrder(
+					m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 3).Return(nil),
+					m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 1).Return(nil),
+				)
+			},
+			want:    true,
+			wantErr: nil,
+		},
+		{
+			name:                   "PLAYでSpotifyのキューに追加されている曲を削除するときは、再生を途切れさせないように次の曲に進むときにキューを積み直すことを記録する",
+			userID:                 "creatorID",
+			participantID:          "bannedUserID",
+			removeQueueTracks:      true,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+				m.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "sessionID", ParticipantID: "bannedUserID"}).Return(nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 3).Return(nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "sessionID", 1).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if !sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = false, want true")
+					}
+					return nil
+				})
+			},
+			want:    true,
+			wantErr: nil,
+		},
+		{
+			name:                   "作成者は追放できない",
+			userID:                 "creatorID",
+			participantID:          "creatorID",
+			removeQueueTracks:      true,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+			},
+			want:    nil,
+			wantErr: entity.ErrCreatorCannotBeBanned,
+		},
+		{
+			name:                   "作成者以外は追放できない",
+			userID:                 "otherUserID",
+			participantID:          "bannedUserID",
+			removeQueueTracks:      true,
+			prepareMockPlayerCliFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.Play), nil)
+			},
+			want:    nil,
+			wantErr: entity.ErrUserIsNotSessionCreator,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockPlayerCli := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerCliFn(mockPlayerCli)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			timerUC := NewSessionTimerUseCase(mockSessionRepo, mockPlayerCli, nil, nil, entity.NewSyncCheckTimerManager())
+			s := NewSessionUseCase(mockSessionRepo, nil, mockPlayerCli, nil, nil, nil, timerUC)
+
+			ctx := service.SetUserIDToContext(context.Background(), tt.userID)
+			got, err := s.banParticipantTx("sessionID", tt.participantID, tt.removeQueueTracks)(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("banParticipantTx() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("banParticipantTx() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionUseCase_BanParticipant_GuestFromListeners(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	guest := &entity.Guest{ID: "3f2a9c0d-1e4b-5a6c-8d7e-9f0a1b2c3d4e", Nickname: "guest"}
+	guestCtx := service.SetGuestToContext(context.Background(), guest)
+	sess := &entity.Session{ID: "sessionID", CreatorID: "creatorID", StateType: entity.Stop, QueueTracks: []*entity.QueueTrack{}}
+
+	mockSessionRepo := mock_repository.NewMockSession(ctrl)
+	gomock.InOrder(
+		mockSessionRepo.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil),
+		mockSessionRepo.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "sessionID", ParticipantID: guest.ID}).Return(nil),
+		mockSessionRepo.EXPECT().FindByID(gomock.Any(), "sessionID").Return(sess, nil),
+		mockSessionRepo.EXPECT().IsBanned(gomock.Any(), "sessionID", guest.ID).Return(true, nil),
+	)
+	s := NewSessionUseCase(mockSessionRepo, nil, nil, nil, nil, nil, nil)
+
+	// /listeners で返されるゲストのIDをそのまま追放に使う
+	listener, err := s.IdentifyListener(guestCtx)
+	if err != nil {
+		t.Fatalf("IdentifyListener() error = %v", err)
+	}
+	creatorCtx := service.SetUserIDToContext(context.Background(), "creatorID")
+	if _, err := s.banParticipantTx("sessionID", listener.ID, false)(creatorCtx); err != nil {
+		t.Fatalf("banParticipantTx() error = %v", err)
+	}
+
+	// 追放したゲストは同じCookieで再接続できない
+	if err := s.CanConnectToPusher(guestCtx, "sessionID"); !errors.Is(err, entity.ErrParticipantBanned) {
+		t.Errorf("CanConnectToPusher() error = %v, wantErr %v", err, entity.ErrParticipantBanned)
+	}
+}
+
+func TestSessionUseCase_toggleQueueTrackVoteTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(queueOrderType entity.QueueOrderType) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			StateType: entity.Stop,
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID", Votes: 1},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID", Votes: 1},
+			},
+			QueueOrderType: queueOrderType,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		index                    int
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantVoted                bool
+		wantIndex                int
+		wantVotes                int
+		wantErr                  error
+	}{
+		{
+			name:  "まだ投票していない曲に投票できる",
+			index: 2,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.QueueOrderInsertion), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(false, nil)
+				m.EXPECT().StoreQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(nil)
+			},
+			wantVoted: true,
+			wantIndex: 2,
+			wantVotes: 2,
+			wantErr:   nil,
+		},
+		{
+			name:  "既に投票している曲の投票は取り消される",
+			index: 2,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.QueueOrderInsertion), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(true, nil)
+			},
+			wantVoted: false,
+			wantIndex: 2,
+			wantVotes: 0,
+			wantErr:   nil,
+		},
+		{
+			name:  "投票モードでは投票数が多くなった曲が前に並び替えられる",
+			index: 2,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.QueueOrderVote), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(false, nil)
+				m.EXPECT().StoreQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(nil)
+				m.EXPECT().UpdateQueueTrackIndexes(gomock.Any(), "sessionID", map[int]int{1: 2, 2: 1}).Return(nil)
+			},
+			wantVoted: true,
+			wantIndex: 1,
+			wantVotes: 2,
+			wantErr:   nil,
+		},
+		{
+			name:  "headの曲には投票できない",
+			index: 0,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.QueueOrderVote), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 0, "userID").Return(false, nil)
+			},
+			wantErr: entity.ErrQueueTrackNotEditable,
+		},
+		{
+			name:  "追放された参加者は投票できずErrParticipantBanned",
+			index: 2,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(entity.QueueOrderVote), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(true, nil)
+			},
+			wantErr: entity.ErrParticipantBanned,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			s := NewSessionUseCase(mockSessionRepo, nil, nil, nil, nil, nil, nil)
+
+			got, err := s.toggleQueueTrackVoteTx("sessionID", tt.index, "userID")(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("toggleQueueTrackVoteTx() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			res := got.(*toggleQueueTrackVoteResponse)
+			if res.voted != tt.wantVoted || res.queueTrack.Index != tt.wantIndex || res.queueTrack.Votes != tt.wantVotes {
+				t.Errorf("toggleQueueTrackVoteTx() voted = %v, index = %d, votes = %d, want voted = %v, index = %d, votes = %d",
+					res.voted, res.queueTrack.Index, res.queueTrack.Votes, tt.wantVoted, tt.wantIndex, tt.wantVotes)
+			}
+		})
+	}
+}
+
+func TestSessionUseCase_GetMySessions(t *testing.T) {
+	t.Parallel()
+
+	lastActivityAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	sessions := []*entity.SessionWithUser{
+		{Session: &entity.Session{ID: "sessionID1", LastActivityAt: lastActivityAt}},
+		{Session: &entity.Session{ID: "sessionID2", LastActivityAt: lastActivityAt.Add(-time.Hour)}},
+	}
+
+	tests := []struct {
+		name                     string
+		limit                    int
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantIDs                  []string
+		wantCursor               *entity.SessionListCursor
+		wantErr                  error
+	}{
+		{
+			name:                     "limitが0だとセッションを取得せずにエラー",
+			limit:                    0,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  entity.ErrInvalidSessionListLimit,
+		},
+		{
+			name:                     "limitが上限を超えているとセッションを取得せずにエラー",
+			limit:                    entity.MaxSessionListLimit + 1,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  entity.ErrInvalidSessionListLimit,
+		},
+		{
+			name:  "次のページがあるときはlimit件だけ返して最後のセッションのカーソルを返す",
+			limit: 1,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByUserID(gomock.Any(), "userID", entity.SessionListFilter{Limit: 2}).Return(sessions, nil)
+			},
+			wantIDs:    []string{"sessionID1"},
+			wantCursor: &entity.SessionListCursor{LastActivityAt: lastActivityAt, ID: "sessionID1"},
+			wantErr:    nil,
+		},
+		{
+			name:  "次のページがないときはカーソルを返さない",
+			limit: 2,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByUserID(gomock.Any(), "userID", entity.SessionListFilter{Limit: 3}).Return(sessions, nil)
+			},
+			wantIDs:    []string{"sessionID1", "sessionID2"},
+			wantCursor: nil,
+			wantErr:    nil,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+			s := NewSessionUseCase(mockSessionRepo, nil, nil, nil, nil, nil, nil)
+
+			ctx := service.SetUserIDToContext(context.Background(), "userID")
+			got, gotCursor, err := s.GetMySessions(ctx, entity.SessionListFilter{Limit: tt.limit})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetMySessions() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			gotIDs := make([]string, len(got))
+			for i, s := range got {
+				gotIDs[i] = s.ID
+			}
+			if !cmp.Equal(gotIDs, tt.wantIDs, cmpopts.EquateEmpty()) {
+				t.Errorf("GetMySessions() diff = %v", cmp.Diff(tt.wantIDs, gotIDs))
+			}
+			if !cmp.Equal(gotCursor, tt.wantCursor) {
+				t.Errorf("GetMySessions() cursor diff = %v", cmp.Diff(tt.wantCursor, gotCursor))
+			}
+		})
+	}
+}
+
 type FakePlayer struct{}
 
 func (m *FakePlayer) PlayWithTracksAndPosition(ctx context.Context, deviceID string, trackURIs []string, position time.Duration) error {
@@ -21,11 +21,12 @@ type SessionTimerUseCase struct {
 	tm          *entity.SyncCheckTimerManager
 	sessionRepo repository.Session
 	playerCli   spotify.Player
+	trackCli    spotify.TrackClient
 	pusher      event.Pusher
 }
 
-func NewSessionTimerUseCase(sessionRepo repository.Session, playerCli spotify.Player, pusher event.Pusher, tm *entity.SyncCheckTimerManager) *SessionTimerUseCase {
-	return &SessionTimerUseCase{tm: tm, sessionRepo: sessionRepo, playerCli: playerCli, pusher: pusher}
+func NewSessionTimerUseCase(sessionRepo repository.Session, playerCli spotify.Player, trackCli spotify.TrackClient, pusher event.Pusher, tm *entity.SyncCheckTimerManager) *SessionTimerUseCase {
+	return &SessionTimerUseCase{tm: tm, sessionRepo: sessionRepo, playerCli: playerCli, trackCli: trackCli, pusher: pusher}
 }
 
 // startTrackEndTrigger は曲の終了やストップを検知してそれぞれの処理を実行します。 goroutineで実行されることを想定しています。
@@ -118,8 +119,8 @@ func (s *SessionTimerUseCase) handleWaitTimerExpired(ctx context.Context, sessio
 	}
 
 	if err := sess.IsPlayingCorrectTrack(playingInfo); err != nil {
-		s.handleInterrupt(sess)
-		if err := s.sessionRepo.Update(ctx, sess); err != nil {
+		s.handleInterrupt(ctx, sess)
+		if err := s.sessionRepo.UpdateWithoutActivity(ctx, sess); err != nil {
 			logger.Errorj(map[string]interface{}{
 				"message":   "handleWaitTimerExpired: failed to update session after IsPlayingCorrectTrack and handleInterrupt",
 				"sessionID": sessionID,
@@ -160,6 +161,9 @@ func (s *SessionTimerUseCase) handleTrackEnd(ctx context.Context, sessionID stri
 		if err != nil {
 			return false, fmt.Errorf("handle track end in transaction: %w", err)
 		}
+		if v.sessionToResetSpotifyQueue != nil {
+			s.resetSpotifyQueue(ctx, v.sessionToResetSpotifyQueue)
+		}
 		return v.nextTrack, v.err
 	}
 	// これはトランザクションが失敗してRollbackしたとき
@@ -177,6 +181,9 @@ func (s *SessionTimerUseCase) handleNext(ctx context.Context, sessionID string)
 		if err != nil {
 			return false, fmt.Errorf("handle track end in transaction: %w", err)
 		}
+		if v.sessionToResetSpotifyQueue != nil {
+			s.resetSpotifyQueue(ctx, v.sessionToResetSpotifyQueue)
+		}
 		return v.nextTrack, v.err
 	}
 	// これはトランザクションが失敗してRollbackしたとき
@@ -196,8 +203,9 @@ func (s *SessionTimerUseCase) handleTrackEndTx(sessionID string) func(ctx contex
 			return &handleTrackEndResponse{nextTrack: false}, fmt.Errorf("find session id=%s: %v", sessionID, err)
 		}
 
+		// 曲の再生が終わってheadが進むのは参加者の操作ではないので、最後の操作日時は更新しない
 		defer func() {
-			if err := s.sessionRepo.Update(ctx, sess); err != nil {
+			if err := s.sessionRepo.UpdateWithoutActivity(ctx, sess); err != nil {
 				if returnErr != nil {
 					returnErr = fmt.Errorf("update session id=%s: %v: %w", sess.ID, err, returnErr)
 				} else {
@@ -210,15 +218,31 @@ func (s *SessionTimerUseCase) handleTrackEndTx(sessionID string) func(ctx contex
 			return s.handleArchiveInTransaction(sessionID)
 		}
 
+		res, err := s.fillQueueInTransaction(ctx, sess)
+		if res != nil {
+			return res, err
+		}
+
+		now := time.Now().UTC()
+		s.recordPlayback(ctx, sess.EndHeadTrack(now, entity.QueueTrackFinished))
+
 		if err := sess.GoNextTrack(); err != nil && errors.Is(err, entity.ErrSessionAllTracksFinished) {
 			s.handleAllTrackFinish(sess)
 			return &handleTrackEndResponse{
-				nextTrack: false,
-				err:       nil,
+				nextTrack:                  false,
+				err:                        nil,
+				sessionToResetSpotifyQueue: takeSessionToResetSpotifyQueue(sess),
 			}, nil
 		}
 
-		res, err := s.enqueueTrackInTransaction(ctx, sess)
+		s.recordPlayback(ctx, sess.StartHeadTrack(now))
+
+		// Spotifyのキューを積み直す場合は、積み直すときに後続の曲も追加される
+		if resetSess := takeSessionToResetSpotifyQueue(sess); resetSess != nil {
+			return &handleTrackEndResponse{nextTrack: true, err: nil, sessionToResetSpotifyQueue: resetSess}, nil
+		}
+
+		res, err = s.enqueueTrackInTransaction(ctx, sess)
 		if res != nil {
 			return res, err
 		}
@@ -249,22 +273,44 @@ func (s *SessionTimerUseCase) handleNextTx(sessionID string) func(ctx context.Co
 			}
 		}()
 
-		if err := s.playerCli.GoNextTrack(ctx, sess.DeviceID); err != nil {
-			return &handleTrackEndResponse{nextTrack: false}, fmt.Errorf("GoNextTrack: %w", err)
+		// 最後の曲をスキップするときはSpotifyのキューが空なので、スキップする前に曲を追加しておく
+		if sess.StateType != entity.Archived {
+			if res, err := s.fillQueueInTransaction(ctx, sess); res != nil {
+				return res, err
+			}
+		}
+
+		// キューの編集でSpotifyのキューが食い違っている場合は、Spotifyでスキップすると編集前の曲が流れるので、
+		// スキップせずにコミットした後にキューを積み直す
+		if sess.StateType == entity.Archived || !sess.NeedsSpotifyQueueReset {
+			if err := s.playerCli.GoNextTrack(ctx, sess.DeviceID); err != nil {
+				return &handleTrackEndResponse{nextTrack: false}, fmt.Errorf("GoNextTrack: %w", err)
+			}
 		}
 
 		if sess.StateType == entity.Archived {
 			return s.handleArchiveInTransaction(sessionID)
 		}
 
+		now := time.Now().UTC()
+		s.recordPlayback(ctx, sess.EndHeadTrack(now, entity.QueueTrackSkipped))
+
 		if err := sess.GoNextTrack(); err != nil && errors.Is(err, entity.ErrSessionAllTracksFinished) {
 			s.handleAllTrackFinish(sess)
 			return &handleTrackEndResponse{
-				nextTrack: false,
-				err:       nil,
+				nextTrack:                  false,
+				err:                        nil,
+				sessionToResetSpotifyQueue: takeSessionToResetSpotifyQueue(sess),
 			}, nil
 		}
 
+		s.recordPlayback(ctx, sess.StartHeadTrack(now))
+
+		// Spotifyのキューを積み直す場合は、積み直すときに後続の曲も追加される
+		if resetSess := takeSessionToResetSpotifyQueue(sess); resetSess != nil {
+			return &handleTrackEndResponse{nextTrack: true, err: nil, sessionToResetSpotifyQueue: resetSess}, nil
+		}
+
 		res, err := s.enqueueTrackInTransaction(ctx, sess)
 		if res != nil {
 			return res, err
@@ -294,8 +340,8 @@ func (s *SessionTimerUseCase) enqueueTrackInTransaction(ctx context.Context, ses
 	track := sess.TrackURIShouldBeAddedWhenHandleTrackEnd()
 	if track != "" {
 		if err := s.playerCli.Enqueue(ctx, track, sess.DeviceID); err != nil {
-			s.handleInterrupt(sess)
-			if err := s.sessionRepo.Update(ctx, sess); err != nil {
+			s.handleInterrupt(ctx, sess)
+			if err := s.sessionRepo.UpdateWithoutActivity(ctx, sess); err != nil {
 				logger.Errorj(map[string]interface{}{
 					"message":   "handleWaitTimerExpired: failed to update session after Enqueue and handleInterrupt",
 					"sessionID": sess.ID,
@@ -309,6 +355,133 @@ func (s *SessionTimerUseCase) enqueueTrackInTransaction(ctx context.Context, ses
 	return nil, nil
 }
 
+// fillQueueInTransaction はheadがキューの最後の曲のときに、ハウスプレイリストの曲もしくはおすすめの曲をキューの最後に追加します。
+// ハウスプレイリストが設定されている場合はそちらを優先し、曲を取得できなかった場合はおすすめの曲を追加します。
+// どちらからも曲を取得できなかった場合は何もせず、通常通りキューの全ての曲の再生が終わったときの処理に任せます。
+func (s *SessionTimerUseCase) fillQueueInTransaction(ctx context.Context, sess *entity.Session) (*handleTrackEndResponse, error) {
+	if sess.ShouldPullFromFallbackPlaylist() {
+		if trackURI := s.nextFallbackTrackURI(ctx, sess); trackURI != "" {
+			return s.appendQueueTracksInTransaction(ctx, sess, []string{trackURI}, entity.FallbackPlaylistDisplayName)
+		}
+	}
+
+	if sess.ShouldAutoplay() {
+		if trackURIs := s.recommendationTrackURIs(ctx, sess); len(trackURIs) > 0 {
+			return s.appendQueueTracksInTransaction(ctx, sess, trackURIs, entity.AutoplayDisplayName)
+		}
+	}
+	return nil, nil
+}
+
+// nextFallbackTrackURI はハウスプレイリストから次にキューに追加する曲のURIを取得します。
+// 取得できなかった場合は空文字列を返します。
+func (s *SessionTimerUseCase) nextFallbackTrackURI(ctx context.Context, sess *entity.Session) string {
+	logger := log.New()
+
+	_, playlistID, err := entity.ParseSpotifyURI(sess.FallbackPlaylistURI)
+	if err != nil {
+		logger.Errorj(map[string]interface{}{
+			"message":     "nextFallbackTrackURI: invalid fallback playlist uri",
+			"sessionID":   sess.ID,
+			"playlistURI": sess.FallbackPlaylistURI,
+			"error":       err.Error(),
+		})
+		return ""
+	}
+
+	playlistTrackURIs, err := s.trackCli.GetPlaylistTrackURIs(ctx, playlistID, entity.FallbackPlaylistTrackLimit)
+	if err != nil {
+		logger.Errorj(map[string]interface{}{
+			"message":     "nextFallbackTrackURI: failed to get playlist tracks",
+			"sessionID":   sess.ID,
+			"playlistURI": sess.FallbackPlaylistURI,
+			"error":       err.Error(),
+		})
+		return ""
+	}
+	return sess.NextFallbackTrackURI(playlistTrackURIs)
+}
+
+// recommendationTrackURIs は最近再生した曲をシードにしたおすすめの曲のURIを取得します。
+// 取得できなかった場合はnilを返します。
+func (s *SessionTimerUseCase) recommendationTrackURIs(ctx context.Context, sess *entity.Session) []string {
+	logger := log.New()
+
+	trackURIs, err := s.trackCli.GetRecommendationTrackURIs(ctx, sess.AutoplaySeedTrackURIs(), entity.AutoplayTrackCount)
+	if err != nil {
+		logger.Errorj(map[string]interface{}{
+			"message":   "recommendationTrackURIs: failed to get recommendations",
+			"sessionID": sess.ID,
+			"error":     err.Error(),
+		})
+		return nil
+	}
+	return trackURIs
+}
+
+// appendQueueTracksInTransaction は与えられた曲を、サーバが追加した曲としてキューの最後に追加し、Spotifyのキューにも追加します。
+func (s *SessionTimerUseCase) appendQueueTracksInTransaction(ctx context.Context, sess *entity.Session, trackURIs []string, addedByName string) (*handleTrackEndResponse, error) {
+	logger := log.New()
+
+	// StoreQueueTrackは最後の操作日時も更新するが、参加者の操作ではないので曲の終了を処理するときは
+	// 呼び出し元のUpdateWithoutActivityでセッションを取得したときの値に戻る
+	addedAt := time.Now().UTC()
+	for _, trackURI := range trackURIs {
+		queueTrack := &entity.QueueTrackToStore{
+			URI:         trackURI,
+			SessionID:   sess.ID,
+			AddedBy:     "",
+			AddedByName: addedByName,
+			AddedAt:     addedAt,
+		}
+		if err := s.sessionRepo.StoreQueueTrack(ctx, queueTrack, len(sess.QueueTracks)); err != nil {
+			return &handleTrackEndResponse{nextTrack: false}, fmt.Errorf("StoreQueueTrack URI=%s, sessionID=%s: %w", trackURI, sess.ID, err)
+		}
+		sess.AppendQueueTrack(queueTrack)
+	}
+
+	// headが最後の曲だったのでSpotifyのキューには何も追加されていない。
+	// 次のheadとその次の曲をここで追加し、さらにその次の曲はheadを進めた後にenqueueTrackInTransactionで追加する。
+	enqueueTrackURIs := trackURIs
+	if len(enqueueTrackURIs) > 2 {
+		enqueueTrackURIs = enqueueTrackURIs[:2]
+	}
+	for _, trackURI := range enqueueTrackURIs {
+		if err := s.playerCli.Enqueue(ctx, trackURI, sess.DeviceID); err != nil {
+			logger.Errorj(map[string]interface{}{
+				"message":   "appendQueueTracksInTransaction: failed to enqueue",
+				"sessionID": sess.ID,
+				"error":     err.Error(),
+			})
+			s.handleInterrupt(ctx, sess)
+			return &handleTrackEndResponse{nextTrack: false, err: nil}, nil
+		}
+	}
+
+	s.pusher.Push(&event.PushMessage{
+		SessionID: sess.ID,
+		Msg:       entity.EventAddTrack,
+	})
+	return nil, nil
+}
+
+// recordPlayback はEndHeadTrackやStartHeadTrackで記録したキューの曲の再生記録をDBに保存します。
+// 再生記録は履歴や統計のためのものなので、保存に失敗しても再生の処理は止めずにログに残すだけにしています。
+func (s *SessionTimerUseCase) recordPlayback(ctx context.Context, queueTrack *entity.QueueTrack) {
+	if queueTrack == nil {
+		return
+	}
+	if err := s.sessionRepo.UpdateQueueTrackPlayback(ctx, queueTrack); err != nil {
+		logger := log.New()
+		logger.Errorj(map[string]interface{}{
+			"message":   "recordPlayback: failed to update queue track playback",
+			"sessionID": queueTrack.SessionID,
+			"index":     queueTrack.Index,
+			"error":     err.Error(),
+		})
+	}
+}
+
 // handleAllTrackFinish はキューの全ての曲の再生が終わったときの処理を行います。
 func (s *SessionTimerUseCase) handleAllTrackFinish(sess *entity.Session) {
 	logger := log.New()
@@ -319,11 +492,49 @@ func (s *SessionTimerUseCase) handleAllTrackFinish(sess *entity.Session) {
 	})
 }
 
+// takeSessionToResetSpotifyQueue はキューの編集でSpotifyのキューを積み直す必要があるセッションの場合に、
+// 積み直しが必要なことの記録を消してセッションを返します。積み直す必要が無い場合はnilを返します。
+func takeSessionToResetSpotifyQueue(sess *entity.Session) *entity.Session {
+	if !sess.NeedsSpotifyQueueReset {
+		return nil
+	}
+	sess.NeedsSpotifyQueueReset = false
+	return sess
+}
+
+// resetSpotifyQueue は次の曲に進んだセッションに合わせて、Spotifyのキューを積み直します。
+// 全ての曲の再生が終わった場合は、Spotifyのキューに残っている編集前の曲が流れないように再生を止めます。
+// Spotifyを操作している間セッションのロックを持ち続けないように、トランザクションをコミットした後に呼び出してください。
+// 積み直しに失敗しても、次の曲の再生を確認するときにINTERRUPTとして扱われるので、ここではログを出すだけにしています。
+func (s *SessionTimerUseCase) resetSpotifyQueue(ctx context.Context, sess *entity.Session) {
+	logger := log.New()
+
+	if sess.StateType != entity.Play {
+		if err := s.playerCli.Pause(ctx, sess.DeviceID); err != nil && !errors.Is(err, entity.ErrActiveDeviceNotFound) {
+			logger.Errorj(map[string]interface{}{
+				"message":   "resetSpotifyQueue: failed to pause",
+				"sessionID": sess.ID,
+				"error":     err.Error(),
+			})
+		}
+		return
+	}
+
+	if err := rebuildSpotifyQueue(ctx, s.playerCli, sess, 0); err != nil {
+		logger.Errorj(map[string]interface{}{
+			"message":   "resetSpotifyQueue: failed to rebuild spotify queue",
+			"sessionID": sess.ID,
+			"error":     err.Error(),
+		})
+	}
+}
+
 // handleInterrupt はSpotifyとの同期が取れていないときの処理を行います。
-func (s *SessionTimerUseCase) handleInterrupt(sess *entity.Session) {
+func (s *SessionTimerUseCase) handleInterrupt(ctx context.Context, sess *entity.Session) {
 	logger := log.New()
 	logger.Debugj(map[string]interface{}{"message": "interrupt detected", "sessionID": sess.ID})
 
+	s.recordPlayback(ctx, sess.EndHeadTrack(time.Now().UTC(), entity.QueueTrackInterrupted))
 	sess.MoveToStop()
 
 	s.pusher.Push(&event.PushMessage{
@@ -352,6 +563,8 @@ func (s *SessionTimerUseCase) sendToNextCh(sessionID string) error {
 type handleTrackEndResponse struct {
 	nextTrack bool
 	err       error
+	// キューの編集でSpotifyのキューを積み直す必要がある場合は、トランザクションをコミットした後に積み直すセッション
+	sessionToResetSpotifyQueue *entity.Session
 }
 
 type currentOperation string
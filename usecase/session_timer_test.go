@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -20,10 +22,12 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 		name                     string
 		sessionID                string
 		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
+		prepareMockTrackCliFn    func(m *mock_spotify.MockTrackClient)
 		prepareMockPusherFn      func(m *mock_event.MockPusher)
 		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
 		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
 		wantNextTrack            bool
+		wantResetSpotifyQueue    bool
 		wantErr                  bool
 	}{
 		{
@@ -50,7 +54,8 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 						{},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackFinished}).Return(nil)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -95,7 +100,11 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 						},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -146,7 +155,11 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 						},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -199,7 +212,11 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 						},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -256,7 +273,7 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 						},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -280,6 +297,202 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 			wantNextTrack: false,
 			wantErr:       false,
 		},
+		{
+			name:      "自動再生が有効なセッションで最後の曲が再生し終わったときは、おすすめの曲を追加して再生を続ける",
+			sessionID: "sessionID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				gomock.InOrder(
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended1", "deviceID").Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended2", "deviceID").Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended3", "deviceID").Return(nil),
+				)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetRecommendationTrackURIs(gomock.Any(), []string{"spotify:track:track1", "spotify:track:track2"}, entity.AutoplayTrackCount).
+					Return([]string{"spotify:track:recommended1", "spotify:track:recommended2", "spotify:track:recommended3"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Play,
+					QueueHead: 1,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+					},
+					Autoplay: true,
+				}, nil)
+				gomock.InOrder(
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 3).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 4).Return(nil),
+				)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 2}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: true,
+			wantErr:       false,
+		},
+		{
+			name:                "自動再生が有効でもおすすめの曲を取得できなかったときはSTOPイベントが送られる",
+			sessionID:           "sessionID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetRecommendationTrackURIs(gomock.Any(), []string{"spotify:track:track1", "spotify:track:track2"}, entity.AutoplayTrackCount).
+					Return(nil, errors.New("unknown error"))
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventStop,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Play,
+					QueueHead: 1,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+					},
+					Autoplay: true,
+				}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackFinished}).Return(nil)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: false,
+			wantErr:       false,
+		},
+		{
+			name:      "ハウスプレイリストが設定されたセッションで最後の曲が再生し終わったときは、プレイリストのまだキューに入っていない曲を追加して再生を続ける",
+			sessionID: "sessionID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:playlist2", "deviceID").Return(nil)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlistID", entity.FallbackPlaylistTrackLimit).
+					Return([]string{"spotify:track:track1", "spotify:track:playlist2", "spotify:track:playlist3"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Play,
+					QueueHead: 1,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+					},
+					Autoplay:            true,
+					FallbackPlaylistURI: "spotify:playlist:playlistID",
+				}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 2}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: true,
+			wantErr:       false,
+		},
+		{
+			name:      "ハウスプレイリストの曲を取得できなかったときは、自動再生が有効ならおすすめの曲を追加する",
+			sessionID: "sessionID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended1", "deviceID").Return(nil)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlistID", entity.FallbackPlaylistTrackLimit).
+					Return(nil, errors.New("unknown error"))
+				m.EXPECT().GetRecommendationTrackURIs(gomock.Any(), []string{"spotify:track:track1", "spotify:track:track2"}, entity.AutoplayTrackCount).
+					Return([]string{"spotify:track:recommended1"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Play,
+					QueueHead: 1,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+					},
+					Autoplay:            true,
+					FallbackPlaylistURI: "spotify:playlist:playlistID",
+				}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 2}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: true,
+			wantErr:       false,
+		},
+		{
+			name:                  "Spotifyのキューを積み直す必要があるときは曲を追加せずに、コミットした後に積み直すセッションを返す",
+			sessionID:             "sessionID",
+			prepareMockPlayerFn:   func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					DeviceID:  "deviceID",
+					StateType: entity.Play,
+					QueueHead: 0,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+						{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+						{Index: 2, URI: "spotify:track:track3", SessionID: "sessionID"},
+					},
+					NeedsSpotifyQueueReset: true,
+				}, nil)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackFinished}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1}).Return(nil),
+				)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if sess.NeedsSpotifyQueueReset {
+						t.Errorf("UpdateWithoutActivity() NeedsSpotifyQueueReset = true, want false")
+					}
+					return nil
+				})
+			},
+			wantNextTrack:         true,
+			wantResetSpotifyQueue: true,
+			wantErr:               false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -287,6 +500,10 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 			defer ctrl.Finish()
 			mockPlayer := mock_spotify.NewMockPlayer(ctrl)
 			tt.prepareMockPlayerFn(mockPlayer)
+			mockTrackCli := mock_spotify.NewMockTrackClient(ctrl)
+			if tt.prepareMockTrackCliFn != nil {
+				tt.prepareMockTrackCliFn(mockTrackCli)
+			}
 			mockPusher := mock_event.NewMockPusher(ctrl)
 			tt.prepareMockPusherFn(mockPusher)
 			mockUserRepo := mock_repository.NewMockUser(ctrl)
@@ -302,7 +519,7 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 
 			syncCheckTimerManager := entity.NewSyncCheckTimerManager()
 
-			s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockPusher, syncCheckTimerManager)
+			s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockTrackCli, mockPusher, syncCheckTimerManager)
 			gotTriggerAfterTrackEndResponseInterface, err := s.handleTrackEndTx(tt.sessionID)(context.Background())
 
 			gotHandleTrackEndResponse, ok := gotTriggerAfterTrackEndResponseInterface.(*handleTrackEndResponse)
@@ -316,10 +533,257 @@ func TestSessionTimerUseCase_handleTrackEndTx(t *testing.T) {
 			if gotHandleTrackEndResponse.nextTrack != tt.wantNextTrack {
 				t.Errorf("handleTrackEnd() gotNextTrack = %v, want %v", gotHandleTrackEndResponse.nextTrack, tt.wantNextTrack)
 			}
+			if (gotHandleTrackEndResponse.sessionToResetSpotifyQueue != nil) != tt.wantResetSpotifyQueue {
+				t.Errorf("handleTrackEnd() sessionToResetSpotifyQueue = %v, wantResetSpotifyQueue %v", gotHandleTrackEndResponse.sessionToResetSpotifyQueue, tt.wantResetSpotifyQueue)
+			}
+		})
+	}
+}
+
+func TestSessionTimerUseCase_handleNextTx(t *testing.T) {
+	t.Parallel()
+
+	newSession := func(autoplay bool, fallbackPlaylistURI string) *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			DeviceID:  "deviceID",
+			StateType: entity.Play,
+			QueueHead: 1,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+			},
+			Autoplay:            autoplay,
+			FallbackPlaylistURI: fallbackPlaylistURI,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
+		prepareMockTrackCliFn    func(m *mock_spotify.MockTrackClient)
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantNextTrack            bool
+		wantResetSpotifyQueue    bool
+		wantErr                  bool
+	}{
+		{
+			name: "最後の曲をスキップしたときにSTOPイベントが送られる",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().GoNextTrack(gomock.Any(), "deviceID").Return(nil)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventStop,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(false, ""), nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackSkipped}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: false,
+			wantErr:       false,
+		},
+		{
+			name: "自動再生が有効なセッションで最後の曲をスキップしたときは、スキップする前におすすめの曲を追加して再生を続ける",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				gomock.InOrder(
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended1", "deviceID").Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended2", "deviceID").Return(nil),
+					m.EXPECT().GoNextTrack(gomock.Any(), "deviceID").Return(nil),
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:recommended3", "deviceID").Return(nil),
+				)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetRecommendationTrackURIs(gomock.Any(), []string{"spotify:track:track1", "spotify:track:track2"}, entity.AutoplayTrackCount).
+					Return([]string{"spotify:track:recommended1", "spotify:track:recommended2", "spotify:track:recommended3"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(true, ""), nil)
+				gomock.InOrder(
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 3).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 4).Return(nil),
+				)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackSkipped}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 2}).Return(nil),
+				)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: true,
+			wantErr:       false,
+		},
+		{
+			name: "ハウスプレイリストが設定されたセッションで最後の曲をスキップしたときは、スキップする前にプレイリストの曲を追加して再生を続ける",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				gomock.InOrder(
+					m.EXPECT().Enqueue(gomock.Any(), "spotify:track:playlist2", "deviceID").Return(nil),
+					m.EXPECT().GoNextTrack(gomock.Any(), "deviceID").Return(nil),
+				)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlistID", entity.FallbackPlaylistTrackLimit).
+					Return([]string{"spotify:track:track1", "spotify:track:playlist2"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(false, "spotify:playlist:playlistID"), nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 2).Return(nil)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackSkipped}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 2}).Return(nil),
+				)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack: true,
+			wantErr:       false,
+		},
+		{
+			name:                  "Spotifyのキューを積み直す必要があるときはSpotifyでスキップせずに、コミットした後に積み直すセッションを返す",
+			prepareMockPlayerFn:   func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				sess := newSession(false, "")
+				sess.QueueHead = 0
+				sess.NeedsSpotifyQueueReset = true
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil)
+				gomock.InOrder(
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 0, endReason: entity.QueueTrackSkipped}).Return(nil),
+					m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1}).Return(nil),
+				)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, sess *entity.Session) error {
+					if sess.NeedsSpotifyQueueReset {
+						t.Errorf("Update() NeedsSpotifyQueueReset = true, want false")
+					}
+					return nil
+				})
+			},
+			wantNextTrack:         true,
+			wantResetSpotifyQueue: true,
+			wantErr:               false,
+		},
+		{
+			name:                  "Spotifyのキューを積み直す必要があるときに最後の曲をスキップした場合も、Spotifyでスキップせずにコミットした後に再生を止めるセッションを返す",
+			prepareMockPlayerFn:   func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventStop,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				sess := newSession(false, "")
+				sess.NeedsSpotifyQueueReset = true
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackSkipped}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantNextTrack:         false,
+			wantResetSpotifyQueue: true,
+			wantErr:               false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockPlayer := mock_spotify.NewMockPlayer(ctrl)
+			tt.prepareMockPlayerFn(mockPlayer)
+			mockTrackCli := mock_spotify.NewMockTrackClient(ctrl)
+			tt.prepareMockTrackCliFn(mockTrackCli)
+			mockPusher := mock_event.NewMockPusher(ctrl)
+			tt.prepareMockPusherFn(mockPusher)
+			mockSessionRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockSessionRepoFn(mockSessionRepo)
+
+			s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockTrackCli, mockPusher, entity.NewSyncCheckTimerManager())
+			got, err := s.handleNextTx("sessionID")(context.Background())
+			if err != nil {
+				t.Fatalf("handleNextTx() error = %v", err)
+			}
+
+			res, ok := got.(*handleTrackEndResponse)
+			if !ok {
+				t.Fatal("handleNextTx() should return *handleTrackEndResponse")
+			}
+			if (res.err != nil) != tt.wantErr {
+				t.Errorf("handleNextTx() error = %v, wantErr %v", res.err, tt.wantErr)
+				return
+			}
+			if res.nextTrack != tt.wantNextTrack {
+				t.Errorf("handleNextTx() gotNextTrack = %v, want %v", res.nextTrack, tt.wantNextTrack)
+			}
+			if (res.sessionToResetSpotifyQueue != nil) != tt.wantResetSpotifyQueue {
+				t.Errorf("handleNextTx() sessionToResetSpotifyQueue = %v, wantResetSpotifyQueue %v", res.sessionToResetSpotifyQueue, tt.wantResetSpotifyQueue)
+			}
 		})
 	}
 }
 
+func TestSessionTimerUseCase_handleTrackEnd(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sess := &entity.Session{
+		ID:        "sessionID",
+		DeviceID:  "deviceID",
+		StateType: entity.Play,
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:track1", SessionID: "sessionID"},
+			{Index: 1, URI: "spotify:track:track2", SessionID: "sessionID"},
+			{Index: 2, URI: "spotify:track:track3", SessionID: "sessionID"},
+		},
+		NeedsSpotifyQueueReset: true,
+	}
+
+	mockSessionRepo := mock_repository.NewMockSession(ctrl)
+	mockPlayer := mock_spotify.NewMockPlayer(ctrl)
+	// Spotifyのキューはトランザクションをコミットした後に、次の曲から積み直す
+	gomock.InOrder(
+		mockSessionRepo.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+			return f(ctx)
+		}),
+		mockPlayer.EXPECT().DeleteAllTracksInQueue(gomock.Any(), "deviceID", "spotify:track:track2").Return(nil),
+		mockPlayer.EXPECT().PlayWithTracksAndPosition(gomock.Any(), "deviceID", []string{"spotify:track:track2"}, time.Duration(0)).Return(nil),
+		mockPlayer.EXPECT().Enqueue(gomock.Any(), "spotify:track:track3", "deviceID").Return(nil),
+	)
+	mockSessionRepo.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil)
+	mockSessionRepo.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockSessionRepo.EXPECT().UpdateWithoutActivity(gomock.Any(), gomock.Any()).Return(nil)
+
+	s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, nil, nil, entity.NewSyncCheckTimerManager())
+	nextTrack, err := s.handleTrackEnd(context.Background(), "sessionID")
+	if err != nil {
+		t.Fatalf("handleTrackEnd() error = %v", err)
+	}
+	if !nextTrack {
+		t.Errorf("handleTrackEnd() nextTrack = false, want true")
+	}
+}
+
 func TestSessionTimerUseCase_handleWaitTimerExpired(t *testing.T) {
 	tests := []struct {
 		name                     string
@@ -485,7 +949,8 @@ func TestSessionTimerUseCase_handleWaitTimerExpired(t *testing.T) {
 					AllowToControlByOthers: false,
 					ProgressWhenPaused:     0,
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), queueTrackPlaybackMatcher{index: 1, endReason: entity.QueueTrackInterrupted}).Return(nil)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "name",
 					CreatorID: "creatorID",
@@ -525,7 +990,7 @@ func TestSessionTimerUseCase_handleWaitTimerExpired(t *testing.T) {
 
 			syncCheckTimerManager := entity.NewSyncCheckTimerManager()
 
-			s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockPusher, syncCheckTimerManager)
+			s := NewSessionTimerUseCase(mockSessionRepo, mockPlayer, nil, mockPusher, syncCheckTimerManager)
 
 			triggerAfterTrackEnd := s.tm.CreateExpiredTimer(tt.sessionID)
 
@@ -535,3 +1000,28 @@ func TestSessionTimerUseCase_handleWaitTimerExpired(t *testing.T) {
 		})
 	}
 }
+
+// queueTrackPlaybackMatcher は再生記録を保存するQueueTrackをIndexと終了理由で比較するgomock.Matcherです。
+// 日時は実行した時刻になるので、endReasonが空のときは開始日時だけが、それ以外のときは終了日時が記録されているかを確認します。
+type queueTrackPlaybackMatcher struct {
+	index     int
+	endReason entity.QueueTrackEndReason
+}
+
+func (m queueTrackPlaybackMatcher) Matches(x interface{}) bool {
+	got, ok := x.(*entity.QueueTrack)
+	if !ok {
+		return false
+	}
+	if got.Index != m.index || got.EndReason != m.endReason {
+		return false
+	}
+	if m.endReason == "" {
+		return !got.StartedAt.IsZero() && got.EndedAt.IsZero()
+	}
+	return !got.EndedAt.IsZero()
+}
+
+func (m queueTrackPlaybackMatcher) String() string {
+	return fmt.Sprintf("is queue track index=%d with endReason=%q", m.index, m.endReason)
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/camphor-/relaym-server/config"
+	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/log"
 	"github.com/camphor-/relaym-server/usecase"
 	"github.com/labstack/echo/v4"
@@ -85,3 +87,66 @@ func (h *AuthHandler) Callback(c echo.Context) error {
 
 	return c.Redirect(http.StatusFound, redirectURL)
 }
+
+// PostGuest は POST /guests に対応するハンドラーです。
+// 有効なゲストのCookieがついている場合は新しいゲストを作らず、そのゲストを返します。
+func (h *AuthHandler) PostGuest(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Nickname string `json:"nickname"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	if guestCookie, err := c.Cookie("guest"); err == nil {
+		guest, err := h.authUC.GetGuestFromToken(guestCookie.Value)
+		if err == nil {
+			return c.JSON(http.StatusOK, &guestRes{
+				ID:       guest.ID,
+				Nickname: guest.Nickname,
+			})
+		}
+		if !errors.Is(err, entity.ErrGuestNotFound) {
+			logger.Errorj(map[string]interface{}{"message": "failed to get guest", "error": err.Error()})
+			return echo.NewHTTPError(http.StatusInternalServerError)
+		}
+	}
+
+	token, guest, err := h.authUC.CreateGuest(req.Nickname)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidNickname) {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrInvalidNickname.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to create guest", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	sameSite := http.SameSiteNoneMode
+	if config.IsLocal() {
+		sameSite = http.SameSiteLaxMode
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     "guest",
+		Value:    token,
+		Path:     "/",
+		MaxAge:   sevenDays,
+		Secure:   !config.IsLocal(),
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+
+	return c.JSON(http.StatusCreated, &guestRes{
+		ID:       guest.ID,
+		Nickname: guest.Nickname,
+	})
+}
+
+type guestRes struct {
+	ID       string `json:"id"`
+	Nickname string `json:"nickname"`
+}
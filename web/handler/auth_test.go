@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -345,3 +346,122 @@ func TestAuthHandler_Callback(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthHandler_PostGuest(t *testing.T) {
+	tests := []struct {
+		name                  string
+		body                  string
+		guestCookie           string
+		prepareMockAuthRepoFn func(mock *mock_repository.MockAuth)
+		wantErr               bool
+		wantCode              int
+		wantCookie            bool
+	}{
+		{
+			name:                  "ニックネームが空だと400",
+			body:                  `{"nickname": "  "}`,
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {},
+			wantErr:               true,
+			wantCode:              http.StatusBadRequest,
+			wantCookie:            false,
+		},
+		{
+			name:                  "ニックネームが長すぎると400",
+			body:                  `{"nickname": "1234567890123456789012345678901"}`,
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {},
+			wantErr:               true,
+			wantCode:              http.StatusBadRequest,
+			wantCookie:            false,
+		},
+		{
+			name: "ゲストの保存に失敗すると500",
+			body: `{"nickname": "nickname"}`,
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {
+				mock.EXPECT().StoreGuest(gomock.Any(), gomock.Any()).Return(errors.New("unknown error"))
+			},
+			wantErr:    true,
+			wantCode:   http.StatusInternalServerError,
+			wantCookie: false,
+		},
+		{
+			name: "ゲストが作成されてCookieがセットされ201",
+			body: `{"nickname": "nickname"}`,
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {
+				mock.EXPECT().StoreGuest(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr:    false,
+			wantCode:   http.StatusCreated,
+			wantCookie: true,
+		},
+		{
+			name:        "有効なゲストのCookieがついていると新しいゲストを作らずに200",
+			body:        `{"nickname": "nickname"}`,
+			guestCookie: "guest_token",
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {
+				mock.EXPECT().FindGuestByToken("guest_token").Return(&entity.Guest{ID: "guest_id", Nickname: "nickname"}, nil)
+			},
+			wantErr:    false,
+			wantCode:   http.StatusOK,
+			wantCookie: false,
+		},
+		{
+			name:        "期限切れのゲストのCookieがついているとゲストが作成されて201",
+			body:        `{"nickname": "nickname"}`,
+			guestCookie: "expired_guest_token",
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {
+				mock.EXPECT().FindGuestByToken("expired_guest_token").Return(nil, entity.ErrGuestNotFound)
+				mock.EXPECT().StoreGuest(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr:    false,
+			wantCode:   http.StatusCreated,
+			wantCookie: true,
+		},
+		{
+			name:        "ゲストの取得に失敗すると500",
+			body:        `{"nickname": "nickname"}`,
+			guestCookie: "guest_token",
+			prepareMockAuthRepoFn: func(mock *mock_repository.MockAuth) {
+				mock.EXPECT().FindGuestByToken("guest_token").Return(nil, errors.New("unknown error"))
+			},
+			wantErr:    true,
+			wantCode:   http.StatusInternalServerError,
+			wantCookie: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.guestCookie != "" {
+				req.AddCookie(&http.Cookie{Name: "guest", Value: tt.guestCookie})
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockAuthRepo := mock_repository.NewMockAuth(ctrl)
+			tt.prepareMockAuthRepoFn(mockAuthRepo)
+
+			h := &AuthHandler{authUC: usecase.NewAuthUseCase(nil, nil, mockAuthRepo, nil, nil)}
+			err := h.PostGuest(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostGuest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PostGuest() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			gotCookie := false
+			for _, cookie := range rec.Result().Cookies() {
+				if cookie.Name == "guest" && cookie.Value != "" && cookie.HttpOnly {
+					gotCookie = true
+				}
+			}
+			if gotCookie != tt.wantCookie {
+				t.Errorf("PostGuest() guest cookie = %v, want = %v", gotCookie, tt.wantCookie)
+			}
+		})
+	}
+}
@@ -21,9 +21,32 @@ func NewBatchHandler(uc *usecase.BatchUseCase) *BatchHandler {
 // PostArchive は POST /archive に対応するハンドラーです。
 func (h *BatchHandler) PostArchive(c echo.Context) error {
 	logger := log.New()
-	if err := h.uc.ArchiveOldSessions(); err != nil {
+	ids, err := h.uc.ArchiveOldSessions(c.Request().Context())
+	if err != nil {
 		logger.Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
-	return c.NoContent(http.StatusOK)
+	if ids == nil {
+		ids = []string{}
+	}
+	return c.JSON(http.StatusOK, &archiveRes{SessionIDs: ids})
+}
+
+type archiveRes struct {
+	SessionIDs []string `json:"session_ids"`
+}
+
+// PostDeleteExpiredGuests は POST /delete-expired-guests に対応するハンドラーです。
+func (h *BatchHandler) PostDeleteExpiredGuests(c echo.Context) error {
+	logger := log.New()
+	n, err := h.uc.DeleteExpiredGuests(c.Request().Context())
+	if err != nil {
+		logger.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, &deleteExpiredGuestsRes{DeletedCount: n})
+}
+
+type deleteExpiredGuestsRes struct {
+	DeletedCount int64 `json:"deleted_count"`
 }
@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"fmt"
+
+	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/labstack/echo/v4"
 	"golang.org/x/oauth2"
 )
@@ -13,3 +18,20 @@ func setToContext(c echo.Context, userID string, token *oauth2.Token) echo.Conte
 	c.SetRequest(c.Request().WithContext(ctx))
 	return c
 }
+
+// queueTrackToStoreMatcher は追加日時以外のフィールドが一致するかどうかでQueueTrackToStoreを比較するgomock.Matcherです。
+type queueTrackToStoreMatcher struct {
+	want *entity.QueueTrackToStore
+}
+
+func (m queueTrackToStoreMatcher) Matches(x interface{}) bool {
+	got, ok := x.(*entity.QueueTrackToStore)
+	if !ok {
+		return false
+	}
+	return cmp.Equal(got, m.want, cmpopts.IgnoreFields(entity.QueueTrackToStore{}, "AddedAt"))
+}
+
+func (m queueTrackToStoreMatcher) String() string {
+	return fmt.Sprintf("is equal to %v except AddedAt", m.want)
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"encoding/csv"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/camphor-/relaym-server/log"
 
@@ -15,21 +21,27 @@ import (
 
 // SessionHandler は /sessions 以下のエンドポイントを管理する構造体です。
 type SessionHandler struct {
-	uc      *usecase.SessionUseCase
-	stateUC *usecase.SessionStateUseCase
+	uc                  *usecase.SessionUseCase
+	stateUC             *usecase.SessionStateUseCase
+	maxTracksPerEnqueue int
 }
 
 // NewSessionHandler はSessionHandlerのポインタを生成する関数です。
-func NewSessionHandler(uc *usecase.SessionUseCase, stateUC *usecase.SessionStateUseCase) *SessionHandler {
-	return &SessionHandler{uc: uc, stateUC: stateUC}
+func NewSessionHandler(uc *usecase.SessionUseCase, stateUC *usecase.SessionStateUseCase, maxTracksPerEnqueue int) *SessionHandler {
+	return &SessionHandler{uc: uc, stateUC: stateUC, maxTracksPerEnqueue: maxTracksPerEnqueue}
 }
 
 // PostSession は POST /sessions に対応するハンドラーです。
 func (h *SessionHandler) PostSession(c echo.Context) error {
 	logger := log.New()
 	type reqJSON struct {
-		Name                   string `json:"name"`
-		AllowToControlByOthers bool   `json:"allow_to_control_by_others"`
+		Name                   string                 `json:"name"`
+		AllowToControlByOthers bool                   `json:"allow_to_control_by_others"`
+		QueueOrderType         string                 `json:"queue_order_type"`
+		SkipVoteThreshold      *skipVoteThresholdJSON `json:"skip_vote_threshold"`
+		Autoplay               bool                   `json:"autoplay"`
+		FallbackPlaylistURI    string                 `json:"fallback_playlist_uri"`
+		Expiration             *expirationJSON        `json:"expiration"`
 	}
 	req := new(reqJSON)
 	if err := c.Bind(req); err != nil {
@@ -37,14 +49,53 @@ func (h *SessionHandler) PostSession(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 
-	sessionName := req.Name
-	if sessionName == "" {
+	if req.Name == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "empty name")
 	}
 
+	settings := entity.SessionSettings{
+		Name:                   &req.Name,
+		AllowToControlByOthers: &req.AllowToControlByOthers,
+		Autoplay:               &req.Autoplay,
+	}
+	if req.QueueOrderType != "" {
+		qot, err := entity.NewQueueOrderType(req.QueueOrderType)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid queue order type")
+		}
+		settings.QueueOrderType = &qot
+	}
+	if req.SkipVoteThreshold != nil {
+		svt, err := entity.NewSkipVoteThreshold(req.SkipVoteThreshold.Type, req.SkipVoteThreshold.Value)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid skip vote threshold")
+		}
+		settings.SkipVoteThreshold = &svt
+	}
+	if req.FallbackPlaylistURI != "" {
+		uri, err := entity.NormalizePlaylistURI(req.FallbackPlaylistURI)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid fallback playlist uri")
+		}
+		settings.FallbackPlaylistURI = &uri
+	}
+
+	expirationPolicy := entity.DefaultExpirationPolicy
+	if req.Expiration != nil {
+		ep, err := entity.NewExpirationPolicy(req.Expiration.Type, req.Expiration.Hours)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid expiration")
+		}
+		expirationPolicy = ep
+	}
+
 	ctx := c.Request().Context()
 	userID, _ := service.GetUserIDFromContext(ctx)
-	session, err := h.uc.CreateSession(ctx, sessionName, userID, req.AllowToControlByOthers)
+	session, err := h.uc.CreateSession(ctx, userID, settings, expirationPolicy)
 	if err != nil {
 		logger.Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError)
@@ -53,6 +104,55 @@ func (h *SessionHandler) PostSession(c echo.Context) error {
 	return c.JSON(http.StatusCreated, h.toSessionRes(session, nil, nil))
 }
 
+// CloneSession は POST /sessions/:id/clone に対応するハンドラーです。
+func (h *SessionHandler) CloneSession(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Name                   string `json:"name"`
+		AllowToControlByOthers *bool  `json:"allow_to_control_by_others"`
+		Queue                  string `json:"queue"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid clone request")
+	}
+
+	queue, err := entity.NewSessionCloneQueue(req.Queue)
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid queue")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+	userID, _ := service.GetUserIDFromContext(ctx)
+
+	opt := entity.SessionCloneOption{
+		Name:                   req.Name,
+		AllowToControlByOthers: req.AllowToControlByOthers,
+		Queue:                  queue,
+	}
+	session, err := h.uc.CloneSession(ctx, sessionID, userID, opt)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionMember):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionMember.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to clone session", "error": err.Error(), "sessionID": sessionID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, h.toSessionRes(session, nil, nil))
+}
+
 // GetSession は GET /sessions/:id に対応するハンドラーです。
 func (h *SessionHandler) GetSession(c echo.Context) error {
 	logger := log.New()
@@ -71,11 +171,462 @@ func (h *SessionHandler) GetSession(c echo.Context) error {
 	return c.JSON(http.StatusOK, h.toSessionRes(session, playingInfo, tracks))
 }
 
+// PatchSession は PATCH /sessions/:id に対応するハンドラーです。
+func (h *SessionHandler) PatchSession(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Name                   *string                `json:"name"`
+		Description            *string                `json:"description"`
+		AllowToControlByOthers *bool                  `json:"allow_to_control_by_others"`
+		QueueOrderType         *string                `json:"queue_order_type"`
+		SkipVoteThreshold      *skipVoteThresholdJSON `json:"skip_vote_threshold"`
+		Autoplay               *bool                  `json:"autoplay"`
+		FallbackPlaylistURI    *string                `json:"fallback_playlist_uri"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	settings := entity.SessionSettings{
+		Name:                   req.Name,
+		Description:            req.Description,
+		AllowToControlByOthers: req.AllowToControlByOthers,
+		Autoplay:               req.Autoplay,
+	}
+	if req.Name != nil && *req.Name == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "empty name")
+	}
+	if req.Description != nil && entity.MaxSessionDescriptionLength < utf8.RuneCountInString(*req.Description) {
+		return echo.NewHTTPError(http.StatusBadRequest, "too long description")
+	}
+	if req.QueueOrderType != nil {
+		qot, err := entity.NewQueueOrderType(*req.QueueOrderType)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid queue order type")
+		}
+		settings.QueueOrderType = &qot
+	}
+	if req.SkipVoteThreshold != nil {
+		svt, err := entity.NewSkipVoteThreshold(req.SkipVoteThreshold.Type, req.SkipVoteThreshold.Value)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid skip vote threshold")
+		}
+		settings.SkipVoteThreshold = &svt
+	}
+	if req.FallbackPlaylistURI != nil {
+		fallbackPlaylistURI := ""
+		if *req.FallbackPlaylistURI != "" {
+			uri, err := entity.NormalizePlaylistURI(*req.FallbackPlaylistURI)
+			if err != nil {
+				logger.Debug(err)
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid fallback playlist uri")
+			}
+			fallbackPlaylistURI = uri
+		}
+		settings.FallbackPlaylistURI = &fallbackPlaylistURI
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.UpdateSettings(ctx, sessionID, settings); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to update session settings", "error": err.Error(), "sessionID": sessionID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteSession は DELETE /sessions/:id に対応するハンドラーです。
+func (h *SessionHandler) DeleteSession(c echo.Context) error {
+	logger := log.New()
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.DeleteSession(ctx, sessionID); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to delete session", "error": err.Error(), "sessionID": sessionID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetListeners は GET /sessions/:id/listeners に対応するハンドラーです。
+func (h *SessionHandler) GetListeners(c echo.Context) error {
+	logger := log.New()
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	listeners, err := h.stateUC.GetListeners(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, entity.ErrSessionNotFound) {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to get listeners", "error": err.Error(), "sessionID": sessionID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, &listenersRes{
+		Listeners: listeners,
+		Count:     len(listeners),
+	})
+}
+
+// PutMember は PUT /sessions/:id/members/:user_id に対応するハンドラーです。
+func (h *SessionHandler) PutMember(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Role string `json:"role"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest)
+	}
+
+	role, err := entity.NewSessionRole(req.Role)
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+	memberUserID := c.Param("user_id")
+
+	if err := h.uc.SetMemberRole(ctx, sessionID, memberUserID, role); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrCreatorRoleNotEditable):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "creator role is not editable")
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrUserNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to set member role", "error": err.Error(), "sessionID": sessionID, "userID": memberUserID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// PostBan は POST /sessions/:id/bans に対応するハンドラーです。
+func (h *SessionHandler) PostBan(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		ParticipantID     string `json:"participant_id"`
+		RemoveQueueTracks bool   `json:"remove_queue_tracks"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid participant id")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.BanParticipant(ctx, sessionID, req.ParticipantID, req.RemoveQueueTracks); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidParticipantID):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid participant id")
+		case errors.Is(err, entity.ErrCreatorCannotBeBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrCreatorCannotBeBanned.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to ban participant", "error": err.Error(), "sessionID": sessionID, "participantID": req.ParticipantID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetSessionIDByJoinCode は GET /join/:code に対応するハンドラーです。
+func (h *SessionHandler) GetSessionIDByJoinCode(c echo.Context) error {
+	logger := log.New()
+	ctx := c.Request().Context()
+
+	code, err := entity.NormalizeJoinCode(c.Param("code"))
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid join code")
+	}
+
+	id, err := h.uc.GetSessionIDByJoinCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, entity.ErrSessionNotFound) {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to get session id by join code", "error": err.Error(), "code": code})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, &joinRes{SessionID: id})
+}
+
+// GetMySessions は GET /users/me/sessions に対応するハンドラーです。
+func (h *SessionHandler) GetMySessions(c echo.Context) error {
+	logger := log.New()
+	ctx := c.Request().Context()
+
+	filter := entity.SessionListFilter{
+		Name:  c.QueryParam("name"),
+		Limit: entity.DefaultSessionListLimit,
+	}
+	if state := c.QueryParam("state"); state != "" {
+		for _, s := range strings.Split(state, ",") {
+			st, err := entity.NewStateType(s)
+			if err != nil {
+				logger.Debug(err)
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid state")
+			}
+			filter.StateTypes = append(filter.StateTypes, st)
+		}
+	}
+	if cursor := c.QueryParam("cursor"); cursor != "" {
+		cur, err := entity.DecodeSessionListCursor(cursor)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.Cursor = cur
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		filter.Limit = l
+	}
+
+	sessions, next, err := h.uc.GetMySessions(ctx, filter)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidSessionListLimit) {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid limit")
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to get my sessions", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	res := &sessionsRes{Sessions: make([]*sessionSummaryJSON, len(sessions))}
+	for i, s := range sessions {
+		res.Sessions[i] = &sessionSummaryJSON{
+			ID:                     s.ID,
+			Name:                   s.Name,
+			State:                  s.StateType.String(),
+			AllowToControlByOthers: s.AllowToControlByOthers,
+			Creator: creatorJSON{
+				ID:          s.Creator.ID,
+				DisplayName: s.Creator.DisplayName,
+			},
+			LastActivityAt: s.LastActivityAt,
+		}
+	}
+	if next != nil {
+		cursor := next.Encode()
+		res.NextCursor = &cursor
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+const (
+	historyFormatJSON = "json"
+	historyFormatCSV  = "csv"
+	historyFormatM3U  = "m3u"
+)
+
+// GetHistory は GET /sessions/:id/history に対応するハンドラーです。
+func (h *SessionHandler) GetHistory(c echo.Context) error {
+	logger := log.New()
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = historyFormatJSON
+	}
+	if format != historyFormatJSON && format != historyFormatCSV && format != historyFormatM3U {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid format")
+	}
+
+	session, history, err := h.uc.GetHistory(ctx, id)
+	if err != nil {
+		if errors.Is(err, entity.ErrSessionNotFound) {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	switch format {
+	case historyFormatCSV:
+		h.writeHistoryCSV(c, session, history)
+		return nil
+	case historyFormatM3U:
+		h.writeHistoryM3U(c, session, history)
+		return nil
+	}
+
+	tracks := make([]*historyTrackJSON, len(history))
+	for i, ht := range history {
+		tracks[i] = toHistoryTrackJSON(ht)
+	}
+	return c.JSON(http.StatusOK, &historyRes{
+		ID:        session.ID,
+		Name:      session.Name,
+		QueueHead: session.QueueHead,
+		Tracks:    tracks,
+	})
+}
+
+// writeHistoryCSV はセッションの履歴をCSV形式で1曲ずつレスポンスに書き込みます。
+// レスポンスのヘッダーを送った後にエラーが起きた場合はステータスコードを変更できないので、ログに残すだけにしています。
+func (h *SessionHandler) writeHistoryCSV(c echo.Context, session *entity.Session, history []*entity.HistoryTrack) {
+	logger := log.New()
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=UTF-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.csv"`, session.ID))
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	header := []string{"index", "status", "uri", "name", "artists", "album", "duration_ms", "external_url", "added_by", "added_at", "started_at", "ended_at", "end_reason"}
+	if err := w.Write(header); err != nil {
+		logger.Errorj(map[string]interface{}{"message": "failed to write history csv header", "error": err.Error(), "sessionID": session.ID})
+		return
+	}
+
+	for _, ht := range history {
+		tj := toHistoryTrackJSON(ht)
+		albumName := ""
+		if tj.Album != nil {
+			albumName = tj.Album.Name
+		}
+		record := []string{
+			strconv.Itoa(tj.Index),
+			tj.Status,
+			tj.URI,
+			tj.Name,
+			historyArtistNames(ht.Track),
+			albumName,
+			strconv.FormatInt(tj.Duration, 10),
+			tj.URL,
+			tj.AddedBy.DisplayName,
+			tj.AddedAt.Format(time.RFC3339),
+			historyTimeString(tj.StartedAt),
+			historyTimeString(tj.EndedAt),
+			tj.EndReason,
+		}
+		if err := w.Write(record); err != nil {
+			logger.Errorj(map[string]interface{}{"message": "failed to write history csv record", "error": err.Error(), "sessionID": session.ID})
+			return
+		}
+		w.Flush()
+		res.Flush()
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logger.Errorj(map[string]interface{}{"message": "failed to flush history csv", "error": err.Error(), "sessionID": session.ID})
+	}
+}
+
+// writeHistoryM3U はセッションの履歴を拡張M3U形式で1曲ずつレスポンスに書き込みます。
+// 各曲のパスにはSpotifyのwebページのURLを使い、取得できなかった場合はURIを使います。
+func (h *SessionHandler) writeHistoryM3U(c echo.Context, session *entity.Session, history []*entity.HistoryTrack) {
+	logger := log.New()
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "audio/x-mpegurl; charset=UTF-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.m3u"`, session.ID))
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "#EXTM3U\n#PLAYLIST:%s\n", session.Name); err != nil {
+		logger.Errorj(map[string]interface{}{"message": "failed to write history m3u header", "error": err.Error(), "sessionID": session.ID})
+		return
+	}
+
+	for _, ht := range history {
+		title := ht.QueueTrack.URI
+		seconds := -1
+		path := ht.QueueTrack.URI
+		if ht.Track != nil {
+			title = fmt.Sprintf("%s - %s", historyArtistNames(ht.Track), ht.Track.Name)
+			seconds = int(ht.Track.Duration.Seconds())
+			if ht.Track.URL != "" {
+				path = ht.Track.URL
+			}
+		}
+		if _, err := fmt.Fprintf(res, "#EXTINF:%d,%s\n%s\n", seconds, title, path); err != nil {
+			logger.Errorj(map[string]interface{}{"message": "failed to write history m3u entry", "error": err.Error(), "sessionID": session.ID})
+			return
+		}
+		res.Flush()
+	}
+}
+
+// historyTimeString は履歴の日時をCSV用の文字列に変換します。記録されていない場合は空文字列を返します。
+func historyTimeString(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// historyArtistNames はアーティスト名をカンマ区切りで連結して返します。
+func historyArtistNames(track *entity.Track) string {
+	if track == nil {
+		return ""
+	}
+	names := make([]string, len(track.Artists))
+	for i, artist := range track.Artists {
+		names[i] = artist.Name
+	}
+	return strings.Join(names, ", ")
+}
+
 // Enqueue は POST /sessions/:id/queue に対応するハンドラーです。
 func (h *SessionHandler) Enqueue(c echo.Context) error {
 	logger := log.New()
 	type reqJSON struct {
-		URI string `json:"uri"`
+		URI      string   `json:"uri"`
+		URIs     []string `json:"uris"`
+		Position string   `json:"position"`
 	}
 	req := new(reqJSON)
 	if err := c.Bind(req); err != nil {
@@ -83,15 +634,38 @@ func (h *SessionHandler) Enqueue(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid track id")
 	}
 
-	if req.URI == "" {
+	uris := req.URIs
+	if req.URI != "" {
+		uris = append([]string{req.URI}, uris...)
+	}
+	if len(uris) == 0 {
 		return echo.NewHTTPError(http.StatusBadRequest, "invalid track id")
 	}
 
+	position, err := entity.NewEnqueuePosition(req.Position)
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid position")
+	}
+
 	ctx := c.Request().Context()
 	sessionID := c.Param("id")
 
-	if err := h.uc.EnqueueTrack(ctx, sessionID, req.URI); err != nil {
-		if errors.Is(err, entity.ErrSessionNotFound) {
+	if err := h.uc.EnqueueTracks(ctx, sessionID, uris, position, h.maxTracksPerEnqueue); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrInvalidSpotifyURI):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid track id")
+		case errors.Is(err, entity.ErrTrackNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrTrackNotFound.Error())
+		case errors.Is(err, entity.ErrTooManyTracksToEnqueue):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrTooManyTracksToEnqueue.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
 			logger.Debug(err)
 			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
 		}
@@ -102,6 +676,133 @@ func (h *SessionHandler) Enqueue(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// DeleteQueueTrack は DELETE /sessions/:id/queue/:index に対応するハンドラーです。
+func (h *SessionHandler) DeleteQueueTrack(c echo.Context) error {
+	logger := log.New()
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid index")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.RemoveQueueTrack(ctx, sessionID, index); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotAllowToControlOthers):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrSessionNotAllowToControlOthers.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotEditable):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrQueueTrackNotEditable.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrQueueTrackNotFound.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to remove queue track", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MoveQueueTrack は PUT /sessions/:id/queue/:index/position に対応するハンドラーです。
+func (h *SessionHandler) MoveQueueTrack(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Position *int `json:"position"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid position")
+	}
+
+	if req.Position == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid position")
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid index")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.MoveQueueTrack(ctx, sessionID, index, *req.Position); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotAllowToControlOthers):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrSessionNotAllowToControlOthers.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotEditable):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrQueueTrackNotEditable.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrQueueTrackNotFound.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to move queue track", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ToggleQueueTrackVote は POST /sessions/:id/queue/:index/vote に対応するハンドラーです。
+func (h *SessionHandler) ToggleQueueTrackVote(c echo.Context) error {
+	logger := log.New()
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid index")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if service.GetParticipantIDFromContext(ctx) == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized)
+	}
+
+	voted, queueTrack, err := h.uc.ToggleQueueTrackVote(ctx, sessionID, index)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotEditable):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrQueueTrackNotEditable.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrQueueTrackNotFound.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to toggle queue track vote", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusOK, &queueTrackVoteRes{
+		Voted: voted,
+		Index: queueTrack.Index,
+		Votes: queueTrack.Votes,
+	})
+}
+
 // NextTrack は PUT /sessions/:id/next に対応するハンドラーです。
 func (h *SessionHandler) NextTrack(c echo.Context) error {
 	logger := log.New()
@@ -113,6 +814,9 @@ func (h *SessionHandler) NextTrack(c echo.Context) error {
 		switch {
 		case errors.Is(err, entity.ErrSessionNotAllowToControlOthers):
 			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrSessionNotAllowToControlOthers.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
 		case errors.Is(err, entity.ErrChangeSessionStateNotPermit):
 			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrChangeSessionStateNotPermit.Error())
 		case errors.Is(err, entity.ErrNextQueueTrackNotFound):
@@ -130,6 +834,42 @@ func (h *SessionHandler) NextTrack(c echo.Context) error {
 	return c.NoContent(http.StatusAccepted)
 }
 
+// PostSkipVote は POST /sessions/:id/skip-votes に対応するハンドラーです。
+func (h *SessionHandler) PostSkipVote(c echo.Context) error {
+	logger := log.New()
+
+	ctx := c.Request().Context()
+	id := c.Param("id")
+
+	if service.GetParticipantIDFromContext(ctx) == "" {
+		return echo.NewHTTPError(http.StatusUnauthorized)
+	}
+
+	skipVote, err := h.stateUC.VoteToSkip(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		case errors.Is(err, entity.ErrSkipVoteAlreadyExisted):
+			return echo.NewHTTPError(http.StatusConflict, entity.ErrSkipVoteAlreadyExisted.Error())
+		case errors.Is(err, entity.ErrChangeSessionStateNotPermit):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrChangeSessionStateNotPermit.Error())
+		case errors.Is(err, entity.ErrQueueTrackNotFound):
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrQueueTrackNotFound.Error())
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrActiveDeviceNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrActiveDeviceNotFound.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to vote to skip", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+	return c.JSON(http.StatusOK, skipVote)
+}
+
 // State は PUT /sessions/:id/state に対応するハンドラーです。
 func (h *SessionHandler) State(c echo.Context) error {
 	logger := log.New()
@@ -160,6 +900,9 @@ func (h *SessionHandler) State(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrChangeSessionStateNotPermit.Error())
 		case errors.Is(err, entity.ErrSessionNotAllowToControlOthers):
 			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrSessionNotAllowToControlOthers.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
 		case errors.Is(err, entity.ErrSessionNotFound):
 			logger.Debug(err)
 			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
@@ -210,6 +953,50 @@ func (h *SessionHandler) SetDevice(c echo.Context) error {
 	sessionID := c.Param("id")
 
 	if err := h.uc.SetDevice(ctx, sessionID, req.DeviceID); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrSessionNotAllowToControlOthers):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrSessionNotAllowToControlOthers.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to set device", "error": err.Error(), "deviceID": req.DeviceID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// SetFallbackPlaylist PUT /sessions/:id/fallback-playlistに対応するハンドラーです。
+func (h *SessionHandler) SetFallbackPlaylist(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		URI string `json:"uri"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid fallback playlist uri")
+	}
+
+	playlistURI := ""
+	if req.URI != "" {
+		uri, err := entity.NormalizePlaylistURI(req.URI)
+		if err != nil {
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid fallback playlist uri")
+		}
+		playlistURI = uri
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	if err := h.uc.SetFallbackPlaylist(ctx, sessionID, playlistURI); err != nil {
 		switch {
 		case errors.Is(err, entity.ErrSessionNotFound):
 			logger.Debug(err)
@@ -217,15 +1004,61 @@ func (h *SessionHandler) SetDevice(c echo.Context) error {
 		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
 			logger.Debug(err)
 			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
-
 		}
-		logger.Errorj(map[string]interface{}{"message": "failed to set device", "error": err.Error(), "deviceID": req.DeviceID})
+		logger.Errorj(map[string]interface{}{"message": "failed to set fallback playlist", "error": err.Error(), "playlistURI": playlistURI})
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// ExportPlaylist POST /sessions/:id/export/playlistに対応するハンドラーです。
+func (h *SessionHandler) ExportPlaylist(c echo.Context) error {
+	logger := log.New()
+	type reqJSON struct {
+		Target string `json:"target"`
+		Name   string `json:"name"`
+		Public bool   `json:"public"`
+	}
+	req := new(reqJSON)
+	if err := c.Bind(req); err != nil {
+		logger.Debugj(map[string]interface{}{"message": "failed to bind", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid target")
+	}
+
+	target, err := entity.NewPlaylistExportTarget(req.Target)
+	if err != nil {
+		logger.Debug(err)
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid target")
+	}
+
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	playlist, err := h.uc.ExportPlaylist(ctx, sessionID, target, req.Name, req.Public)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrUserIsNotSessionCreator):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrUserIsNotSessionCreator.Error())
+		case errors.Is(err, entity.ErrNoTracksToExport):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusBadRequest, entity.ErrNoTracksToExport.Error())
+		}
+		logger.Errorj(map[string]interface{}{"message": "failed to export playlist", "error": err.Error(), "sessionID": sessionID})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	return c.JSON(http.StatusCreated, &playlistRes{
+		ID:  playlist.ID,
+		URI: playlist.URI,
+		URL: playlist.URL,
+	})
+}
+
 func (h *SessionHandler) toSessionRes(session *entity.SessionWithUser, info *entity.CurrentPlayingInfo, tracks []*entity.Track) *sessionRes {
 	var dJ *deviceJSON = nil
 	if info != nil && info.Device != nil {
@@ -256,7 +1089,20 @@ func (h *SessionHandler) toSessionRes(session *entity.SessionWithUser, info *ent
 	return &sessionRes{
 		ID:                     session.ID,
 		Name:                   session.Name,
+		Description:            session.Description,
+		JoinCode:               session.JoinCode,
 		AllowToControlByOthers: session.AllowToControlByOthers,
+		QueueOrderType:         session.QueueOrderType.String(),
+		SkipVoteThreshold: skipVoteThresholdJSON{
+			Type:  session.SkipVoteThreshold.Type.String(),
+			Value: session.SkipVoteThreshold.Value,
+		},
+		Autoplay:            session.Autoplay,
+		FallbackPlaylistURI: session.FallbackPlaylistURI,
+		Expiration: expirationJSON{
+			Type:  session.ExpirationPolicy.Type.String(),
+			Hours: session.ExpirationPolicy.Hours,
+		},
 		Creator: creatorJSON{
 			ID:          session.Creator.ID,
 			DisplayName: session.Creator.DisplayName,
@@ -267,18 +1113,64 @@ func (h *SessionHandler) toSessionRes(session *entity.SessionWithUser, info *ent
 		},
 		Queue: queueJSON{
 			Head:   session.QueueHead,
-			Tracks: toTrackJSON(tracks),
+			Tracks: toQueueTrackJSON(session.QueueTracks, tracks),
 		},
 	}
 }
 
 type sessionRes struct {
-	ID                     string       `json:"id"`
-	Name                   string       `json:"name"`
-	AllowToControlByOthers bool         `json:"allow_to_control_by_others"`
-	Creator                creatorJSON  `json:"creator"`
-	Playback               playbackJSON `json:"playback"`
-	Queue                  queueJSON    `json:"queue"`
+	ID                     string                `json:"id"`
+	Name                   string                `json:"name"`
+	Description            string                `json:"description"`
+	JoinCode               string                `json:"join_code"`
+	AllowToControlByOthers bool                  `json:"allow_to_control_by_others"`
+	QueueOrderType         string                `json:"queue_order_type"`
+	SkipVoteThreshold      skipVoteThresholdJSON `json:"skip_vote_threshold"`
+	Autoplay               bool                  `json:"autoplay"`
+	FallbackPlaylistURI    string                `json:"fallback_playlist_uri"`
+	Expiration             expirationJSON        `json:"expiration"`
+	Creator                creatorJSON           `json:"creator"`
+	Playback               playbackJSON          `json:"playback"`
+	Queue                  queueJSON             `json:"queue"`
+}
+
+type sessionsRes struct {
+	Sessions   []*sessionSummaryJSON `json:"sessions"`
+	NextCursor *string               `json:"next_cursor"`
+}
+
+type sessionSummaryJSON struct {
+	ID                     string      `json:"id"`
+	Name                   string      `json:"name"`
+	State                  string      `json:"state"`
+	AllowToControlByOthers bool        `json:"allow_to_control_by_others"`
+	Creator                creatorJSON `json:"creator"`
+	LastActivityAt         time.Time   `json:"last_activity_at"`
+}
+
+type joinRes struct {
+	SessionID string `json:"session_id"`
+}
+
+type listenersRes struct {
+	Listeners []*entity.Listener `json:"listeners"`
+	Count     int                `json:"count"`
+}
+
+type playlistRes struct {
+	ID  string `json:"id"`
+	URI string `json:"uri"`
+	URL string `json:"url"`
+}
+
+type skipVoteThresholdJSON struct {
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+}
+
+type expirationJSON struct {
+	Type  string `json:"type"`
+	Hours int    `json:"hours"`
 }
 
 type creatorJSON struct {
@@ -298,6 +1190,93 @@ type stateJSON struct {
 }
 
 type queueJSON struct {
-	Head   int          `json:"head"`
-	Tracks []*trackJSON `json:"tracks"`
+	Head   int               `json:"head"`
+	Tracks []*queueTrackJSON `json:"tracks"`
+}
+
+type queueTrackJSON struct {
+	trackJSON
+	AddedBy addedByJSON `json:"added_by"`
+	AddedAt time.Time   `json:"added_at"`
+	Votes   int         `json:"votes"`
+}
+
+type historyRes struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	QueueHead int                 `json:"queue_head"`
+	Tracks    []*historyTrackJSON `json:"tracks"`
+}
+
+type historyTrackJSON struct {
+	Index     int        `json:"index"`
+	Status    string     `json:"status"`
+	StartedAt *time.Time `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+	EndReason string     `json:"end_reason"`
+	queueTrackJSON
+}
+
+// toHistoryTrackJSON は履歴の曲をレスポンス用の構造体に変換します。
+// Spotifyから曲の情報を取得できなかった場合は、URIとキューの曲の情報のみを返します。
+func toHistoryTrackJSON(ht *entity.HistoryTrack) *historyTrackJSON {
+	tj := trackJSON{URI: ht.QueueTrack.URI, Artists: []*artistJSON{}}
+	if ht.Track != nil {
+		tj = *toTrackJSON([]*entity.Track{ht.Track})[0]
+	}
+	return &historyTrackJSON{
+		Index:     ht.QueueTrack.Index,
+		Status:    ht.Status.String(),
+		StartedAt: toNullableTime(ht.QueueTrack.StartedAt),
+		EndedAt:   toNullableTime(ht.QueueTrack.EndedAt),
+		EndReason: ht.QueueTrack.EndReason.String(),
+		queueTrackJSON: queueTrackJSON{
+			trackJSON: tj,
+			AddedBy: addedByJSON{
+				ID:          ht.QueueTrack.AddedBy,
+				DisplayName: ht.QueueTrack.AddedByName,
+			},
+			AddedAt: ht.QueueTrack.AddedAt,
+			Votes:   ht.QueueTrack.Votes,
+		},
+	}
+}
+
+// toNullableTime はゼロ値の日時をJSONでnullにするためにnilに変換します。
+func toNullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+type queueTrackVoteRes struct {
+	Voted bool `json:"voted"`
+	Index int  `json:"index"`
+	Votes int  `json:"votes"`
+}
+
+type addedByJSON struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+}
+
+// toQueueTrackJSON はキューの曲の情報とSpotifyから取得した曲の情報を合わせてレスポンス用の構造体に変換します。
+// tracksはqueueTracksと同じ順番で並んでいる必要があります。
+func toQueueTrackJSON(queueTracks []*entity.QueueTrack, tracks []*entity.Track) []*queueTrackJSON {
+	trackJSONs := toTrackJSON(tracks)
+	queueTrackJSONs := make([]*queueTrackJSON, len(trackJSONs))
+
+	for i, tj := range trackJSONs {
+		queueTrackJSONs[i] = &queueTrackJSON{trackJSON: *tj}
+		if i < len(queueTracks) {
+			queueTrackJSONs[i].AddedBy = addedByJSON{
+				ID:          queueTracks[i].AddedBy,
+				DisplayName: queueTracks[i].AddedByName,
+			}
+			queueTrackJSONs[i].AddedAt = queueTracks[i].AddedAt
+			queueTrackJSONs[i].Votes = queueTracks[i].Votes
+		}
+	}
+	return queueTrackJSONs
 }
@@ -67,6 +67,8 @@ func TestSessionHandler_State(t *testing.T) {
 					},
 					AllowToControlByOthers: false,
 				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().FindMemberRole(gomock.Any(), "sessionID", "userID").Return(entity.SessionRoleListener, nil)
 			},
 			wantErr:  true,
 			wantCode: http.StatusBadRequest,
@@ -100,6 +102,8 @@ func TestSessionHandler_State(t *testing.T) {
 					AllowToControlByOthers: true,
 					ProgressWhenPaused:     10 * time.Second,
 				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "nonCreatorID").Return(false, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "session_name",
@@ -190,6 +194,7 @@ func TestSessionHandler_State_PLAY(t *testing.T) {
 					AllowToControlByOthers: true,
 					ProgressWhenPaused:     10 * time.Second,
 				}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "session_name",
@@ -231,6 +236,7 @@ func TestSessionHandler_State_PLAY(t *testing.T) {
 					},
 					AllowToControlByOthers: true,
 				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "nonCreatorID").Return(false, nil)
 			},
 			wantErr:  true,
 			wantCode: http.StatusForbidden,
@@ -269,6 +275,7 @@ func TestSessionHandler_State_PLAY(t *testing.T) {
 					},
 					AllowToControlByOthers: true,
 				}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "session_name",
@@ -656,6 +663,7 @@ func TestSessionHandler_State_STOP(t *testing.T) {
 						{Index: 1, URI: "spotify:track:49BRCNV7E94s7Q2FUhhT3w"},
 					},
 					AllowToControlByOthers: true,
+					ExpirationPolicy:       entity.DefaultExpirationPolicy,
 				}, nil)
 				m.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
 					func(ctx context.Context, sess *entity.Session) error {
@@ -673,13 +681,18 @@ func TestSessionHandler_State_STOP(t *testing.T) {
 								{Index: 1, URI: "spotify:track:49BRCNV7E94s7Q2FUhhT3w"},
 							},
 							AllowToControlByOthers: true,
+							ExpirationPolicy:       entity.DefaultExpirationPolicy,
 						}
 
 						if !fourDaysAfter.After(sess.ExpiredAt) || !twoDaysAfter.Before(sess.ExpiredAt) {
 							t.Errorf("Update(): unexpected ExpiredAt: got: %v", sess.ExpiredAt)
 						}
 
-						opts := []cmp.Option{cmpopts.IgnoreFields(entity.Session{}, "ExpiredAt")}
+						if sess.JoinCode == "" {
+							t.Errorf("Update(): join code is not regenerated")
+						}
+
+						opts := []cmp.Option{cmpopts.IgnoreFields(entity.Session{}, "ExpiredAt", "JoinCode")}
 						if !cmp.Equal(sessionMustBeCall, sess, opts...) {
 							t.Errorf("Update(): unexpected args: diff: %v", cmp.Diff(sessionMustBeCall, sess))
 						}
@@ -810,6 +823,7 @@ func TestSessionHandler_State_ARCHIVED(t *testing.T) {
 					},
 					AllowToControlByOthers: true,
 				}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "session_name",
@@ -850,6 +864,7 @@ func TestSessionHandler_State_ARCHIVED(t *testing.T) {
 					},
 					AllowToControlByOthers: true,
 				}, nil)
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
 				m.EXPECT().Update(gomock.Any(), &entity.Session{
 					ID:        "sessionID",
 					Name:      "session_name",
@@ -953,6 +968,7 @@ func TestSessionHandler_State_ARCHIVED(t *testing.T) {
 					},
 					AllowToControlByOthers: true,
 				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "non_creator_id").Return(false, nil)
 			},
 			wantErr:  true,
 			wantCode: http.StatusBadRequest,
@@ -1007,8 +1023,8 @@ func newSessionStateHandlerForTest(
 	mockSessionRepo := mock_repository.NewMockSession(ctrl)
 	prepareMockSessionRepoFn(mockSessionRepo)
 	syncCheckTimerManager := entity.NewSyncCheckTimerManager()
-	timerUC := usecase.NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockPusher, syncCheckTimerManager)
+	timerUC := usecase.NewSessionTimerUseCase(mockSessionRepo, mockPlayer, nil, mockPusher, syncCheckTimerManager)
 	uc := usecase.NewSessionUseCase(mockSessionRepo, mockUserRepo, mockPlayer, nil, nil, mockPusher, timerUC)
-	stateUC := usecase.NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, timerUC)
-	return &SessionHandler{uc: uc, stateUC: stateUC}
+	stateUC := usecase.NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, nil, timerUC)
+	return &SessionHandler{uc: uc, stateUC: stateUC, maxTracksPerEnqueue: 100}
 }
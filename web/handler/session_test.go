@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -17,6 +19,7 @@ import (
 	"github.com/camphor-/relaym-server/domain/mock_event"
 	"github.com/camphor-/relaym-server/domain/mock_repository"
 	"github.com/camphor-/relaym-server/domain/mock_spotify"
+	"github.com/camphor-/relaym-server/domain/service"
 	"github.com/camphor-/relaym-server/usecase"
 
 	"github.com/golang/mock/gomock"
@@ -58,6 +61,43 @@ func TestSessionHandler_SetDevice(t *testing.T) {
 			wantErr:               true,
 			wantCode:              http.StatusNotFound,
 		},
+		{
+			name:      "他人による操作が許可されていないセッションでリスナーが指定すると400",
+			userID:    "user_id",
+			sessionID: "session_id",
+			body:      `{"device_id": "device_id"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:                     "session_id",
+					CreatorID:              "creator_id",
+					StateType:              "PAUSE",
+					AllowToControlByOthers: false,
+				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "session_id", "user_id").Return(false, nil)
+				m.EXPECT().FindMemberRole(gomock.Any(), "session_id", "user_id").Return(entity.SessionRoleListener, nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			wantErr:               true,
+			wantCode:              http.StatusBadRequest,
+		},
+		{
+			name:      "追放された参加者が指定すると403",
+			userID:    "user_id",
+			sessionID: "session_id",
+			body:      `{"device_id": "device_id"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:                     "session_id",
+					CreatorID:              "creator_id",
+					StateType:              "PAUSE",
+					AllowToControlByOthers: true,
+				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "session_id", "user_id").Return(true, nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			wantErr:               true,
+			wantCode:              http.StatusForbidden,
+		},
 		{
 			name:      "正しくデバイスをセットできると204",
 			userID:    "creator_id",
@@ -125,267 +165,2680 @@ func TestSessionHandler_SetDevice(t *testing.T) {
 	}
 }
 
-func TestSessionHandler_PostSession(t *testing.T) {
-	sessionResponse := &sessionRes{
-		ID:                     "ID",
-		Name:                   "go! go! session!",
-		AllowToControlByOthers: true,
-		Creator: creatorJSON{
-			ID:          "creatorID",
-			DisplayName: "creatorDisplayName",
+func TestSessionHandler_SetFallbackPlaylist(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		userID            string
+		sessionID         string
+		body              string
+		prepareMockRepoFn func(m *mock_repository.MockSession)
+		wantErr           bool
+		wantCode          int
+	}{
+		{
+			name:              "プレイリスト以外のURIだと400",
+			userID:            "creator_id",
+			sessionID:         "session_id",
+			body:              `{"uri": "spotify:track:5uQ0vKy2973Y9IUCd1wMEF"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:           true,
+			wantCode:          http.StatusBadRequest,
 		},
-		Playback: playbackJSON{
-			State: stateJSON{
-				Type: "STOP",
+		{
+			name:      "セッションが存在しないと404",
+			userID:    "creator_id",
+			sessionID: "session_id",
+			body:      `{"uri": "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
 			},
-			Device: nil,
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
 		},
-		Queue: queueJSON{
-			Head:   0,
-			Tracks: []*trackJSON{},
+		{
+			name:      "作成者以外は設定できず403",
+			userID:    "user_id",
+			sessionID: "session_id",
+			body:      `{"uri": "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+				}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:      "共有リンクを指定するとURIに変換して設定され204",
+			userID:    "creator_id",
+			sessionID: "session_id",
+			body:      `{"uri": "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abcdefg"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+				}, nil)
+				m.EXPECT().Update(gomock.Any(), &entity.Session{
+					ID:                  "session_id",
+					CreatorID:           "creator_id",
+					FallbackPlaylistURI: "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+				}).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:      "空文字列を指定すると設定が解除され204",
+			userID:    "creator_id",
+			sessionID: "session_id",
+			body:      `{"uri": ""}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:                  "session_id",
+					CreatorID:           "creator_id",
+					FallbackPlaylistURI: "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M",
+				}, nil)
+				m.EXPECT().Update(gomock.Any(), &entity.Session{
+					ID:                  "session_id",
+					CreatorID:           "creator_id",
+					FallbackPlaylistURI: "",
+				}).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
 		},
 	}
-	user := &entity.User{
-		ID:            "creatorID",
-		SpotifyUserID: "creatorSpotifyUserID",
-		DisplayName:   "creatorDisplayName",
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/fallback-playlist")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.sessionID)
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockRepoFn(mockRepo)
+
+			uc := usecase.NewSessionUseCase(mockRepo, nil, nil, nil, nil, nil, nil)
+			h := &SessionHandler{uc: uc}
+
+			err := h.SetFallbackPlaylist(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetFallbackPlaylist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("SetFallbackPlaylist() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_PatchSession(t *testing.T) {
+	t.Parallel()
+
+	newSession := func() *entity.Session {
+		return &entity.Session{
+			ID:                     "session_id",
+			Name:                   "session_name",
+			CreatorID:              "creator_id",
+			StateType:              entity.Stop,
+			QueueHead:              0,
+			AllowToControlByOthers: false,
+			QueueOrderType:         entity.QueueOrderInsertion,
+			SkipVoteThreshold:      entity.DefaultSkipVoteThreshold,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "session_id", Votes: 0},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "session_id", Votes: 0},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "session_id", Votes: 2},
+			},
+		}
 	}
+
 	tests := []struct {
-		name                     string
-		body                     string
-		userID                   string
-		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
-		prepareMockPusherFn      func(m *mock_event.MockPusher)
-		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
-		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
-		want                     *sessionRes
-		wantErr                  bool
-		wantCode                 int
+		name              string
+		userID            string
+		body              string
+		prepareMockRepoFn func(m *mock_repository.MockSession)
+		prepareMockPusher func(m *mock_event.MockPusher)
+		wantErr           bool
+		wantCode          int
 	}{
 		{
-			name:                "nameを渡すと正常に動作する",
-			body:                `{"name": "go! go! session!", "allow_to_control_by_others": true}`,
-			userID:              "creatorID",
+			name:              "空の名前を指定すると400",
+			userID:            "creator_id",
+			body:              `{"name": ""}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusBadRequest,
+		},
+		{
+			name:              "説明が長すぎると400",
+			userID:            "creator_id",
+			body:              `{"description": "` + strings.Repeat("あ", entity.MaxSessionDescriptionLength+1) + `"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusBadRequest,
+		},
+		{
+			name:              "queue_order_typeが不正だと400",
+			userID:            "creator_id",
+			body:              `{"queue_order_type": "invalid"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusBadRequest,
+		},
+		{
+			name:   "セッションが存在しないと404",
+			userID: "creator_id",
+			body:   `{"name": "new_session_name"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusNotFound,
+		},
+		{
+			name:   "作成者以外は変更できず403",
+			userID: "user_id",
+			body:   `{"name": "new_session_name"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(newSession(), nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusForbidden,
+		},
+		{
+			name:   "指定した設定のみ変更されてSETTINGS_CHANGEDイベントが送られ204",
+			userID: "creator_id",
+			body:   `{"name": "new_session_name", "description": "description", "allow_to_control_by_others": true, "fallback_playlist_uri": "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abcdefg"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(newSession(), nil)
+				want := newSession()
+				want.Name = "new_session_name"
+				want.Description = "description"
+				want.AllowToControlByOthers = true
+				want.FallbackPlaylistURI = "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"
+				m.EXPECT().Update(gomock.Any(), want).Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "session_id",
+					Msg:       entity.EventSettingsChanged,
+				})
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "キューの曲の並び順の決め方を変更するとキューが並び替えられて204",
+			userID: "creator_id",
+			body:   `{"queue_order_type": "VOTE"}`,
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(newSession(), nil)
+				m.EXPECT().UpdateQueueTrackIndexes(gomock.Any(), "session_id", map[int]int{1: 2, 2: 1}).Return(nil)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "session_id",
+					Msg:       entity.EventSettingsChanged,
+				})
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("session_id")
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {}, tt.prepareMockPusher, func(m *mock_repository.MockUser) {}, tt.prepareMockRepoFn, "")
+
+			err := h.PatchSession(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PatchSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PatchSession() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_DeleteSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		userID              string
+		prepareMockPlayerFn func(m *mock_spotify.MockPlayer)
+		prepareMockRepoFn   func(m *mock_repository.MockSession)
+		prepareMockPusher   func(m *mock_event.MockPusher)
+		wantErr             bool
+		wantCode            int
+	}{
+		{
+			name:                "セッションが存在しないと404",
+			userID:              "creator_id",
 			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
-			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
-			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
-				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
 			},
-			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
-				m.EXPECT().FindByID("creatorID").Return(user, nil)
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusNotFound,
+		},
+		{
+			name:                "作成者以外は削除できず403",
+			userID:              "user_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Play,
+					DeviceID:  "device_id",
+				}, nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusForbidden,
+		},
+		{
+			name:   "再生中のセッションは削除された後に一時停止され、DELETEDイベントを送って接続を切断し204",
+			userID: "creator_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").Return(nil)
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Play,
+					DeviceID:  "device_id",
+				}, nil)
+				m.EXPECT().Delete(gomock.Any(), "session_id").Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:  "session_id",
+					Msg:        entity.EventDeleted,
+					Disconnect: true,
+				})
 			},
-			want:     sessionResponse,
 			wantErr:  false,
-			wantCode: http.StatusCreated,
+			wantCode: http.StatusNoContent,
 		},
 		{
-			name:                     "nameが空だとempty nameが返る",
-			body:                     `{"name": "", "allow_to_control_by_others": true}`,
-			userID:                   "creatorID",
-			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
-			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
-			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
-			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
-			want:                     sessionResponse,
-			wantErr:                  true,
-			wantCode:                 http.StatusBadRequest,
+			name:   "アクティブなデバイスが無くても削除されて204",
+			userID: "creator_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").Return(entity.ErrActiveDeviceNotFound)
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Play,
+					DeviceID:  "device_id",
+				}, nil)
+				m.EXPECT().Delete(gomock.Any(), "session_id").Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:  "session_id",
+					Msg:        entity.EventDeleted,
+					Disconnect: true,
+				})
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "一時停止に失敗しても削除されて204",
+			userID: "creator_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Pause(gomock.Any(), "device_id").Return(errors.New("unknown error"))
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Play,
+					DeviceID:  "device_id",
+				}, nil)
+				m.EXPECT().Delete(gomock.Any(), "session_id").Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:  "session_id",
+					Msg:        entity.EventDeleted,
+					Disconnect: true,
+				})
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                "アーカイブされたセッションは一時停止せずに削除されて204",
+			userID:              "creator_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Archived,
+				}, nil)
+				m.EXPECT().Delete(gomock.Any(), "session_id").Return(nil)
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:  "session_id",
+					Msg:        entity.EventDeleted,
+					Disconnect: true,
+				})
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                "削除に失敗すると一時停止せずにDELETEDイベントも送らずに500",
+			userID:              "creator_id",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Play,
+					DeviceID:  "device_id",
+				}, nil)
+				m.EXPECT().Delete(gomock.Any(), "session_id").Return(errors.New("unknown error"))
+			},
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			wantErr:           true,
+			wantCode:          http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id")
+			c.SetParamNames("id")
+			c.SetParamValues("session_id")
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, tt.prepareMockPlayerFn, func(m *mock_spotify.MockTrackClient) {}, tt.prepareMockPusher, func(m *mock_repository.MockUser) {}, tt.prepareMockRepoFn, "session_id")
+
+			err := h.DeleteSession(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("DeleteSession() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_PutMember(t *testing.T) {
+	t.Parallel()
+
+	session := &entity.Session{
+		ID:        "session_id",
+		CreatorID: "creator_id",
+		StateType: entity.Play,
+	}
+
+	tests := []struct {
+		name                  string
+		userID                string
+		memberUserID          string
+		body                  string
+		prepareMockUserRepoFn func(m *mock_repository.MockUser)
+		prepareMockRepoFn     func(m *mock_repository.MockSession)
+		wantErr               bool
+		wantCode              int
+	}{
+		{
+			name:                  "存在しない役割を指定すると400",
+			userID:                "creator_id",
+			memberUserID:          "member_id",
+			body:                  `{"role": "ADMIN"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockRepoFn:     func(m *mock_repository.MockSession) {},
+			wantErr:               true,
+			wantCode:              http.StatusBadRequest,
+		},
+		{
+			name:                  "セッションが存在しないと404",
+			userID:                "creator_id",
+			memberUserID:          "member_id",
+			body:                  `{"role": "CO_HOST"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:                  "作成者以外は役割を変更できず403",
+			userID:                "user_id",
+			memberUserID:          "member_id",
+			body:                  `{"role": "CO_HOST"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(session, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:         "存在しないユーザを指定すると404",
+			userID:       "creator_id",
+			memberUserID: "member_id",
+			body:         `{"role": "CO_HOST"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("member_id").Return(nil, entity.ErrUserNotFound)
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(session, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:         "作成者自身の役割は変更できず400",
+			userID:       "creator_id",
+			memberUserID: "creator_id",
+			body:         `{"role": "LISTENER"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creator_id").Return(&entity.User{ID: "creator_id"}, nil)
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(session, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:         "作成者が共同ホストに任命すると204",
+			userID:       "creator_id",
+			memberUserID: "member_id",
+			body:         `{"role": "CO_HOST"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("member_id").Return(&entity.User{ID: "member_id"}, nil)
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(session, nil)
+				m.EXPECT().StoreMember(gomock.Any(), &entity.SessionMember{
+					SessionID: "session_id",
+					UserID:    "member_id",
+					Role:      entity.SessionRoleCoHost,
+				}).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/members/:user_id")
+			c.SetParamNames("id", "user_id")
+			c.SetParamValues("session_id", tt.memberUserID)
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {}, func(m *mock_event.MockPusher) {}, tt.prepareMockUserRepoFn, tt.prepareMockRepoFn, "")
+
+			err := h.PutMember(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PutMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PutMember() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_PostBan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		userID            string
+		body              string
+		prepareMockPusher func(m *mock_event.MockPusher)
+		prepareMockRepoFn func(m *mock_repository.MockSession)
+		wantErr           bool
+		wantCode          int
+	}{
+		{
+			name:              "参加者のIDが空だと400",
+			userID:            "creator_id",
+			body:              `{"participant_id": ""}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{ID: "session_id", CreatorID: "creator_id"}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:              "作成者は追放できず400",
+			userID:            "creator_id",
+			body:              `{"participant_id": "creator_id"}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{ID: "session_id", CreatorID: "creator_id"}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:              "セッションが存在しないと404",
+			userID:            "creator_id",
+			body:              `{"participant_id": "banned_id"}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:              "作成者以外は追放できず403",
+			userID:            "user_id",
+			body:              `{"participant_id": "banned_id"}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{ID: "session_id", CreatorID: "creator_id"}, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "作成者が追放すると追放した参加者の接続を切断して204",
+			userID: "creator_id",
+			body:   `{"participant_id": "banned_id"}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:     "session_id",
+					Msg:           entity.EventBanned,
+					ParticipantID: "banned_id",
+					Disconnect:    true,
+				})
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{ID: "session_id", CreatorID: "creator_id"}, nil)
+				m.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "session_id", ParticipantID: "banned_id"}).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:   "追放した参加者の曲を削除するとQUEUECHANGEDイベントも送られて204",
+			userID: "creator_id",
+			body:   `{"participant_id": "banned_id", "remove_queue_tracks": true}`,
+			prepareMockPusher: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID:     "session_id",
+					Msg:           entity.EventBanned,
+					ParticipantID: "banned_id",
+					Disconnect:    true,
+				})
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "session_id",
+					Msg:       entity.EventQueueChanged,
+				})
+			},
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "session_id").Return(&entity.Session{
+					ID:        "session_id",
+					CreatorID: "creator_id",
+					StateType: entity.Stop,
+					QueueHead: 0,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track_uri1", SessionID: "session_id", AddedBy: "creator_id"},
+						{Index: 1, URI: "spotify:track:track_uri2", SessionID: "session_id", AddedBy: "banned_id"},
+					},
+				}, nil)
+				m.EXPECT().StoreBan(gomock.Any(), &entity.SessionBan{SessionID: "session_id", ParticipantID: "banned_id"}).Return(nil)
+				m.EXPECT().DeleteQueueTrack(gomock.Any(), "session_id", 1).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/bans")
+			c.SetParamNames("id")
+			c.SetParamValues("session_id")
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {}, tt.prepareMockPusher, func(m *mock_repository.MockUser) {}, tt.prepareMockRepoFn, "")
+
+			err := h.PostBan(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostBan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PostBan() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_PostSession(t *testing.T) {
+	sessionResponse := &sessionRes{
+		ID:                     "ID",
+		Name:                   "go! go! session!",
+		AllowToControlByOthers: true,
+		QueueOrderType:         "INSERTION",
+		SkipVoteThreshold: skipVoteThresholdJSON{
+			Type:  "RATIO",
+			Value: 0.5,
+		},
+		Expiration: expirationJSON{
+			Type:  "FIXED",
+			Hours: 72,
+		},
+		Creator: creatorJSON{
+			ID:          "creatorID",
+			DisplayName: "creatorDisplayName",
+		},
+		Playback: playbackJSON{
+			State: stateJSON{
+				Type: "STOP",
+			},
+			Device: nil,
+		},
+		Queue: queueJSON{
+			Head:   0,
+			Tracks: []*queueTrackJSON{},
+		},
+	}
+	fairSessionResponse := *sessionResponse
+	fairSessionResponse.QueueOrderType = "FAIR"
+	fallbackPlaylistSessionResponse := *sessionResponse
+	fallbackPlaylistSessionResponse.FallbackPlaylistURI = "spotify:playlist:37i9dQZF1DXcBWIGoYBM5M"
+	neverExpireSessionResponse := *sessionResponse
+	neverExpireSessionResponse.Expiration = expirationJSON{Type: "NEVER", Hours: 0}
+	user := &entity.User{
+		ID:            "creatorID",
+		SpotifyUserID: "creatorSpotifyUserID",
+		DisplayName:   "creatorDisplayName",
+	}
+	tests := []struct {
+		name                     string
+		body                     string
+		userID                   string
+		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
+		want                     *sessionRes
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                "nameを渡すと正常に動作する",
+			body:                `{"name": "go! go! session!", "allow_to_control_by_others": true}`,
+			userID:              "creatorID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(user, nil)
+			},
+			want:     sessionResponse,
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:                "queue_order_typeを指定するとその並び順でセッションが作られる",
+			body:                `{"name": "go! go! session!", "allow_to_control_by_others": true, "queue_order_type": "FAIR"}`,
+			userID:              "creatorID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(user, nil)
+			},
+			want:     &fairSessionResponse,
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:                     "queue_order_typeが不正だと400",
+			body:                     `{"name": "go! go! session!", "allow_to_control_by_others": true, "queue_order_type": "invalid"}`,
+			userID:                   "creatorID",
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			want:                     sessionResponse,
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "skip_vote_thresholdが不正だと400",
+			body:                     `{"name": "go! go! session!", "skip_vote_threshold": {"type": "COUNT", "value": 0}}`,
+			userID:                   "creatorID",
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			want:                     sessionResponse,
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "fallback_playlist_uriに共有リンクを指定するとURIに変換してセッションが作られる",
+			body:                `{"name": "go! go! session!", "allow_to_control_by_others": true, "fallback_playlist_uri": "https://open.spotify.com/playlist/37i9dQZF1DXcBWIGoYBM5M?si=abcdefg"}`,
+			userID:              "creatorID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(user, nil)
+			},
+			want:     &fallbackPlaylistSessionResponse,
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:                     "fallback_playlist_uriがプレイリストでないと400",
+			body:                     `{"name": "go! go! session!", "fallback_playlist_uri": "spotify:track:5uQ0vKy2973Y9IUCd1wMEF"}`,
+			userID:                   "creatorID",
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			want:                     sessionResponse,
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "expirationにNEVERを指定するとアーカイブされないセッションが作られる",
+			body:                `{"name": "go! go! session!", "allow_to_control_by_others": true, "expiration": {"type": "NEVER"}}`,
+			userID:              "creatorID",
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(user, nil)
+			},
+			want:     &neverExpireSessionResponse,
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:                     "expirationが不正だと400",
+			body:                     `{"name": "go! go! session!", "expiration": {"type": "IDLE", "hours": 0}}`,
+			userID:                   "creatorID",
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			want:                     sessionResponse,
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "nameが空だとempty nameが返る",
+			body:                     `{"name": "", "allow_to_control_by_others": true}`,
+			userID:                   "creatorID",
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			want:                     sessionResponse,
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c = setToContext(c, tt.userID, nil)
+			c.SetPath("/sessions")
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionStateHandlerForTest(t, ctrl, tt.prepareMockPlayerFn, tt.prepareMockPusherFn, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn)
+
+			postErr := h.PostSession(c)
+			if (postErr != nil) != tt.wantErr {
+				t.Errorf("PostSession() error = %v, wantErr %v", postErr, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := postErr.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PostSession() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &sessionRes{}
+				err := json.Unmarshal(rec.Body.Bytes(), got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts := []cmp.Option{cmpopts.IgnoreFields(sessionRes{}, "ID", "JoinCode"), cmp.AllowUnexported(queueTrackJSON{})}
+				if !cmp.Equal(got, tt.want, opts...) {
+					t.Errorf("PostSession() diff = %v", cmp.Diff(got, tt.want, opts...))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_Enqueue(t *testing.T) {
+	session := &entity.Session{
+		ID:        "sessionID",
+		Name:      "sessionName",
+		CreatorID: "sessionCreator",
+		DeviceID:  "sessionDeviceID",
+		StateType: "PLAY",
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{{
+			Index:     0,
+			URI:       "spotify:track:track_uri",
+			SessionID: "sessionID",
+		},
+		},
+	}
+	sessionHadManyTracks := &entity.Session{
+		ID:        "sessionHadManyTracksID",
+		Name:      "sessionName",
+		CreatorID: "sessionCreator",
+		DeviceID:  "sessionDeviceID",
+		StateType: "PLAY",
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{
+				Index:     0,
+				URI:       "spotify:track:track_uri1",
+				SessionID: "sessionID",
+			},
+			{
+				Index:     1,
+				URI:       "spotify:track:track_uri2",
+				SessionID: "sessionID",
+			},
+			{
+				Index:     2,
+				URI:       "spotify:track:track_uri3",
+				SessionID: "sessionID",
+			},
+		},
+	}
+	tooManyPlaylistTrackURIs := make([]string, 100)
+	for i := range tooManyPlaylistTrackURIs {
+		tooManyPlaylistTrackURIs[i] = fmt.Sprintf("spotify:track:playlist_track%d", i)
+	}
+	tests := []struct {
+		name                     string
+		sessionID                string
+		userID                   string
+		body                     string
+		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
+		prepareMockTrackCliFn    func(m *mock_spotify.MockTrackClient)
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                "正しいuriが渡されると正常に動作し、sessionがqueueの最後から二番目以内ではない曲を再生している場合はEnqueueを叩かない",
+			sessionID:           "sessionHadManyTracksID",
+			body:                `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionHadManyTracksID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionHadManyTracksID").Return(sessionHadManyTracks, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+					URI:         "spotify:track:valid_uri",
+					SessionID:   "sessionHadManyTracksID",
+					AddedByName: entity.GuestDisplayName,
+				}}, 3).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:      "正しくuriが渡されると正常に動作し、sessionがqueueの最後から二番目以内の曲を再生している場合はEnqueueを叩く",
+			sessionID: "sessionID",
+			body:      `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:valid_uri", "sessionDeviceID").Return(nil)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(session, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+					URI:         "spotify:track:valid_uri",
+					SessionID:   "sessionID",
+					AddedByName: entity.GuestDisplayName,
+				}}, 1).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                     "uriが空の時400",
+			sessionID:                "sessionID",
+			body:                     `{"uri": ""}`,
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "ログインしているユーザが曲を追加すると、追加したユーザとして記録される",
+			sessionID:           "sessionHadManyTracksID",
+			userID:              "userID",
+			body:                `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionHadManyTracksID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(&entity.User{
+					ID:            "userID",
+					SpotifyUserID: "spotifyUserID",
+					DisplayName:   "userDisplayName",
+				}, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionHadManyTracksID").Return(sessionHadManyTracks, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionHadManyTracksID", "userID").Return(false, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+					URI:         "spotify:track:valid_uri",
+					SessionID:   "sessionHadManyTracksID",
+					AddedBy:     "userID",
+					AddedByName: "userDisplayName",
+				}}, gomock.Any()).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                "セッションから追放された参加者が曲を追加しようとすると403",
+			sessionID:           "sessionHadManyTracksID",
+			userID:              "bannedUserID",
+			body:                `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("bannedUserID").Return(&entity.User{ID: "bannedUserID"}, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionHadManyTracksID").Return(sessionHadManyTracks, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionHadManyTracksID", "bannedUserID").Return(true, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:                "アルバムのuriが渡されるとアルバムの曲が展開されて追加され、ADDTRACKイベントは1回だけ送られる",
+			sessionID:           "sessionHadManyTracksID",
+			body:                `{"uri": "spotify:album:album_id"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetAlbumTrackURIs(gomock.Any(), "album_id", 101).Return([]string{"spotify:track:album_track1", "spotify:track:album_track2"}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionHadManyTracksID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionHadManyTracksID").Return(&entity.Session{
+					ID:        "sessionHadManyTracksID",
+					StateType: "STOP",
+				}, nil)
+				gomock.InOrder(
+					m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+						URI:         "spotify:track:album_track1",
+						SessionID:   "sessionHadManyTracksID",
+						AddedByName: entity.GuestDisplayName,
+					}}, 0).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+						URI:         "spotify:track:album_track2",
+						SessionID:   "sessionHadManyTracksID",
+						AddedByName: entity.GuestDisplayName,
+					}}, 1).Return(nil),
+				)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:      "複数のuriが渡されると、プレイリストの曲と合わせて追加され、Spotifyのキューには先読みする分だけEnqueueを叩く",
+			sessionID: "sessionID",
+			body:      `{"uris": ["spotify:track:valid_uri", "spotify:playlist:playlist_id"]}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:valid_uri", "sessionDeviceID").Return(nil)
+				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:playlist_track1", "sessionDeviceID").Return(nil)
+			},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlist_id", 100).Return([]string{"spotify:track:playlist_track1", "spotify:track:playlist_track2"}, nil)
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:          "sessionID",
+					DeviceID:    "sessionDeviceID",
+					StateType:   "PLAY",
+					QueueHead:   0,
+					QueueTracks: []*entity.QueueTrack{{Index: 0, URI: "spotify:track:track_uri", SessionID: "sessionID"}},
+				}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                "展開した曲数が上限を超えるとどの曲も追加せずに400",
+			sessionID:           "sessionID",
+			body:                `{"uris": ["spotify:track:valid_uri", "spotify:playlist:playlist_id"]}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetPlaylistTrackURIs(gomock.Any(), "playlist_id", 100).Return(tooManyPlaylistTrackURIs, nil)
+			},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "公平モードのセッションでは、曲を追加したユーザごとに1曲ずつになるようにキューが並び替えられる",
+			sessionID:           "fairSessionID",
+			body:                `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "fairSessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				addedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "fairSessionID").Return(&entity.Session{
+					ID:             "fairSessionID",
+					StateType:      "STOP",
+					QueueHead:      0,
+					QueueOrderType: entity.QueueOrderFair,
+					QueueTracks: []*entity.QueueTrack{
+						{Index: 0, URI: "spotify:track:track_uri1", SessionID: "fairSessionID", AddedBy: "userID", AddedAt: addedAt},
+						{Index: 1, URI: "spotify:track:track_uri2", SessionID: "fairSessionID", AddedBy: "userID", AddedAt: addedAt},
+						{Index: 2, URI: "spotify:track:track_uri3", SessionID: "fairSessionID", AddedBy: "userID", AddedAt: addedAt},
+					},
+				}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+					URI:         "spotify:track:valid_uri",
+					SessionID:   "fairSessionID",
+					AddedByName: entity.GuestDisplayName,
+				}}, 3).Return(nil)
+				m.EXPECT().UpdateQueueTrackIndexes(gomock.Any(), "fairSessionID", map[int]int{1: 2, 2: 3, 3: 1}).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                     "positionが不正な時400",
+			sessionID:                "sessionID",
+			body:                     `{"uri": "spotify:track:valid_uri", "position": "invalid"}`,
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "共有リンクが渡されるとURIに変換して追加される",
+			sessionID:           "sessionID",
+			body:                `{"uri": "https://open.spotify.com/track/5uQ0vKy2973Y9IUCd1wMEF?si=abcdefg"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:5uQ0vKy2973Y9IUCd1wMEF"}).Return([]*entity.Track{{URI: "spotify:track:5uQ0vKy2973Y9IUCd1wMEF"}}, nil)
+			},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventAddTrack,
+				})
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					StateType: "STOP",
+				}, nil)
+				m.EXPECT().StoreQueueTrack(gomock.Any(), queueTrackToStoreMatcher{&entity.QueueTrackToStore{
+					URI:         "spotify:track:5uQ0vKy2973Y9IUCd1wMEF",
+					SessionID:   "sessionID",
+					AddedByName: entity.GuestDisplayName,
+				}}, 0).Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                "Spotifyに存在しない曲のIDの時400",
+			sessionID:           "sessionID",
+			body:                `{"uri": "5uQ0vKy2973Y9IUCd1wMEF"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:5uQ0vKy2973Y9IUCd1wMEF"}).Return([]*entity.Track{nil}, nil)
+			},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "対応していない種類のuriの時400",
+			sessionID:                "sessionID",
+			body:                     `{"uri": "spotify:artist:artist_id"}`,
+			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "存在しないsessionIDの時404",
+			sessionID:           "invalidSessionID",
+			body:                `{"uri": "spotify:track:valid_uri"}`,
+			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:valid_uri"}).Return([]*entity.Track{{URI: "spotify:track:valid_uri"}}, nil)
+			},
+			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "invalidSessionID").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/queue")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.sessionID)
+			if tt.userID != "" {
+				c = setToContext(c, tt.userID, nil)
+			}
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			prepareMockTrackCliFn := tt.prepareMockTrackCliFn
+			if prepareMockTrackCliFn == nil {
+				prepareMockTrackCliFn = func(m *mock_spotify.MockTrackClient) {}
+			}
+			h := newSessionHandlerForTest(t, ctrl, tt.prepareMockPlayerFn, prepareMockTrackCliFn,
+				tt.prepareMockPusherFn, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn, "")
+
+			err := h.Enqueue(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Enqueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); ok && er.Code != tt.wantCode {
+				t.Errorf("Enqueue() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_DeleteQueueTrack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		index                    string
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:      "正しく曲が削除されると204",
+			sessionID: "sessionID",
+			index:     "2",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventQueueChanged,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                     "indexが数値でないと400",
+			sessionID:                "sessionID",
+			index:                    "invalid",
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "headの曲を指定すると400",
+			sessionID:           "sessionID",
+			index:               "0",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrQueueTrackNotEditable)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:                "作成者以外の操作が許可されていないと400",
+			sessionID:           "sessionID",
+			index:               "2",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrSessionNotAllowToControlOthers)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:                "存在しないindexを指定すると404",
+			sessionID:           "sessionID",
+			index:               "10",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrQueueTrackNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:                "存在しないsessionIDの時404",
+			sessionID:           "invalidSessionID",
+			index:               "2",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/queue/:index")
+			c.SetParamNames("id", "index")
+			c.SetParamValues(tt.sessionID, tt.index)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {},
+				tt.prepareMockPusherFn, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
+
+			err := h.DeleteQueueTrack(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeleteQueueTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("DeleteQueueTrack() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_MoveQueueTrack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		index                    string
+		body                     string
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:      "正しく曲が移動されると204",
+			sessionID: "sessionID",
+			index:     "3",
+			body:      `{"position": 1}`,
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventQueueChanged,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:                     "positionが指定されていないと400",
+			sessionID:                "sessionID",
+			index:                    "3",
+			body:                     `{}`,
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "indexが数値でないと400",
+			sessionID:                "sessionID",
+			index:                    "invalid",
+			body:                     `{"position": 1}`,
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                "headの位置に移動しようとすると400",
+			sessionID:           "sessionID",
+			index:               "3",
+			body:                `{"position": 0}`,
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrQueueTrackNotEditable)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:                "存在しないindexを指定すると404",
+			sessionID:           "sessionID",
+			index:               "10",
+			body:                `{"position": 1}`,
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrQueueTrackNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/queue/:index/position")
+			c.SetParamNames("id", "index")
+			c.SetParamValues(tt.sessionID, tt.index)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {},
+				tt.prepareMockPusherFn, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
+
+			err := h.MoveQueueTrack(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MoveQueueTrack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("MoveQueueTrack() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionHandler_ToggleQueueTrackVote(t *testing.T) {
+	t.Parallel()
+
+	newSession := func() *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "name",
+			CreatorID: "creatorID",
+			DeviceID:  "deviceID",
+			StateType: "PLAY",
+			QueueHead: 0,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+			},
+			QueueOrderType: entity.QueueOrderInsertion,
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		index                    string
+		userID                   string
+		guest                    *entity.Guest
+		prepareMockPusherFn      func(m *mock_event.MockPusher)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  bool
+		wantCode                 int
+		want                     *queueTrackVoteRes
+	}{
+		{
+			name:                     "ログインしていないと401",
+			sessionID:                "sessionID",
+			index:                    "2",
+			userID:                   "",
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusUnauthorized,
+		},
+		{
+			name:                     "indexが数値でないと400",
+			sessionID:                "sessionID",
+			index:                    "invalid",
+			userID:                   "userID",
+			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:      "まだ投票していない曲に投票すると200",
+			sessionID: "sessionID",
+			index:     "2",
+			userID:    "userID",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventQueueChanged,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(false, nil)
+				m.EXPECT().StoreQueueTrackVote(gomock.Any(), "sessionID", 2, "userID").Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			want:     &queueTrackVoteRes{Voted: true, Index: 2, Votes: 1},
+		},
+		{
+			name:      "ログインしていなくてもゲストとして登録していれば投票できて200",
+			sessionID: "sessionID",
+			index:     "2",
+			userID:    "",
+			guest:     &entity.Guest{ID: "guestID", Nickname: "nickname"},
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {
+				m.EXPECT().Push(&event.PushMessage{
+					SessionID: "sessionID",
+					Msg:       entity.EventQueueChanged,
+				})
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "guestID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 2, "guestID").Return(false, nil)
+				m.EXPECT().StoreQueueTrackVote(gomock.Any(), "sessionID", 2, "guestID").Return(nil)
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+			want:     &queueTrackVoteRes{Voted: true, Index: 2, Votes: 1},
+		},
+		{
+			name:                "headの曲に投票しようとすると400",
+			sessionID:           "sessionID",
+			index:               "0",
+			userID:              "userID",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().DeleteQueueTrackVote(gomock.Any(), "sessionID", 0, "userID").Return(false, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:                "追放された参加者が投票しようとすると403",
+			sessionID:           "sessionID",
+			index:               "2",
+			userID:              "userID",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(true, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:                "存在しないsessionIDの時404",
+			sessionID:           "invalidSessionID",
+			index:               "2",
+			userID:              "userID",
+			prepareMockPusherFn: func(m *mock_event.MockPusher) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "invalidSessionID").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/queue/:index/vote")
+			c.SetParamNames("id", "index")
+			c.SetParamValues(tt.sessionID, tt.index)
+			c = setToContext(c, tt.userID, nil)
+			c.SetRequest(c.Request().WithContext(service.SetGuestToContext(c.Request().Context(), tt.guest)))
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {},
+				tt.prepareMockPusherFn, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
+
+			err := h.ToggleQueueTrackVote(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToggleQueueTrackVote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("ToggleQueueTrackVote() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &queueTrackVoteRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("ToggleQueueTrackVote() diff = %v", cmp.Diff(got, tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_ExportPlaylist(t *testing.T) {
+	t.Parallel()
+
+	newSession := func() *entity.Session {
+		return &entity.Session{
+			ID:        "sessionID",
+			Name:      "sessionName",
+			CreatorID: "creatorID",
+			StateType: entity.Play,
+			QueueHead: 2,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+			},
+		}
+	}
+	creator := &entity.User{
+		ID:            "creatorID",
+		SpotifyUserID: "creatorSpotifyUserID",
+		DisplayName:   "creatorDisplayName",
+	}
+	playlist := &entity.Playlist{
+		ID:  "playlistID",
+		URI: "spotify:playlist:playlistID",
+		URL: "https://open.spotify.com/playlist/playlistID",
+	}
+
+	tests := []struct {
+		name                     string
+		userID                   string
+		body                     string
+		prepareMockTrackCliFn    func(m *mock_spotify.MockTrackClient)
+		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		want                     *playlistRes
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                     "targetが不正だと400",
+			userID:                   "creatorID",
+			body:                     `{"target": "invalid"}`,
+			prepareMockTrackCliFn:    func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                  "存在しないセッションだと404",
+			userID:                "creatorID",
+			body:                  `{}`,
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:                  "作成者以外は書き出せず403",
+			userID:                "userID",
+			body:                  `{}`,
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(newSession(), nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:                  "再生済みの曲が無いと400",
+			userID:                "creatorID",
+			body:                  `{"target": "played"}`,
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				sess := newSession()
+				sess.QueueHead = 0
+				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(sess, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:   "targetを省略すると再生済みの曲がセッション名のプレイリストに書き出され201",
+			userID: "creatorID",
+			body:   `{}`,
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().CreatePlaylist(gomock.Any(), "creatorSpotifyUserID", "sessionName", gomock.Any(), false).Return(playlist, nil)
+				m.EXPECT().AddTracksToPlaylist(gomock.Any(), "playlistID", []string{"spotify:track:track_uri1", "spotify:track:track_uri2"}).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(creator, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(newSession(), nil)
+			},
+			want: &playlistRes{
+				ID:  "playlistID",
+				URI: "spotify:playlist:playlistID",
+				URL: "https://open.spotify.com/playlist/playlistID",
+			},
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name:   "targetにallを指定するとキューの全ての曲が指定した名前のプレイリストに書き出され201",
+			userID: "creatorID",
+			body:   `{"target": "all", "name": "playlistName", "public": true}`,
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {
+				m.EXPECT().CreatePlaylist(gomock.Any(), "creatorSpotifyUserID", "playlistName", gomock.Any(), true).Return(playlist, nil)
+				m.EXPECT().AddTracksToPlaylist(gomock.Any(), "playlistID", []string{"spotify:track:track_uri1", "spotify:track:track_uri2", "spotify:track:track_uri3"}).Return(nil)
+			},
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("creatorID").Return(creator, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(newSession(), nil)
+			},
+			want: &playlistRes{
+				ID:  "playlistID",
+				URI: "spotify:playlist:playlistID",
+				URL: "https://open.spotify.com/playlist/playlistID",
+			},
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/export/playlist")
+			c.SetParamNames("id")
+			c.SetParamValues("sessionID")
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, tt.prepareMockTrackCliFn, func(m *mock_event.MockPusher) {}, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn, "")
+
+			err := h.ExportPlaylist(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExportPlaylist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("ExportPlaylist() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &playlistRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("ExportPlaylist() diff = %v", cmp.Diff(got, tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_CloneSession(t *testing.T) {
+	t.Parallel()
+
+	newSession := func() *entity.Session {
+		return &entity.Session{
+			ID:                     "sessionID",
+			Name:                   "sessionName",
+			CreatorID:              "creatorID",
+			StateType:              entity.Archived,
+			QueueHead:              1,
+			AllowToControlByOthers: true,
+			QueueTracks: []*entity.QueueTrack{
+				{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+				{Index: 1, URI: "spotify:track:track_uri2", SessionID: "sessionID"},
+				{Index: 2, URI: "spotify:track:track_uri3", SessionID: "sessionID"},
+			},
+		}
+	}
+	user := &entity.User{
+		ID:            "userID",
+		SpotifyUserID: "userSpotifyUserID",
+		DisplayName:   "userDisplayName",
+	}
+	newSessionRes := func(name string, allowToControlByOthers bool) *sessionRes {
+		return &sessionRes{
+			Name:                   name,
+			AllowToControlByOthers: allowToControlByOthers,
+			QueueOrderType:         "INSERTION",
+			SkipVoteThreshold: skipVoteThresholdJSON{
+				Type:  "RATIO",
+				Value: 0.5,
+			},
+			Expiration: expirationJSON{
+				Type:  "FIXED",
+				Hours: 72,
+			},
+			Creator: creatorJSON{
+				ID:          "userID",
+				DisplayName: "userDisplayName",
+			},
+			Playback: playbackJSON{
+				State: stateJSON{
+					Type: "STOP",
+				},
+			},
+			Queue: queueJSON{
+				Head:   0,
+				Tracks: []*queueTrackJSON{},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		body                     string
+		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		want                     *sessionRes
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                     "queueが不正だと400",
+			body:                     `{"queue": "invalid"}`,
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "リクエストボディが不正だと400",
+			body:                     `{"queue": 1}`,
+			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name: "作成者でもメンバーでもないと403",
+			body: `{}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(user, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().IsMember(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "追放されていると403",
+			body: `{}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(user, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(true, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name: "存在しないセッションだと404",
+			body: `{}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(user, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "メンバーが何も指定しないと名前と他人による操作の許可を引き継ぎ、キューは引き継がずに201",
+			body: `{}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(user, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(newSession(), nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().IsMember(gomock.Any(), "sessionID", "userID").Return(true, nil)
+				m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want:     newSessionRes("sessionName", true),
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+		{
+			name: "作成者が名前と他人による操作の許可を指定し、まだ再生していない曲のみを引き継いで201",
+			body: `{"name": "newSessionName", "allow_to_control_by_others": false, "queue": "unplayed"}`,
+			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {
+				m.EXPECT().FindByID("userID").Return(user, nil)
+			},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				sess := newSession()
+				sess.CreatorID = "userID"
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(sess, nil)
+				gomock.InOrder(
+					m.EXPECT().StoreSession(gomock.Any(), gomock.Any()).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 0).Return(nil),
+					m.EXPECT().StoreQueueTrack(gomock.Any(), gomock.Any(), 1).Return(nil),
+				)
+			},
+			want:     newSessionRes("newSessionName", false),
+			wantErr:  false,
+			wantCode: http.StatusCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/clone")
+			c.SetParamNames("id")
+			c.SetParamValues("sessionID")
+			c = setToContext(c, "userID", nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {}, func(m *mock_event.MockPusher) {}, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn, "")
+
+			err := h.CloneSession(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CloneSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("CloneSession() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &sessionRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				opts := []cmp.Option{cmpopts.IgnoreFields(sessionRes{}, "ID", "JoinCode"), cmp.AllowUnexported(queueTrackJSON{})}
+				if !cmp.Equal(got, tt.want, opts...) {
+					t.Errorf("CloneSession() diff = %v", cmp.Diff(got, tt.want, opts...))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_GetSessionIDByJoinCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		code              string
+		prepareMockRepoFn func(m *mock_repository.MockSession)
+		want              *joinRes
+		wantErr           bool
+		wantCode          int
+	}{
+		{
+			name:              "不正な形式のコードだと400",
+			code:              "ABC0O1",
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:           true,
+			wantCode:          http.StatusBadRequest,
+		},
+		{
+			name: "存在しないコードだと404",
+			code: "ABC234",
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindIDByJoinCode(gomock.Any(), "ABC234").Return("", entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name: "小文字で入力してもセッションのIDを取得できて200",
+			code: "abc234",
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindIDByJoinCode(gomock.Any(), "ABC234").Return("session_id", nil)
+			},
+			want:     &joinRes{SessionID: "session_id"},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/join/:code")
+			c.SetParamNames("code")
+			c.SetParamValues(tt.code)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockRepoFn(mockRepo)
+
+			uc := usecase.NewSessionUseCase(mockRepo, nil, nil, nil, nil, nil, nil)
+			h := &SessionHandler{uc: uc}
+
+			err := h.GetSessionIDByJoinCode(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSessionIDByJoinCode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("GetSessionIDByJoinCode() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &joinRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("GetSessionIDByJoinCode() diff = %v", cmp.Diff(got, tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_GetListeners(t *testing.T) {
+	t.Parallel()
+
+	listeners := []*entity.Listener{
+		{ID: "0123abcd-4567-89ef-0123-456789abcdef", DisplayName: "Guest 0123", IsGuest: true},
+		{ID: "user_id", DisplayName: "user", IsGuest: false},
+	}
+
+	tests := []struct {
+		name                         string
+		prepareMockRepoFn            func(m *mock_repository.MockSession)
+		prepareMockListenerCounterFn func(m *mock_event.MockListenerCounter)
+		want                         *listenersRes
+		wantErr                      bool
+		wantCode                     int
+	}{
+		{
+			name: "セッションが存在しないと404",
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(nil, entity.ErrSessionNotFound)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {},
+			wantErr:                      true,
+			wantCode:                     http.StatusNotFound,
+		},
+		{
+			name: "接続しているリスナーの一覧を取得できて200",
+			prepareMockRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByID(gomock.Any(), "session_id").Return(&entity.Session{ID: "session_id"}, nil)
+			},
+			prepareMockListenerCounterFn: func(m *mock_event.MockListenerCounter) {
+				m.EXPECT().Listeners("session_id").Return(listeners)
+			},
+			want:     &listenersRes{Listeners: listeners, Count: 2},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptestの準備
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/listeners")
+			c.SetParamNames("id")
+			c.SetParamValues("session_id")
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockRepo := mock_repository.NewMockSession(ctrl)
+			tt.prepareMockRepoFn(mockRepo)
+			mockListenerCounter := mock_event.NewMockListenerCounter(ctrl)
+			tt.prepareMockListenerCounterFn(mockListenerCounter)
+
+			stateUC := usecase.NewSessionStateUseCase(mockRepo, nil, nil, mockListenerCounter, nil)
+			h := &SessionHandler{stateUC: stateUC}
+
+			err := h.GetListeners(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetListeners() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("GetListeners() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+
+			if !tt.wantErr {
+				got := &listenersRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("GetListeners() diff = %v", cmp.Diff(got, tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestSessionHandler_GetMySessions(t *testing.T) {
+	t.Parallel()
+
+	lastActivityAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	newSession := func(id string, lastActivityAt time.Time) *entity.SessionWithUser {
+		return &entity.SessionWithUser{
+			Session: &entity.Session{
+				ID:                     id,
+				Name:                   "sessionName",
+				CreatorID:              "creatorID",
+				StateType:              entity.Play,
+				AllowToControlByOthers: true,
+				LastActivityAt:         lastActivityAt,
+			},
+			Creator: &entity.User{
+				ID:          "creatorID",
+				DisplayName: "creatorDisplayName",
+			},
+		}
+	}
+	newSessionSummaryJSON := func(id string, lastActivityAt time.Time) *sessionSummaryJSON {
+		return &sessionSummaryJSON{
+			ID:                     id,
+			Name:                   "sessionName",
+			State:                  "PLAY",
+			AllowToControlByOthers: true,
+			Creator: creatorJSON{
+				ID:          "creatorID",
+				DisplayName: "creatorDisplayName",
+			},
+			LastActivityAt: lastActivityAt,
+		}
+	}
+	cursor := &entity.SessionListCursor{LastActivityAt: lastActivityAt, ID: "sessionID1"}
+	encodedCursor := cursor.Encode()
+
+	tests := []struct {
+		name                     string
+		query                    string
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		want                     *sessionsRes
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                     "stateが不正だと400",
+			query:                    "state=PLAY,INVALID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "cursorが不正だと400",
+			query:                    "cursor=invalid",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "limitが0だと400",
+			query:                    "limit=0",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                     "limitが上限を超えていると400",
+			query:                    "limit=101",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:  "何も指定しないと最初のページを取得し、次のページがなければnext_cursorはnullで200",
+			query: "",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByUserID(gomock.Any(), "userID", entity.SessionListFilter{Limit: 21}).
+					Return([]*entity.SessionWithUser{newSession("sessionID1", lastActivityAt)}, nil)
+			},
+			want: &sessionsRes{
+				Sessions:   []*sessionSummaryJSON{newSessionSummaryJSON("sessionID1", lastActivityAt)},
+				NextCursor: nil,
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:  "絞り込みの条件とカーソルを指定でき、次のページがあればnext_cursorを返して200",
+			query: "state=PLAY,PAUSE&name=party&limit=1&cursor=" + encodedCursor,
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().FindByUserID(gomock.Any(), "userID", entity.SessionListFilter{
+					StateTypes: []entity.StateType{entity.Play, entity.Pause},
+					Name:       "party",
+					Cursor:     cursor,
+					Limit:      2,
+				}).Return([]*entity.SessionWithUser{
+					newSession("sessionID2", lastActivityAt.Add(-time.Hour)),
+					newSession("sessionID3", lastActivityAt.Add(-2*time.Hour)),
+				}, nil)
+			},
+			want: &sessionsRes{
+				Sessions: []*sessionSummaryJSON{newSessionSummaryJSON("sessionID2", lastActivityAt.Add(-time.Hour))},
+				NextCursor: func() *string {
+					c := (&entity.SessionListCursor{LastActivityAt: lastActivityAt.Add(-time.Hour), ID: "sessionID2"}).Encode()
+					return &c
+				}(),
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
 		},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// httptestの準備
 			e := echo.New()
-			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
-			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c = setToContext(c, tt.userID, nil)
-			c.SetPath("/sessions")
+			c.SetPath("/users/me/sessions")
+			c = setToContext(c, "userID", nil)
 
 			// モックの準備
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			h := newSessionStateHandlerForTest(t, ctrl, tt.prepareMockPlayerFn, tt.prepareMockPusherFn, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn)
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {}, func(m *mock_event.MockPusher) {}, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
 
-			postErr := h.PostSession(c)
-			if (postErr != nil) != tt.wantErr {
-				t.Errorf("PostSession() error = %v, wantErr %v", postErr, tt.wantErr)
+			err := h.GetMySessions(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetMySessions() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
 			// ステータスコードのチェック
-			if er, ok := postErr.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
-				t.Errorf("PostSession() code = %d, want = %d", rec.Code, tt.wantCode)
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("GetMySessions() code = %d, want = %d", rec.Code, tt.wantCode)
 			}
 
 			if !tt.wantErr {
-				got := &sessionRes{}
-				err := json.Unmarshal(rec.Body.Bytes(), got)
-				if err != nil {
+				got := &sessionsRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
 					t.Fatal(err)
 				}
-				opts := []cmp.Option{cmpopts.IgnoreFields(sessionRes{}, "ID")}
-				if !cmp.Equal(got, tt.want, opts...) {
-					t.Errorf("PostSession() diff = %v", cmp.Diff(got, tt.want, opts...))
+				if !cmp.Equal(got, tt.want) {
+					t.Errorf("GetMySessions() diff = %v", cmp.Diff(got, tt.want))
 				}
 			}
 		})
 	}
 }
 
-func TestSessionHandler_Enqueue(t *testing.T) {
+func TestSessionHandler_GetHistory(t *testing.T) {
+	t.Parallel()
+
+	addedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	startedAt := time.Date(2020, 10, 1, 12, 1, 0, 0, time.UTC)
+	endedAt := time.Date(2020, 10, 1, 12, 4, 33, 0, time.UTC)
 	session := &entity.Session{
 		ID:        "sessionID",
 		Name:      "sessionName",
-		CreatorID: "sessionCreator",
-		DeviceID:  "sessionDeviceID",
-		StateType: "PLAY",
-		QueueHead: 0,
-		QueueTracks: []*entity.QueueTrack{{
-			Index:     0,
-			URI:       "spotify:track:track_uri",
-			SessionID: "sessionID",
-		},
+		CreatorID: "creatorID",
+		StateType: entity.Play,
+		QueueHead: 1,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:06QTSGUEgcmKwiEJ0IMPig", SessionID: "sessionID", AddedBy: "userID", AddedByName: "userDisplayName", AddedAt: addedAt,
+				StartedAt: startedAt, EndedAt: endedAt, EndReason: entity.QueueTrackFinished},
+			{Index: 1, URI: "spotify:track:removedTrack", SessionID: "sessionID", AddedBy: "", AddedByName: "ゲスト", AddedAt: addedAt},
 		},
 	}
-	sessionHadManyTracks := &entity.Session{
-		ID:        "sessionHadManyTracksID",
-		Name:      "sessionName",
-		CreatorID: "sessionCreator",
-		DeviceID:  "sessionDeviceID",
-		StateType: "PLAY",
-		QueueHead: 0,
-		QueueTracks: []*entity.QueueTrack{
-			{
-				Index:     0,
-				URI:       "spotify:track:track_uri1",
-				SessionID: "sessionID",
-			},
-			{
-				Index:     1,
-				URI:       "spotify:track:track_uri2",
-				SessionID: "sessionID",
-			},
-			{
-				Index:     2,
-				URI:       "spotify:track:track_uri3",
-				SessionID: "sessionID",
+	tracks := []*entity.Track{
+		{
+			URI:      "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+			ID:       "06QTSGUEgcmKwiEJ0IMPig",
+			Name:     "Borderland",
+			Duration: 213066 * time.Millisecond,
+			Artists:  []*entity.Artist{{Name: "MONOEYES"}},
+			URL:      "https://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig",
+			Album: &entity.Album{
+				Name:   "Interstate 46 E.P.",
+				Images: []*entity.AlbumImage{},
 			},
 		},
+		nil,
+	}
+	prepareMockTrackCliFn := func(m *mock_spotify.MockTrackClient) {
+		m.EXPECT().GetTracksFromURI(gomock.Any(), []string{"spotify:track:06QTSGUEgcmKwiEJ0IMPig", "spotify:track:removedTrack"}).Return(tracks, nil)
 	}
+	prepareMockSessionRepoFn := func(m *mock_repository.MockSession) {
+		m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(session, nil)
+	}
+
 	tests := []struct {
 		name                     string
 		sessionID                string
-		body                     string
-		prepareMockPlayerFn      func(m *mock_spotify.MockPlayer)
-		prepareMockPusherFn      func(m *mock_event.MockPusher)
-		prepareMockUserRepoFn    func(m *mock_repository.MockUser)
+		format                   string
+		prepareMockTrackCliFn    func(m *mock_spotify.MockTrackClient)
 		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantJSON                 *historyRes
+		wantBody                 string
 		wantErr                  bool
 		wantCode                 int
 	}{
 		{
-			name:                "正しいuriが渡されると正常に動作し、sessionがqueueの最後から二番目以内ではない曲を再生している場合はEnqueueを叩かない",
-			sessionID:           "sessionHadManyTracksID",
-			body:                `{"uri": "spotify:track:valid_uri"}`,
-			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {},
-			prepareMockPusherFn: func(m *mock_event.MockPusher) {
-				m.EXPECT().Push(&event.PushMessage{
-					SessionID: "sessionHadManyTracksID",
-					Msg:       entity.EventAddTrack,
-				})
-			},
-			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
+			name:                     "formatが不正だと400",
+			sessionID:                "sessionID",
+			format:                   "xml",
+			prepareMockTrackCliFn:    func(m *mock_spotify.MockTrackClient) {},
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusBadRequest,
+		},
+		{
+			name:                  "存在しないセッションだと404",
+			sessionID:             "notFoundSessionID",
+			format:                "",
+			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
 			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
-				m.EXPECT().FindByID(gomock.Any(), "sessionHadManyTracksID").Return(sessionHadManyTracks, nil)
-				m.EXPECT().StoreQueueTrack(gomock.Any(), &entity.QueueTrackToStore{
-					URI:       "spotify:track:valid_uri",
-					SessionID: "sessionHadManyTracksID",
-				}).Return(nil)
+				m.EXPECT().FindByID(gomock.Any(), "notFoundSessionID").Return(nil, entity.ErrSessionNotFound)
 			},
-			wantErr:  false,
-			wantCode: http.StatusNoContent,
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
 		},
 		{
-			name:      "正しくuriが渡されると正常に動作し、sessionがqueueの最後から二番目以内の曲を再生している場合はEnqueueを叩く",
-			sessionID: "sessionID",
-			body:      `{"uri": "spotify:track:valid_uri"}`,
-			prepareMockPlayerFn: func(m *mock_spotify.MockPlayer) {
-				m.EXPECT().Enqueue(gomock.Any(), "spotify:track:valid_uri", "sessionDeviceID").Return(nil)
-			},
-			prepareMockPusherFn: func(m *mock_event.MockPusher) {
-				m.EXPECT().Push(&event.PushMessage{
-					SessionID: "sessionID",
-					Msg:       entity.EventAddTrack,
-				})
-			},
-			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
-			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
-				m.EXPECT().FindByID(gomock.Any(), "sessionID").Return(session, nil)
-				m.EXPECT().StoreQueueTrack(gomock.Any(), &entity.QueueTrackToStore{
-					URI:       "spotify:track:valid_uri",
-					SessionID: "sessionID",
-				}).Return(nil)
+			name:                     "formatを省略するとJSONで全ての曲と再生状況が返る",
+			sessionID:                "sessionID",
+			format:                   "",
+			prepareMockTrackCliFn:    prepareMockTrackCliFn,
+			prepareMockSessionRepoFn: prepareMockSessionRepoFn,
+			wantJSON: &historyRes{
+				ID:        "sessionID",
+				Name:      "sessionName",
+				QueueHead: 1,
+				Tracks: []*historyTrackJSON{
+					{
+						Index:     0,
+						Status:    "played",
+						StartedAt: &startedAt,
+						EndedAt:   &endedAt,
+						EndReason: "FINISHED",
+						queueTrackJSON: queueTrackJSON{
+							trackJSON: trackJSON{
+								URI:      "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+								ID:       "06QTSGUEgcmKwiEJ0IMPig",
+								Name:     "Borderland",
+								Duration: 213066,
+								Artists:  []*artistJSON{{Name: "MONOEYES"}},
+								URL:      "https://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig",
+								Album: &albumJSON{
+									Name:   "Interstate 46 E.P.",
+									Images: []*albumImageJSON{},
+								},
+							},
+							AddedBy: addedByJSON{ID: "userID", DisplayName: "userDisplayName"},
+							AddedAt: addedAt,
+						},
+					},
+					{
+						Index:  1,
+						Status: "pending",
+						queueTrackJSON: queueTrackJSON{
+							trackJSON: trackJSON{
+								URI:     "spotify:track:removedTrack",
+								Artists: []*artistJSON{},
+							},
+							AddedBy: addedByJSON{ID: "", DisplayName: "ゲスト"},
+							AddedAt: addedAt,
+						},
+					},
+				},
 			},
 			wantErr:  false,
-			wantCode: http.StatusNoContent,
+			wantCode: http.StatusOK,
 		},
 		{
-			name:                     "uriが空の時400",
+			name:                     "csvを指定するとCSVで返る",
 			sessionID:                "sessionID",
-			body:                     `{"uri": ""}`,
-			prepareMockPlayerFn:      func(m *mock_spotify.MockPlayer) {},
-			prepareMockPusherFn:      func(m *mock_event.MockPusher) {},
-			prepareMockUserRepoFn:    func(m *mock_repository.MockUser) {},
-			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
-			wantErr:                  true,
-			wantCode:                 http.StatusBadRequest,
+			format:                   "csv",
+			prepareMockTrackCliFn:    prepareMockTrackCliFn,
+			prepareMockSessionRepoFn: prepareMockSessionRepoFn,
+			wantBody: "index,status,uri,name,artists,album,duration_ms,external_url,added_by,added_at,started_at,ended_at,end_reason\n" +
+				"0,played,spotify:track:06QTSGUEgcmKwiEJ0IMPig,Borderland,MONOEYES,Interstate 46 E.P.,213066,https://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig,userDisplayName,2020-10-01T12:00:00Z,2020-10-01T12:01:00Z,2020-10-01T12:04:33Z,FINISHED\n" +
+				"1,pending,spotify:track:removedTrack,,,,0,,ゲスト,2020-10-01T12:00:00Z,,,\n",
+			wantErr:  false,
+			wantCode: http.StatusOK,
 		},
 		{
-			name:                  "存在しないsessionIDの時404",
-			sessionID:             "invalidSessionID",
-			body:                  `{"uri": "valid_uri"}`,
-			prepareMockPlayerFn:   func(m *mock_spotify.MockPlayer) {},
-			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
-			prepareMockUserRepoFn: func(m *mock_repository.MockUser) {},
-			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
-				m.EXPECT().FindByID(gomock.Any(), "invalidSessionID").Return(nil, entity.ErrSessionNotFound)
-			},
-			wantErr:  true,
-			wantCode: http.StatusNotFound,
+			name:                     "m3uを指定すると拡張M3Uで返る",
+			sessionID:                "sessionID",
+			format:                   "m3u",
+			prepareMockTrackCliFn:    prepareMockTrackCliFn,
+			prepareMockSessionRepoFn: prepareMockSessionRepoFn,
+			wantBody: "#EXTM3U\n#PLAYLIST:sessionName\n" +
+				"#EXTINF:213,MONOEYES - Borderland\nhttps://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig\n" +
+				"#EXTINF:-1,spotify:track:removedTrack\nspotify:track:removedTrack\n",
+			wantErr:  false,
+			wantCode: http.StatusOK,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// httptestの準備
 			e := echo.New()
-			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
-			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req := httptest.NewRequest(http.MethodGet, "/?format="+tt.format, nil)
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
-			c.SetPath("/sessions/:id/queue")
+			c.SetPath("/sessions/:id/history")
 			c.SetParamNames("id")
 			c.SetParamValues(tt.sessionID)
 
 			// モックの準備
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			h := newSessionStateHandlerForTest(t, ctrl, tt.prepareMockPlayerFn, tt.prepareMockPusherFn, tt.prepareMockUserRepoFn, tt.prepareMockSessionRepoFn)
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, tt.prepareMockTrackCliFn, func(m *mock_event.MockPusher) {}, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
 
-			err := h.Enqueue(c)
+			err := h.GetHistory(c)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Enqueue() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetHistory() error = %v, wantErr %v", err, tt.wantErr)
+				return
 			}
 
 			// ステータスコードのチェック
-			if er, ok := err.(*echo.HTTPError); ok && er.Code != tt.wantCode {
-				t.Errorf("Enqueue() code = %d, want = %d", rec.Code, tt.wantCode)
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("GetHistory() code = %d, want = %d", rec.Code, tt.wantCode)
+				return
+			}
+
+			if tt.wantJSON != nil {
+				got := &historyRes{}
+				if err := json.Unmarshal(rec.Body.Bytes(), got); err != nil {
+					t.Fatal(err)
+				}
+				opts := []cmp.Option{cmp.AllowUnexported(historyTrackJSON{}, queueTrackJSON{})}
+				if !cmp.Equal(got, tt.wantJSON, opts...) {
+					t.Errorf("GetHistory() diff = %v", cmp.Diff(got, tt.wantJSON, opts...))
+				}
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("GetHistory() body = %q, want %q", rec.Body.String(), tt.wantBody)
 			}
 		})
 	}
 }
 
 func TestSessionHandler_GetSession(t *testing.T) {
+	addedAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
 	session := &entity.Session{
 		ID:        "sessionID",
 		Name:      "sessionName",
@@ -395,9 +2848,12 @@ func TestSessionHandler_GetSession(t *testing.T) {
 		QueueHead: 0,
 		QueueTracks: []*entity.QueueTrack{
 			{
-				Index:     0,
-				URI:       "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
-				SessionID: "sessionID",
+				Index:       0,
+				URI:         "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+				SessionID:   "sessionID",
+				AddedBy:     "userID",
+				AddedByName: "userDisplayName",
+				AddedAt:     addedAt,
 			},
 		},
 	}
@@ -461,18 +2917,36 @@ func TestSessionHandler_GetSession(t *testing.T) {
 		},
 	}
 
-	trackJSONs := []*trackJSON{
+	trackJSONs := []*queueTrackJSON{
 		{
-			URI:      "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
-			ID:       "06QTSGUEgcmKwiEJ0IMPig",
-			Name:     "Borderland",
-			Duration: 213066,
-			Artists:  artistJSONs,
-			URL:      "https://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig",
-			Album: &albumJSON{
-				Name:   "Interstate 46 E.P.",
-				Images: albumImageJSONs,
+			trackJSON: trackJSON{
+				URI:      "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+				ID:       "06QTSGUEgcmKwiEJ0IMPig",
+				Name:     "Borderland",
+				Duration: 213066,
+				Artists:  artistJSONs,
+				URL:      "https://open.spotify.com/track/06QTSGUEgcmKwiEJ0IMPig",
+				Album: &albumJSON{
+					Name:   "Interstate 46 E.P.",
+					Images: albumImageJSONs,
+				},
+			},
+			AddedBy: addedByJSON{
+				ID:          "userID",
+				DisplayName: "userDisplayName",
+			},
+			AddedAt: addedAt,
+		},
+	}
+
+	guestTrackJSONs := []*queueTrackJSON{
+		{
+			trackJSON: trackJSONs[0].trackJSON,
+			AddedBy: addedByJSON{
+				ID:          "",
+				DisplayName: entity.GuestDisplayName,
 			},
+			AddedAt: addedAt,
 		},
 	}
 
@@ -572,9 +3046,11 @@ func TestSessionHandler_GetSession(t *testing.T) {
 					QueueHead: 0,
 					QueueTracks: []*entity.QueueTrack{
 						{
-							Index:     0,
-							URI:       "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
-							SessionID: "sessionID",
+							Index:       0,
+							URI:         "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+							SessionID:   "sessionID",
+							AddedByName: entity.GuestDisplayName,
+							AddedAt:     addedAt,
 						},
 					},
 				}, nil)
@@ -601,7 +3077,7 @@ func TestSessionHandler_GetSession(t *testing.T) {
 				},
 				Queue: queueJSON{
 					Head:   0,
-					Tracks: trackJSONs,
+					Tracks: guestTrackJSONs,
 				},
 			},
 			wantErr:  false,
@@ -651,13 +3127,16 @@ func TestSessionHandler_GetSession(t *testing.T) {
 					QueueHead: 0,
 					QueueTracks: []*entity.QueueTrack{
 						{
-							Index:     0,
-							URI:       "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
-							SessionID: "sessionID",
+							Index:       0,
+							URI:         "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+							SessionID:   "sessionID",
+							AddedByName: entity.GuestDisplayName,
+							AddedAt:     addedAt,
 						},
 					},
 				}, nil)
-				m.EXPECT().Update(gomock.Any(), &entity.Session{
+				m.EXPECT().UpdateQueueTrackPlayback(gomock.Any(), gomock.Any()).Return(nil)
+				m.EXPECT().UpdateWithoutActivity(gomock.Any(), &entity.Session{
 					ID:        "play_sessionID",
 					Name:      "sessionName",
 					CreatorID: "creatorID",
@@ -666,9 +3145,11 @@ func TestSessionHandler_GetSession(t *testing.T) {
 					QueueHead: 0,
 					QueueTracks: []*entity.QueueTrack{
 						{
-							Index:     0,
-							URI:       "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
-							SessionID: "sessionID",
+							Index:       0,
+							URI:         "spotify:track:06QTSGUEgcmKwiEJ0IMPig",
+							SessionID:   "sessionID",
+							AddedByName: entity.GuestDisplayName,
+							AddedAt:     addedAt,
 						},
 					},
 				}).Return(nil)
@@ -693,7 +3174,7 @@ func TestSessionHandler_GetSession(t *testing.T) {
 				},
 				Queue: queueJSON{
 					Head:   0,
-					Tracks: trackJSONs,
+					Tracks: guestTrackJSONs,
 				},
 			},
 			wantErr:  false,
@@ -735,7 +3216,7 @@ func TestSessionHandler_GetSession(t *testing.T) {
 					t.Fatal(err)
 					return
 				}
-				opts := []cmp.Option{cmpopts.IgnoreFields(sessionRes{}, "ID")}
+				opts := []cmp.Option{cmpopts.IgnoreFields(sessionRes{}, "ID"), cmp.AllowUnexported(queueTrackJSON{})}
 				if !cmp.Equal(got, tt.want, opts...) {
 					t.Errorf("GetSession() diff = %v", cmp.Diff(got, tt.want, opts...))
 				}
@@ -876,6 +3357,7 @@ func TestSessionHandler_NextTrack(t *testing.T) {
 						AllowToControlByOthers: true,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
 				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).Return(nil, entity.ErrNextQueueTrackNotFound)
 			},
 			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
@@ -904,6 +3386,7 @@ func TestSessionHandler_NextTrack(t *testing.T) {
 						AllowToControlByOthers: true,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
 			},
 			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
 			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
@@ -932,6 +3415,7 @@ func TestSessionHandler_NextTrack(t *testing.T) {
 						AllowToControlByOthers: true,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
 			},
 			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
 			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
@@ -959,6 +3443,8 @@ func TestSessionHandler_NextTrack(t *testing.T) {
 						AllowToControlByOthers: false,
 						ProgressWhenPaused:     0,
 					}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().FindMemberRole(gomock.Any(), "sessionID", "userID").Return(entity.SessionRoleListener, nil)
 			},
 			prepareMockPusherFn:   func(m *mock_event.MockPusher) {},
 			prepareMockTrackCliFn: func(m *mock_spotify.MockTrackClient) {},
@@ -999,6 +3485,122 @@ func TestSessionHandler_NextTrack(t *testing.T) {
 	}
 }
 
+// 正常系のテストケースはトランザクションの中を確かめる必要があるので、 usecase/session_state_test.go で行っている
+func TestSessionHandler_PostSkipVote(t *testing.T) {
+	t.Parallel()
+
+	session := &entity.Session{
+		ID:        "sessionID",
+		Name:      "name",
+		CreatorID: "creatorID",
+		DeviceID:  "deviceID",
+		StateType: "PLAY",
+		QueueHead: 0,
+		QueueTracks: []*entity.QueueTrack{
+			{Index: 0, URI: "spotify:track:track_uri1", SessionID: "sessionID"},
+		},
+		SkipVoteThreshold: entity.DefaultSkipVoteThreshold,
+	}
+
+	tests := []struct {
+		name                     string
+		sessionID                string
+		userID                   string
+		prepareMockSessionRepoFn func(m *mock_repository.MockSession)
+		wantErr                  bool
+		wantCode                 int
+	}{
+		{
+			name:                     "ログインしていないと401",
+			sessionID:                "sessionID",
+			userID:                   "",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {},
+			wantErr:                  true,
+			wantCode:                 http.StatusUnauthorized,
+		},
+		{
+			name:      "既に同じ曲に投票していると409",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(session, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+				m.EXPECT().StoreSkipVote(gomock.Any(), "sessionID", 0, "userID").Return(entity.ErrSkipVoteAlreadyExisted)
+			},
+			wantErr:  true,
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:      "STOPのときは400",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(&entity.Session{
+					ID:        "sessionID",
+					StateType: "STOP",
+				}, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(false, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name:      "追放された参加者が投票しようとすると403",
+			sessionID: "sessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "sessionID").Return(session, nil)
+				m.EXPECT().IsBanned(gomock.Any(), "sessionID", "userID").Return(true, nil)
+			},
+			wantErr:  true,
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:      "存在しないsessionIDの時404",
+			sessionID: "invalidSessionID",
+			userID:    "userID",
+			prepareMockSessionRepoFn: func(m *mock_repository.MockSession) {
+				m.EXPECT().DoInTx(gomock.Any(), gomock.Any()).DoAndReturn(doInTxForTest)
+				m.EXPECT().FindByIDForUpdate(gomock.Any(), "invalidSessionID").Return(nil, entity.ErrSessionNotFound)
+			},
+			wantErr:  true,
+			wantCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/sessions/:id/skip-votes")
+			c.SetParamNames("id")
+			c.SetParamValues(tt.sessionID)
+			c = setToContext(c, tt.userID, nil)
+
+			// モックの準備
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			h := newSessionHandlerForTest(t, ctrl, func(m *mock_spotify.MockPlayer) {}, func(m *mock_spotify.MockTrackClient) {},
+				func(m *mock_event.MockPusher) {}, func(m *mock_repository.MockUser) {}, tt.prepareMockSessionRepoFn, "")
+
+			err := h.PostSkipVote(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PostSkipVote() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// ステータスコードのチェック
+			if er, ok := err.(*echo.HTTPError); (ok && er.Code != tt.wantCode) || (!ok && rec.Code != tt.wantCode) {
+				t.Errorf("PostSkipVote() code = %d, want = %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
 func convToPointer(given int64) *int64 {
 	return &given
 }
@@ -1030,8 +3632,12 @@ func newSessionHandlerForTest(
 		timer := syncCheckTimerManager.CreateExpiredTimer(sessionID)
 		timer.SetDuration(5 * time.Minute)
 	}
-	timerUC := usecase.NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockPusher, syncCheckTimerManager)
+	timerUC := usecase.NewSessionTimerUseCase(mockSessionRepo, mockPlayer, mockTrackCli, mockPusher, syncCheckTimerManager)
 	uc := usecase.NewSessionUseCase(mockSessionRepo, mockUserRepo, mockPlayer, mockTrackCli, nil, mockPusher, timerUC)
-	stateUC := usecase.NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, timerUC)
-	return &SessionHandler{uc: uc, stateUC: stateUC}
+	stateUC := usecase.NewSessionStateUseCase(mockSessionRepo, mockPlayer, mockPusher, nil, timerUC)
+	return &SessionHandler{uc: uc, stateUC: stateUC, maxTracksPerEnqueue: 100}
+}
+
+func doInTxForTest(ctx context.Context, f func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return f(ctx)
 }
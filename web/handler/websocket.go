@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/camphor-/relaym-server/domain/entity"
+	"github.com/camphor-/relaym-server/domain/service"
 	"github.com/camphor-/relaym-server/log"
 	"github.com/camphor-/relaym-server/usecase"
 	"github.com/camphor-/relaym-server/web/ws"
@@ -45,10 +46,20 @@ func (h *WebSocketHandler) WebSocket(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	if err := h.uc.CanConnectToPusher(ctx, sessionID); err != nil {
-		if errors.Is(err, entity.ErrSessionNotFound) {
+		switch {
+		case errors.Is(err, entity.ErrSessionNotFound):
 			return echo.NewHTTPError(http.StatusNotFound, entity.ErrSessionNotFound.Error())
+		case errors.Is(err, entity.ErrParticipantBanned):
+			logger.Debug(err)
+			return echo.NewHTTPError(http.StatusForbidden, entity.ErrParticipantBanned.Error())
 		}
-		logger.Errorj(map[string]interface{}{"message:": "can not connect to pusher", "error": err.Error()})
+		logger.Errorj(map[string]interface{}{"message": "can not connect to pusher", "error": err.Error()})
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	}
+
+	listener, err := h.uc.IdentifyListener(ctx)
+	if err != nil {
+		logger.Errorj(map[string]interface{}{"message": "failed to identify listener", "error": err.Error()})
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 
@@ -58,7 +69,7 @@ func (h *WebSocketHandler) WebSocket(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError)
 	}
 
-	wsCli := ws.NewClient(sessionID, wsConn, h.hub.UnregisterCh())
+	wsCli := ws.NewClient(sessionID, service.GetParticipantIDFromContext(ctx), listener, wsConn, h.hub.UnregisterCh())
 	h.hub.Register(wsCli)
 
 	go wsCli.PushLoop()
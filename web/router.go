@@ -44,7 +44,7 @@ func NewServer(authUC *usecase.AuthUseCase, userUC *usecase.UserUseCase, session
 
 	userHandler := handler.NewUserHandler(userUC)
 	trackHandler := handler.NewTrackHandler(trackUC)
-	sessionHandler := handler.NewSessionHandler(sessionUC, sessionStateUC)
+	sessionHandler := handler.NewSessionHandler(sessionUC, sessionStateUC, config.MaxTracksPerEnqueue())
 	authHandler := handler.NewAuthHandler(authUC, config.FrontendURL())
 	wsHandler := handler.NewWebSocketHandler(hub, sessionUC)
 	batchHandler := handler.NewBatchHandler(batchUC)
@@ -52,26 +52,43 @@ func NewServer(authUC *usecase.AuthUseCase, userUC *usecase.UserUseCase, session
 	v3 := e.Group("/api/v3")
 	v3.GET("/login", authHandler.Login)
 	v3.GET("/callback", authHandler.Callback)
+	v3.GET("/join/:code", sessionHandler.GetSessionIDByJoinCode)
+	v3.POST("/guests", authHandler.PostGuest)
 
 	batch := v3.Group("/batch")
 	batch.POST("/archive", batchHandler.PostArchive)
+	batch.POST("/delete-expired-guests", batchHandler.PostDeleteExpiredGuests)
 
 	authed := v3.Group("", NewAuthMiddleware(authUC).Authenticate)
 
 	user := authed.Group("/users")
 	user.GET("/me", userHandler.GetMe)
+	user.GET("/me/sessions", sessionHandler.GetMySessions)
 
 	authedSession := authed.Group("/sessions")
 	authedSession.POST("", sessionHandler.PostSession)
+	authedSession.POST("/:id/clone", sessionHandler.CloneSession)
 
 	sessionWithCreatorToken := v3.Group("/sessions/:id", NewCreatorTokenMiddleware(authUC).SetCreatorTokenToContext)
 	sessionWithCreatorToken.GET("", sessionHandler.GetSession)
+	sessionWithCreatorToken.PATCH("", sessionHandler.PatchSession)
+	sessionWithCreatorToken.DELETE("", sessionHandler.DeleteSession)
 	sessionWithCreatorToken.GET("/search", trackHandler.SearchTracks)
 	sessionWithCreatorToken.GET("/devices", sessionHandler.GetActiveDevices)
 	sessionWithCreatorToken.PUT("/devices", sessionHandler.SetDevice)
+	sessionWithCreatorToken.PUT("/fallback-playlist", sessionHandler.SetFallbackPlaylist)
+	sessionWithCreatorToken.POST("/export/playlist", sessionHandler.ExportPlaylist)
+	sessionWithCreatorToken.GET("/history", sessionHandler.GetHistory)
+	sessionWithCreatorToken.GET("/listeners", sessionHandler.GetListeners)
+	sessionWithCreatorToken.PUT("/members/:user_id", sessionHandler.PutMember)
+	sessionWithCreatorToken.POST("/bans", sessionHandler.PostBan)
 	sessionWithCreatorToken.POST("/queue", sessionHandler.Enqueue)
+	sessionWithCreatorToken.DELETE("/queue/:index", sessionHandler.DeleteQueueTrack)
+	sessionWithCreatorToken.PUT("/queue/:index/position", sessionHandler.MoveQueueTrack)
+	sessionWithCreatorToken.POST("/queue/:index/vote", sessionHandler.ToggleQueueTrackVote)
 	sessionWithCreatorToken.PUT("/state", sessionHandler.State)
 	sessionWithCreatorToken.PUT("/next", sessionHandler.NextTrack)
+	sessionWithCreatorToken.POST("/skip-votes", sessionHandler.PostSkipVote)
 	sessionWithCreatorToken.GET("/ws", wsHandler.WebSocket)
 	return e
 }
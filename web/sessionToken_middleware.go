@@ -43,6 +43,16 @@ func (m *CreatorTokenMiddleware) SetCreatorTokenToContext(next echo.HandlerFunc)
 			}
 		}
 
+		// ログインしていないユーザは、ゲストとして登録していればゲストとして扱う
+		var guest *entity.Guest
+		if loginUserID == "" {
+			if guestCookie, err := c.Cookie("guest"); err == nil {
+				if g, err := m.uc.GetGuestFromToken(guestCookie.Value); err == nil {
+					guest = g
+				}
+			}
+		}
+
 		token, creatorID, err := m.uc.GetTokenAndCreatorIDBySessionID(sessionID)
 		if err != nil {
 			if errors.Is(err, entity.ErrSessionNotFound) {
@@ -60,13 +70,14 @@ func (m *CreatorTokenMiddleware) SetCreatorTokenToContext(next echo.HandlerFunc)
 		}
 		token = newToken
 
-		c = setToCreatorContext(c, loginUserID, creatorID, token)
+		c = setToCreatorContext(c, loginUserID, guest, creatorID, token)
 		return next(c)
 	}
 }
-func setToCreatorContext(c echo.Context, userID, creatorID string, token *oauth2.Token) echo.Context {
+func setToCreatorContext(c echo.Context, userID string, guest *entity.Guest, creatorID string, token *oauth2.Token) echo.Context {
 	ctx := c.Request().Context()
 	ctx = service.SetUserIDToContext(ctx, userID)
+	ctx = service.SetGuestToContext(ctx, guest)
 	ctx = service.SetCreatorIDToContext(ctx, creatorID)
 	ctx = service.SetTokenToContext(ctx, token)
 	c.SetRequest(c.Request().WithContext(ctx))
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/mock_spotify"
 
 	"github.com/camphor-/relaym-server/domain/mock_repository"
@@ -24,6 +25,7 @@ func TestSessionTokenMiddleware_SetTokenToContext(t *testing.T) {
 	tests := []struct {
 		name               string
 		sessionID          string
+		guestToken         string
 		prepareSessionRepo func(r *mock_repository.MockSession)
 		prepareAuthRepo    func(r *mock_repository.MockAuth)
 		prepareAuthCli     func(c *mock_spotify.MockAuth)
@@ -152,6 +154,61 @@ func TestSessionTokenMiddleware_SetTokenToContext(t *testing.T) {
 			wantErr:  false,
 			wantCode: http.StatusOK,
 		},
+		{
+			name:       "ゲストのCookieがついているとゲストがContextにセットされる",
+			sessionID:  "sessionID",
+			guestToken: "guestToken",
+			prepareSessionRepo: func(r *mock_repository.MockSession) {
+				r.EXPECT().FindCreatorTokenBySessionID(gomock.Any(), "sessionID").Return(&oauth2.Token{
+					AccessToken:  "access_token",
+					TokenType:    "Bearer",
+					RefreshToken: "refresh_token",
+					Expiry:       time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+				}, "userID", nil)
+			},
+			prepareAuthRepo: func(r *mock_repository.MockAuth) {
+				r.EXPECT().FindGuestByToken("guestToken").Return(&entity.Guest{ID: "guestID", Nickname: "nickname"}, nil)
+			},
+			prepareAuthCli: func(c *mock_spotify.MockAuth) {},
+			next: func(c echo.Context) error {
+				guest, ok := service.GetGuestFromContext(c.Request().Context())
+				if !ok {
+					t.Errorf("CreatorTokenMiddleware.SetCreatorTokenToContext() guest not found in context")
+				}
+				want := &entity.Guest{ID: "guestID", Nickname: "nickname"}
+				if !cmp.Equal(want, guest) {
+					t.Errorf("CreatorTokenMiddleware.SetCreatorTokenToContext() guest diff = %s", cmp.Diff(want, guest))
+				}
+				return nil
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "ゲストのCookieが無効な場合はゲストがセットされずに処理が続く",
+			sessionID:  "sessionID",
+			guestToken: "invalidToken",
+			prepareSessionRepo: func(r *mock_repository.MockSession) {
+				r.EXPECT().FindCreatorTokenBySessionID(gomock.Any(), "sessionID").Return(&oauth2.Token{
+					AccessToken:  "access_token",
+					TokenType:    "Bearer",
+					RefreshToken: "refresh_token",
+					Expiry:       time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+				}, "userID", nil)
+			},
+			prepareAuthRepo: func(r *mock_repository.MockAuth) {
+				r.EXPECT().FindGuestByToken("invalidToken").Return(nil, entity.ErrGuestNotFound)
+			},
+			prepareAuthCli: func(c *mock_spotify.MockAuth) {},
+			next: func(c echo.Context) error {
+				if _, ok := service.GetGuestFromContext(c.Request().Context()); ok {
+					t.Errorf("CreatorTokenMiddleware.SetCreatorTokenToContext() guest is set to context")
+				}
+				return nil
+			},
+			wantErr:  false,
+			wantCode: http.StatusOK,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -159,6 +216,9 @@ func TestSessionTokenMiddleware_SetTokenToContext(t *testing.T) {
 			e := echo.New()
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.guestToken != "" {
+				req.AddCookie(&http.Cookie{Name: "guest", Value: tt.guestToken})
+			}
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 			c.SetPath("/sessions/:id/search")
@@ -27,15 +27,19 @@ var (
 // Client はWebSocketのクライアントを表します。
 type Client struct {
 	sessionID      string
+	participantID  string           // ログインしているユーザのユーザID、またはゲストのゲストID(どちらでもない場合は空文字列)
+	listener       *entity.Listener // ログインもゲストの登録もしていない場合はnil
 	ws             *websocket.Conn
 	pushCh         chan *entity.Event
 	notifyClosedCh chan<- *Client // HubのunregisterChをもらう
 }
 
 // NewClient は Clientのポインタを生成します。
-func NewClient(sessionID string, ws *websocket.Conn, notifyClosedCh chan<- *Client) *Client {
+func NewClient(sessionID string, participantID string, listener *entity.Listener, ws *websocket.Conn, notifyClosedCh chan<- *Client) *Client {
 	return &Client{
 		sessionID:      sessionID,
+		participantID:  participantID,
+		listener:       listener,
 		ws:             ws,
 		pushCh:         make(chan *entity.Event, 256),
 		notifyClosedCh: notifyClosedCh,
@@ -92,6 +96,7 @@ func (c *Client) PushLoop() {
 					})
 					return
 				}
+				return
 			}
 
 			if err := c.ws.WriteJSON(msg); err != nil {
@@ -1,6 +1,10 @@
 package ws
 
 import (
+	"sort"
+	"sync"
+
+	"github.com/camphor-/relaym-server/domain/entity"
 	"github.com/camphor-/relaym-server/domain/event"
 	"github.com/camphor-/relaym-server/log"
 )
@@ -11,9 +15,11 @@ type Hub struct {
 	// １つ目のキーがセッションID
 	// O(1) で Client を削除できるようにmapでClientを持つ
 	clientsPerSession map[string]map[*Client]struct{}
-	pushMsgCh         chan *event.PushMessage
-	registerCh        chan *Client
-	unregisterCh      chan *Client
+	// Run() 以外のgoroutineからも clientsPerSession を読み取れるようにするためのロック
+	mu           sync.RWMutex
+	pushMsgCh    chan *event.PushMessage
+	registerCh   chan *Client
+	unregisterCh chan *Client
 }
 
 // NewHub はHubのポインタを生成します。
@@ -57,6 +63,63 @@ func (h *Hub) Push(pushMsg *event.PushMessage) {
 	h.pushMsgCh <- pushMsg
 }
 
+// CountListeners は指定されたセッションに接続しているリスナーの数を返します。
+// 同じユーザが複数のクライアントから接続している場合も一人として数え、Listenerとして識別できないクライアントは数えません。
+// event.ListenerCounter インターフェースを満たしています。
+func (h *Hub) CountListeners(sessionID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.listeners(sessionID))
+}
+
+// Listeners は指定されたセッションに接続しているリスナーを表示名の順に並べて返します。
+// 同じユーザが複数のクライアントから接続している場合も一人として扱います。
+// event.ListenerCounter インターフェースを満たしています。
+func (h *Hub) Listeners(sessionID string) []*entity.Listener {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	listeners := h.listeners(sessionID)
+	sort.Slice(listeners, func(i, j int) bool {
+		if listeners[i].DisplayName != listeners[j].DisplayName {
+			return listeners[i].DisplayName < listeners[j].DisplayName
+		}
+		return listeners[i].ID < listeners[j].ID
+	})
+	return listeners
+}
+
+// listeners は指定されたセッションに接続しているリスナーをユーザごとに重複を除いて返します。
+// Listenerとして識別できないクライアントは含みません。
+// 呼び出し元でロックを取得している必要があります。
+func (h *Hub) listeners(sessionID string) []*entity.Listener {
+	seen := map[string]struct{}{}
+	listeners := make([]*entity.Listener, 0, len(h.clientsPerSession[sessionID]))
+	for cli := range h.clientsPerSession[sessionID] {
+		if cli.listener == nil {
+			continue
+		}
+		if _, ok := seen[cli.listener.ID]; ok {
+			continue
+		}
+		seen[cli.listener.ID] = struct{}{}
+		listeners = append(listeners, cli.listener)
+	}
+	return listeners
+}
+
+// countConnections は指定されたリスナーが指定されたセッションに接続しているクライアントの数を返します。
+// 呼び出し元でロックを取得している必要があります。
+func (h *Hub) countConnections(sessionID string, listenerID string) int {
+	count := 0
+	for cli := range h.clientsPerSession[sessionID] {
+		if cli.listener != nil && cli.listener.ID == listenerID {
+			count++
+		}
+	}
+	return count
+}
+
 // Run はWebSocketのメッセージを送信するメインループを実行する関数です。
 func (h *Hub) Run() {
 	for {
@@ -67,35 +130,115 @@ func (h *Hub) Run() {
 			h.unregister(cli)
 		case pushMsg := <-h.pushMsgCh:
 			h.push(pushMsg)
+			if pushMsg.Disconnect && pushMsg.ParticipantID != "" {
+				h.disconnectParticipant(pushMsg.SessionID, pushMsg.ParticipantID)
+			} else if pushMsg.Disconnect {
+				h.disconnect(pushMsg.SessionID)
+			}
 		}
 	}
 }
 
+// register はクライアントをHubに登録します。
+// ユーザにとって最初の接続の場合は、セッションに接続しているクライアントにJOINイベントを送信します。
+// Listenerとして識別できないクライアントの場合は送信しません。
 func (h *Hub) register(cli *Client) {
 	logger := log.New()
 	sessionID := cli.sessionID
 	logger.Debugj(map[string]interface{}{"message": "register websocket", "sessionID": sessionID})
 
+	h.mu.Lock()
 	if _, ok := h.clientsPerSession[sessionID]; ok {
 		h.clientsPerSession[sessionID][cli] = struct{}{}
-		return
+	} else {
+		h.clientsPerSession[sessionID] = map[*Client]struct{}{cli: {}}
+	}
+	joined := cli.listener != nil && h.countConnections(sessionID, cli.listener.ID) == 1
+	listenerCount := len(h.listeners(sessionID))
+	h.mu.Unlock()
+
+	if joined {
+		h.push(&event.PushMessage{SessionID: sessionID, Msg: entity.NewEventJoin(cli.listener, listenerCount)})
 	}
-	h.clientsPerSession[sessionID] = map[*Client]struct{}{cli: {}}
 }
 
+// unregister はクライアントをHubから登録解除します。
+// ユーザの最後の接続が切れた場合は、セッションに接続しているクライアントにLEAVEイベントを送信します。
+// Listenerとして識別できないクライアントの場合は送信しません。
 func (h *Hub) unregister(cli *Client) {
 	logger := log.New()
 	sessionID := cli.sessionID
 	logger.Debugj(map[string]interface{}{"message": "unregister websocket", "sessionID": sessionID})
 
-	if _, ok := h.clientsPerSession[sessionID][cli]; ok {
-		delete(h.clientsPerSession[sessionID], cli)
+	h.mu.Lock()
+	if _, ok := h.clientsPerSession[sessionID][cli]; !ok {
+		h.mu.Unlock()
 		return
 	}
+	delete(h.clientsPerSession[sessionID], cli)
+	left := cli.listener != nil && h.countConnections(sessionID, cli.listener.ID) == 0
+	listenerCount := len(h.listeners(sessionID))
+	h.mu.Unlock()
+
+	if left {
+		h.push(&event.PushMessage{SessionID: sessionID, Msg: entity.NewEventLeave(cli.listener, listenerCount)})
+	}
 }
 
 func (h *Hub) push(pushMsg *event.PushMessage) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 	for cli := range h.clientsPerSession[pushMsg.SessionID] {
+		if pushMsg.ParticipantID != "" && cli.participantID != pushMsg.ParticipantID {
+			continue
+		}
 		cli.pushCh <- pushMsg.Msg
 	}
 }
+
+// disconnect は指定されたセッションに接続している全てのクライアントのpushChを閉じて、Hubから登録解除します。
+// pushChが閉じられたクライアントは、残っているメッセージを送信した後に接続を切断します。
+func (h *Hub) disconnect(sessionID string) {
+	logger := log.New()
+	logger.Debugj(map[string]interface{}{"message": "disconnect websocket", "sessionID": sessionID})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for cli := range h.clientsPerSession[sessionID] {
+		close(cli.pushCh)
+	}
+	delete(h.clientsPerSession, sessionID)
+}
+
+// disconnectParticipant は指定された参加者がセッションに接続している全てのクライアントのpushChを閉じて、Hubから登録解除します。
+// 参加者が接続していたリスナーの接続が全て切れた場合は、セッションに接続しているクライアントにLEAVEイベントを送信します。
+func (h *Hub) disconnectParticipant(sessionID string, participantID string) {
+	logger := log.New()
+	logger.Debugj(map[string]interface{}{"message": "disconnect websocket", "sessionID": sessionID, "participantID": participantID})
+
+	h.mu.Lock()
+	disconnected := map[string]*entity.Listener{}
+	for cli := range h.clientsPerSession[sessionID] {
+		if cli.participantID != participantID {
+			continue
+		}
+		close(cli.pushCh)
+		delete(h.clientsPerSession[sessionID], cli)
+		if cli.listener != nil {
+			disconnected[cli.listener.ID] = cli.listener
+		}
+	}
+	left := make([]*entity.Listener, 0, len(disconnected))
+	for id, listener := range disconnected {
+		if h.countConnections(sessionID, id) == 0 {
+			left = append(left, listener)
+		}
+	}
+	listenerCount := len(h.listeners(sessionID))
+	h.mu.Unlock()
+
+	for _, listener := range left {
+		h.push(&event.PushMessage{SessionID: sessionID, Msg: entity.NewEventLeave(listener, listenerCount)})
+	}
+}
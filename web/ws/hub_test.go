@@ -45,15 +45,21 @@ func TestHub_UnregisterCh(t *testing.T) {
 func TestHub_Register(t *testing.T) {
 	newCli := &Client{
 		sessionID: "sessionID",
+		listener:  &entity.Listener{ID: "newUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 	sameCli := &Client{
 		sessionID: "sessionID",
+		listener:  &entity.Listener{ID: "sameUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 	existingCli := &Client{
 		sessionID: "existingSessionID",
+		listener:  &entity.Listener{ID: "existingUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 	tests := []struct {
 		name              string
@@ -105,15 +111,21 @@ func TestHub_Register(t *testing.T) {
 func TestHub_Unregister(t *testing.T) {
 	deletingCli := &Client{
 		sessionID: "sessionID",
+		listener:  &entity.Listener{ID: "deletingUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 	sameCli := &Client{
 		sessionID: "sessionID",
+		listener:  &entity.Listener{ID: "sameUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 	existingCli := &Client{
 		sessionID: "existingSessionID",
+		listener:  &entity.Listener{ID: "existingUserID"},
 		ws:        &websocket.Conn{},
+		pushCh:    make(chan *entity.Event, 10),
 	}
 
 	tests := []struct {
@@ -185,8 +197,8 @@ func TestHub_Push(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cli := NewClient("sessionID", conn, nil)
-	invalidCli := NewClient("sessionID", closedConn, nil)
+	cli := NewClient("sessionID", "userID", &entity.Listener{ID: "userID"}, conn, nil)
+	invalidCli := NewClient("sessionID", "otherUserID", &entity.Listener{ID: "otherUserID"}, closedConn, nil)
 	go cli.PushLoop()
 	go invalidCli.PushLoop()
 
@@ -258,6 +270,91 @@ func TestHub_Push(t *testing.T) {
 	}
 }
 
+func TestHub_Push_Disconnect(t *testing.T) {
+	cli := &Client{sessionID: "sessionID", pushCh: make(chan *entity.Event, 1)}
+	otherCli := &Client{sessionID: "otherSessionID", pushCh: make(chan *entity.Event, 1)}
+
+	h := &Hub{
+		clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {cli: struct{}{}}, "otherSessionID": {otherCli: struct{}{}}},
+		pushMsgCh:         make(chan *event.PushMessage),
+		registerCh:        make(chan *Client),
+		unregisterCh:      make(chan *Client),
+	}
+	go h.Run()
+	h.Push(&event.PushMessage{
+		SessionID:  "sessionID",
+		Msg:        entity.EventDeleted,
+		Disconnect: true,
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	want := map[string]map[*Client]struct{}{"otherSessionID": {otherCli: struct{}{}}}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !cmp.Equal(want, h.clientsPerSession) {
+		t.Errorf("Push() diff=%v", cmp.Diff(want, h.clientsPerSession))
+	}
+
+	if got := <-cli.pushCh; !cmp.Equal(entity.EventDeleted, got) {
+		t.Errorf("Push() recieved message diff=%v", cmp.Diff(entity.EventDeleted, got))
+	}
+	if _, ok := <-cli.pushCh; ok {
+		t.Errorf("Push() pushCh is not closed")
+	}
+	if len(otherCli.pushCh) != 0 {
+		t.Errorf("Push() pushed to other session's client")
+	}
+}
+
+func TestHub_Push_DisconnectParticipant(t *testing.T) {
+	listener := &entity.Listener{ID: "bannedUserID", DisplayName: "banned"}
+	bannedCli := &Client{sessionID: "sessionID", participantID: "bannedUserID", listener: listener, pushCh: make(chan *entity.Event, 1)}
+	bannedOtherTabCli := &Client{sessionID: "sessionID", participantID: "bannedUserID", listener: listener, pushCh: make(chan *entity.Event, 1)}
+	otherCli := &Client{sessionID: "sessionID", participantID: "otherUserID", listener: &entity.Listener{ID: "otherUserID"}, pushCh: make(chan *entity.Event, 2)}
+
+	h := &Hub{
+		clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {bannedCli: struct{}{}, bannedOtherTabCli: struct{}{}, otherCli: struct{}{}}},
+		pushMsgCh:         make(chan *event.PushMessage),
+		registerCh:        make(chan *Client),
+		unregisterCh:      make(chan *Client),
+	}
+	go h.Run()
+	h.Push(&event.PushMessage{
+		SessionID:     "sessionID",
+		Msg:           entity.EventBanned,
+		ParticipantID: "bannedUserID",
+		Disconnect:    true,
+	})
+
+	time.Sleep(100 * time.Millisecond)
+
+	want := map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}}}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !cmp.Equal(want, h.clientsPerSession) {
+		t.Errorf("Push() diff=%v", cmp.Diff(want, h.clientsPerSession))
+	}
+
+	for _, cli := range []*Client{bannedCli, bannedOtherTabCli} {
+		if got := <-cli.pushCh; !cmp.Equal(entity.EventBanned, got) {
+			t.Errorf("Push() recieved message diff=%v", cmp.Diff(entity.EventBanned, got))
+		}
+		if _, ok := <-cli.pushCh; ok {
+			t.Errorf("Push() pushCh is not closed")
+		}
+	}
+
+	// 追放されていない参加者にはBANNEDイベントは送られず、LEAVEイベントのみが送られる
+	wantLeave := entity.NewEventLeave(listener, 1)
+	if got := <-otherCli.pushCh; !cmp.Equal(wantLeave, got) {
+		t.Errorf("Push() recieved message diff=%v", cmp.Diff(wantLeave, got))
+	}
+	if len(otherCli.pushCh) != 0 {
+		t.Errorf("Push() pushed unexpected message to other participant's client")
+	}
+}
+
 type testWSServer struct {
 	ws *websocket.Conn
 }
@@ -275,3 +372,187 @@ func (s *testWSServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	s.ws = ws
 }
+
+func TestHub_Register_Join(t *testing.T) {
+	listener := &entity.Listener{ID: "userID", DisplayName: "user"}
+	existingCli := &Client{sessionID: "sessionID", listener: listener, pushCh: make(chan *entity.Event, 10)}
+	otherCli := &Client{sessionID: "sessionID", listener: &entity.Listener{ID: "otherUserID"}, pushCh: make(chan *entity.Event, 10)}
+
+	tests := []struct {
+		name              string
+		clientsPerSession map[string]map[*Client]struct{}
+		client            *Client
+		want              *entity.Event
+	}{
+		{
+			name:              "ユーザの最初の接続の場合はJOINイベントが送信される",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}}},
+			client:            &Client{sessionID: "sessionID", listener: listener, pushCh: make(chan *entity.Event, 10)},
+			want:              entity.NewEventJoin(listener, 2),
+		},
+		{
+			name:              "既に接続しているユーザが別のタブから接続した場合はJOINイベントが送信されない",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}, existingCli: struct{}{}}},
+			client:            &Client{sessionID: "sessionID", listener: listener, pushCh: make(chan *entity.Event, 10)},
+			want:              nil,
+		},
+		{
+			name:              "Listenerとして識別できないクライアントが接続した場合はJOINイベントが送信されない",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}}},
+			client:            &Client{sessionID: "sessionID", listener: nil, pushCh: make(chan *entity.Event, 10)},
+			want:              nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hub{
+				clientsPerSession: tt.clientsPerSession,
+				registerCh:        make(chan *Client),
+			}
+			go h.Run()
+			h.Register(tt.client)
+
+			time.Sleep(100 * time.Millisecond)
+
+			var got *entity.Event
+			select {
+			case got = <-otherCli.pushCh:
+			default:
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("Register() pushed event diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestHub_Unregister_Leave(t *testing.T) {
+	listener := &entity.Listener{ID: "userID", DisplayName: "user"}
+	deletingCli := &Client{sessionID: "sessionID", listener: listener, pushCh: make(chan *entity.Event, 10)}
+	sameUserCli := &Client{sessionID: "sessionID", listener: listener, pushCh: make(chan *entity.Event, 10)}
+	otherCli := &Client{sessionID: "sessionID", listener: &entity.Listener{ID: "otherUserID"}, pushCh: make(chan *entity.Event, 10)}
+	anonymousCli := &Client{sessionID: "sessionID", listener: nil, pushCh: make(chan *entity.Event, 10)}
+
+	tests := []struct {
+		name              string
+		clientsPerSession map[string]map[*Client]struct{}
+		deletingCli       *Client
+		want              *entity.Event
+	}{
+		{
+			name:              "ユーザの最後の接続が切れた場合はLEAVEイベントが送信される",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}, deletingCli: struct{}{}}},
+			deletingCli:       deletingCli,
+			want:              entity.NewEventLeave(listener, 1),
+		},
+		{
+			name:              "同じユーザが別のタブから接続している場合はLEAVEイベントが送信されない",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}, deletingCli: struct{}{}, sameUserCli: struct{}{}}},
+			deletingCli:       deletingCli,
+			want:              nil,
+		},
+		{
+			name:              "Listenerとして識別できないクライアントの接続が切れた場合はLEAVEイベントが送信されない",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {otherCli: struct{}{}, anonymousCli: struct{}{}}},
+			deletingCli:       anonymousCli,
+			want:              nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hub{
+				clientsPerSession: tt.clientsPerSession,
+				unregisterCh:      make(chan *Client),
+			}
+			go h.Run()
+			h.Unregister(tt.deletingCli)
+
+			time.Sleep(100 * time.Millisecond)
+
+			var got *entity.Event
+			select {
+			case got = <-otherCli.pushCh:
+			default:
+			}
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("Unregister() pushed event diff=%v", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestHub_CountListeners(t *testing.T) {
+	cli1 := &Client{sessionID: "sessionID", listener: &entity.Listener{ID: "userID1"}}
+	cli2 := &Client{sessionID: "sessionID", listener: &entity.Listener{ID: "userID2"}}
+	sameUserCli := &Client{sessionID: "sessionID", listener: &entity.Listener{ID: "userID1"}}
+	otherCli := &Client{sessionID: "otherSessionID", listener: &entity.Listener{ID: "userID3"}}
+	anonymousCli1 := &Client{sessionID: "sessionID", listener: nil}
+	anonymousCli2 := &Client{sessionID: "sessionID", listener: nil}
+
+	tests := []struct {
+		name              string
+		clientsPerSession map[string]map[*Client]struct{}
+		sessionID         string
+		want              int
+	}{
+		{
+			name:              "指定したセッションに接続しているClientの数を返す",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {cli1: struct{}{}, cli2: struct{}{}}, "otherSessionID": {otherCli: struct{}{}}},
+			sessionID:         "sessionID",
+			want:              2,
+		},
+		{
+			name:              "同じユーザが複数のClientで接続していても一人として数える",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {cli1: struct{}{}, cli2: struct{}{}, sameUserCli: struct{}{}}},
+			sessionID:         "sessionID",
+			want:              2,
+		},
+		{
+			name:              "Listenerとして識別できないClientは数えない",
+			clientsPerSession: map[string]map[*Client]struct{}{"sessionID": {cli1: struct{}{}, anonymousCli1: struct{}{}, anonymousCli2: struct{}{}}},
+			sessionID:         "sessionID",
+			want:              1,
+		},
+		{
+			name:              "Clientが接続していないセッションは0を返す",
+			clientsPerSession: map[string]map[*Client]struct{}{"otherSessionID": {otherCli: struct{}{}}},
+			sessionID:         "sessionID",
+			want:              0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Hub{
+				clientsPerSession: tt.clientsPerSession,
+			}
+			if got := h.CountListeners(tt.sessionID); got != tt.want {
+				t.Errorf("CountListeners() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHub_Listeners(t *testing.T) {
+	alice := &entity.Listener{ID: "aliceID", DisplayName: "alice"}
+	bob := &entity.Listener{ID: "bobID", DisplayName: "bob"}
+	guest := entity.NewGuestListener("guestID", "")
+
+	h := &Hub{
+		clientsPerSession: map[string]map[*Client]struct{}{
+			"sessionID": {
+				&Client{sessionID: "sessionID", listener: bob}:   struct{}{},
+				&Client{sessionID: "sessionID", listener: guest}: struct{}{},
+				&Client{sessionID: "sessionID", listener: alice}: struct{}{},
+				&Client{sessionID: "sessionID", listener: alice}: struct{}{},
+			},
+		},
+	}
+
+	want := []*entity.Listener{guest, alice, bob}
+	if got := h.Listeners("sessionID"); !cmp.Equal(want, got) {
+		t.Errorf("Listeners() diff=%v", cmp.Diff(want, got))
+	}
+	if got := h.Listeners("otherSessionID"); len(got) != 0 {
+		t.Errorf("Listeners() = %v, want empty", got)
+	}
+}